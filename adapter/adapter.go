@@ -0,0 +1,139 @@
+// Package adapter provides thin adapters of mcache caches to store interfaces of popular libraries.
+// The interfaces are satisfied structurally, so none of the libraries is imported.
+//
+// Store interface of eko/gocache takes options of a type declared in that package, so it can't be
+// satisfied without importing it: it's implemented by the adapter/gocache module.
+package adapter
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/parMaster/mcache"
+)
+
+// ErrNotSet is returned when the cache doesn't set the value, and doesn't tell why
+var ErrNotSet = errors.New("value is not set")
+
+// trySetter is a cache returning the reason a value isn't set, like mcache.Cache
+type trySetter[T any] interface {
+	TrySet(key string, value T, ttl time.Duration) error
+}
+
+// set sets the value, returning the reason it isn't set if the cache tells it
+func set[T any](cache mcache.Cacher[T], key string, value T, ttl time.Duration) error {
+	if ts, ok := cache.(trySetter[T]); ok {
+		return ts.TrySet(key, value, ttl)
+	}
+	if !cache.Set(key, value, ttl) {
+		return ErrNotSet
+	}
+	return nil
+}
+
+// missing checks if the error of Get means there is no value of the key
+func missing(err error) bool {
+	return errors.Is(err, mcache.ErrKeyNotFound) || errors.Is(err, mcache.ErrExpired) ||
+		errors.Is(err, mcache.ErrNegativeCached)
+}
+
+// Storage adapts a cache of byte slices, like mcache.Cache[[]byte], bytecache or shm,
+// to the Storage interface of Fiber middlewares (github.com/gofiber/storage),
+// for sessions, rate limiting and response caching of Fiber apps.
+type Storage struct {
+	cache mcache.Cacher[[]byte]
+}
+
+// NewStorage is a constructor for Storage of the cache
+func NewStorage(cache mcache.Cacher[[]byte]) *Storage {
+	return &Storage{cache: cache}
+}
+
+// Get returns the value of the key, nil without an error if the key doesn't exist
+func (s *Storage) Get(key string) ([]byte, error) {
+	if key == "" {
+		return nil, nil
+	}
+	v, err := s.cache.Get(key)
+	if missing(err) {
+		return nil, nil
+	}
+	return v, err
+}
+
+// Set sets the value of the key expiring after exp, zero exp means no expiration.
+// Empty key or value is ignored without an error.
+func (s *Storage) Set(key string, val []byte, exp time.Duration) error {
+	if key == "" || len(val) == 0 {
+		return nil
+	}
+	return set(s.cache, key, val, exp)
+}
+
+// Delete deletes the key, missing key is not an error
+func (s *Storage) Delete(key string) error {
+	if key == "" {
+		return nil
+	}
+	if err := s.cache.Del(key); err != nil && !missing(err) {
+		return err
+	}
+	return nil
+}
+
+// Reset deletes all keys
+func (s *Storage) Reset() error {
+	return s.cache.Clear()
+}
+
+// Close closes the cache, if it can be closed
+func (s *Storage) Close() error {
+	if c, ok := s.cache.(interface{ Close() error }); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Store adapts a cache to mcache.Store, so a cache, like a shared striped or remote one,
+// can be the backing store of another cache WithReadThrough or WithWriteBehind, as a second level.
+type Store[T any] struct {
+	cache mcache.Cacher[T]
+}
+
+// NewStore is a constructor for Store of the cache
+func NewStore[T any](cache mcache.Cacher[T]) *Store[T] {
+	return &Store[T]{cache: cache}
+}
+
+// Load implements mcache.Store. Remaining TTL is returned for caches having EntryInfo,
+// like mcache.Cache, values of other caches are loaded without expiration.
+func (s *Store[T]) Load(_ context.Context, key string) (value T, ttl time.Duration, err error) {
+	if value, err = s.cache.Get(key); err != nil {
+		if missing(err) {
+			err = mcache.ErrKeyNotFound
+		}
+		return value, 0, err
+	}
+	if ei, ok := s.cache.(interface {
+		EntryInfo(key string) (mcache.EntryInfo, error)
+	}); ok {
+		if info, err := ei.EntryInfo(key); err == nil && !info.Expiration.IsZero() {
+			ttl = max(time.Until(info.Expiration), time.Nanosecond)
+		}
+	}
+	return value, ttl, nil
+}
+
+// Save implements mcache.Store
+func (s *Store[T]) Save(_ context.Context, key string, value T, ttl time.Duration) error {
+	return set(s.cache, key, value, ttl)
+}
+
+// Delete implements mcache.Store, missing key is not an error
+func (s *Store[T]) Delete(_ context.Context, key string) error {
+	if err := s.cache.Del(key); err != nil && !missing(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package adapter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache"
+	"github.com/parMaster/mcache/bytecache"
+)
+
+// fiberStorage is the Storage interface of github.com/gofiber/storage
+type fiberStorage interface {
+	Get(key string) ([]byte, error)
+	Set(key string, val []byte, exp time.Duration) error
+	Delete(key string) error
+	Reset() error
+	Close() error
+}
+
+func TestStorage(t *testing.T) {
+	for name, cache := range map[string]mcache.Cacher[[]byte]{
+		"cache":     mcache.NewCache[[]byte](),
+		"bytecache": bytecache.New(1 << 20),
+	} {
+		t.Run(name, func(t *testing.T) {
+			var s fiberStorage = NewStorage(cache)
+			require.NoError(t, s.Set("key", []byte("value"), 0))
+			require.NoError(t, s.Set("key", []byte("other"), 0))
+			require.NoError(t, s.Set("expired", []byte("value"), time.Millisecond))
+			require.NoError(t, s.Set("", []byte("value"), 0))
+			require.NoError(t, s.Set("empty", nil, 0))
+			time.Sleep(10 * time.Millisecond)
+
+			v, err := s.Get("key")
+			require.NoError(t, err)
+			assert.Equal(t, []byte("other"), v)
+			for _, key := range []string{"expired", "empty", "missing", ""} {
+				v, err = s.Get(key)
+				require.NoError(t, err, key)
+				assert.Nil(t, v, key)
+			}
+
+			require.NoError(t, s.Delete("key"))
+			require.NoError(t, s.Delete("key"))
+			v, err = s.Get("key")
+			require.NoError(t, err)
+			assert.Nil(t, v)
+
+			require.NoError(t, s.Set("key", []byte("value"), 0))
+			require.NoError(t, s.Reset())
+			v, err = s.Get("key")
+			require.NoError(t, err)
+			assert.Nil(t, v)
+			require.NoError(t, s.Close())
+		})
+	}
+
+	full := mcache.NewCache(mcache.WithMaxEntries[[]byte](1), mcache.WithFullPolicy[[]byte](mcache.Reject))
+	s := NewStorage(full)
+	require.NoError(t, s.Set("a", []byte("value"), 0))
+	assert.ErrorIs(t, s.Set("b", []byte("value"), 0), mcache.ErrCacheFull)
+	require.NoError(t, s.Close())
+	_, err := s.Get("a")
+	assert.ErrorIs(t, err, mcache.ErrClosed)
+}
+
+func TestStore(t *testing.T) {
+	l2 := mcache.NewStripedCache[string](4)
+	l2.Set("shared", "value", 0)
+	var store mcache.Store[string] = NewStore[string](l2)
+	l1 := mcache.NewCache(mcache.WithReadThrough[string](store))
+
+	v, err := l1.Get("shared")
+	require.NoError(t, err)
+	assert.Equal(t, "value", v)
+	_, err = l1.Get("missing")
+	assert.ErrorIs(t, err, mcache.ErrKeyNotFound)
+
+	// remaining TTL is kept for caches with EntryInfo
+	cache := mcache.NewCache[string]()
+	cache.Set("key", "value", time.Hour)
+	_, ttl, err := NewStore[string](cache).Load(context.Background(), "key")
+	require.NoError(t, err)
+	assert.InDelta(t, time.Hour, ttl, float64(time.Second))
+
+	ctx := context.Background()
+	require.NoError(t, store.Save(ctx, "saved", "value", 0))
+	v, err = l2.Get("saved")
+	require.NoError(t, err)
+	assert.Equal(t, "value", v)
+	require.NoError(t, store.Delete(ctx, "saved"))
+	require.NoError(t, store.Delete(ctx, "saved"))
+	_, _, err = store.Load(ctx, "saved")
+	assert.ErrorIs(t, err, mcache.ErrKeyNotFound)
+}
@@ -0,0 +1,169 @@
+// Package gocache adapts mcache caches to the StoreInterface of eko/gocache, so a cache can be
+// a store of gocache chains, loadable and metric caches. It's a module of its own,
+// so the main module doesn't depend on gocache.
+package gocache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/eko/gocache/lib/v4/store"
+
+	"github.com/parMaster/mcache"
+)
+
+// Type is the type of the store returned by GetType
+const Type = "mcache"
+
+// tagPrefix is the prefix of keys the tagged values depend on, see Set
+const tagPrefix = "gocache:tag:"
+
+// Errors of the store
+var (
+	ErrKeyType         = errors.New("key is not a string")
+	ErrValueType       = errors.New("value type doesn't match the cache")
+	ErrNotSet          = errors.New("value is not set")
+	ErrTagsUnsupported = errors.New("tags are not supported by the cache")
+)
+
+// trySetter is a cache returning the reason a value isn't set, like mcache.Cache
+type trySetter[T any] interface {
+	TrySet(key string, value T, ttl time.Duration) error
+}
+
+// depSetter is a cache of values depending on other keys, like mcache.Cache
+type depSetter[T any] interface {
+	SetWithDeps(key string, value T, ttl time.Duration, deps ...string) bool
+}
+
+// Store implements store.StoreInterface of github.com/eko/gocache/lib/v4 over a cache.
+// Keys must be strings, gocache turns other keys into strings before they get to the store,
+// values must be of the cache value type.
+type Store[T any] struct {
+	cache   mcache.Cacher[T]
+	options *store.Options
+}
+
+// NewStore is a constructor for Store of the cache, options are defaults of Set, like store.WithExpiration
+func NewStore[T any](cache mcache.Cacher[T], options ...store.Option) *Store[T] {
+	return &Store[T]{cache: cache, options: store.ApplyOptions(options...)}
+}
+
+// Get returns the value of the key, store.NotFound error if it doesn't exist
+func (s *Store[T]) Get(ctx context.Context, key any) (any, error) {
+	v, _, err := s.GetWithTTL(ctx, key)
+	return v, err
+}
+
+// GetWithTTL returns the value of the key with its remaining TTL, zero if it doesn't expire.
+// TTL is known for caches having EntryInfo, like mcache.Cache, it's zero for other caches.
+func (s *Store[T]) GetWithTTL(_ context.Context, key any) (any, time.Duration, error) {
+	k, err := keyString(key)
+	if err != nil {
+		return nil, 0, err
+	}
+	v, err := s.cache.Get(k)
+	if err != nil {
+		if missing(err) {
+			err = store.NotFoundWithCause(err)
+		}
+		return nil, 0, err
+	}
+	var ttl time.Duration
+	if ei, ok := s.cache.(interface {
+		EntryInfo(key string) (mcache.EntryInfo, error)
+	}); ok {
+		if info, err := ei.EntryInfo(k); err == nil && !info.Expiration.IsZero() {
+			ttl = max(time.Until(info.Expiration), time.Nanosecond)
+		}
+	}
+	return v, ttl, nil
+}
+
+// Set sets the value of the key, expiring after store.WithExpiration, zero means no expiration.
+// Values set store.WithTags are deleted by Invalidate of any of the tags, which needs a cache
+// of values depending on other keys, like mcache.Cache: tags are keys the value depends on.
+// Cost and other options of particular stores are ignored.
+func (s *Store[T]) Set(_ context.Context, key any, value any, options ...store.Option) error {
+	k, err := keyString(key)
+	if err != nil {
+		return err
+	}
+	v, ok := value.(T)
+	if !ok {
+		return fmt.Errorf("%w: %T", ErrValueType, value)
+	}
+	opts := store.ApplyOptionsWithDefault(s.options, options...)
+	if len(opts.Tags) == 0 {
+		if ts, ok := s.cache.(trySetter[T]); ok {
+			return ts.TrySet(k, v, opts.Expiration)
+		}
+		if !s.cache.Set(k, v, opts.Expiration) {
+			return ErrNotSet
+		}
+		return nil
+	}
+
+	ds, ok := s.cache.(depSetter[T])
+	if !ok {
+		return ErrTagsUnsupported
+	}
+	deps := make([]string, len(opts.Tags))
+	for i, tag := range opts.Tags {
+		deps[i] = tagPrefix + tag
+	}
+	if !ds.SetWithDeps(k, v, opts.Expiration, deps...) {
+		return ErrNotSet
+	}
+	return nil
+}
+
+// Delete deletes the key, missing key is not an error
+func (s *Store[T]) Delete(_ context.Context, key any) error {
+	k, err := keyString(key)
+	if err != nil {
+		return err
+	}
+	if err := s.cache.Del(k); err != nil && !missing(err) {
+		return err
+	}
+	return nil
+}
+
+// Invalidate deletes values set with any of the tags of store.WithInvalidateTags
+func (s *Store[T]) Invalidate(_ context.Context, options ...store.InvalidateOption) error {
+	for _, tag := range store.ApplyInvalidateOptions(options...).Tags {
+		// deleting the tag key deletes values depending on it, even if the key doesn't exist
+		if err := s.cache.Del(tagPrefix + tag); err != nil && !missing(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Clear deletes all keys
+func (s *Store[T]) Clear(_ context.Context) error {
+	return s.cache.Clear()
+}
+
+// GetType returns the type of the store
+func (s *Store[T]) GetType() string {
+	return Type
+}
+
+// keyString returns the key as a string
+func keyString(key any) (string, error) {
+	k, ok := key.(string)
+	if !ok {
+		return "", fmt.Errorf("%w: %T", ErrKeyType, key)
+	}
+	return k, nil
+}
+
+// missing checks if the error of Get means there is no value of the key
+func missing(err error) bool {
+	return errors.Is(err, mcache.ErrKeyNotFound) || errors.Is(err, mcache.ErrExpired) ||
+		errors.Is(err, mcache.ErrNegativeCached)
+}
@@ -0,0 +1,104 @@
+package gocache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/eko/gocache/lib/v4/cache"
+	"github.com/eko/gocache/lib/v4/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache"
+	"github.com/parMaster/mcache/bytecache"
+)
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	var s store.StoreInterface = NewStore[string](mcache.NewCache[string](), store.WithExpiration(time.Hour))
+	assert.Equal(t, "mcache", s.GetType())
+
+	require.NoError(t, s.Set(ctx, "key", "value"))
+	v, ttl, err := s.GetWithTTL(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, "value", v)
+	assert.InDelta(t, time.Hour, ttl, float64(time.Second), "default expiration")
+
+	require.NoError(t, s.Set(ctx, "forever", "value", store.WithExpiration(0)))
+	_, ttl, err = s.GetWithTTL(ctx, "forever")
+	require.NoError(t, err)
+	assert.Zero(t, ttl)
+
+	require.NoError(t, s.Set(ctx, "expiring", "value", store.WithExpiration(time.Millisecond)))
+	time.Sleep(5 * time.Millisecond)
+	_, err = s.Get(ctx, "expiring")
+	assert.True(t, store.NotFound{}.Is(err))
+
+	_, err = s.Get(ctx, "missing")
+	assert.ErrorIs(t, err, &store.NotFound{})
+	assert.ErrorIs(t, err, mcache.ErrKeyNotFound)
+
+	assert.ErrorIs(t, s.Set(ctx, 42, "value"), ErrKeyType)
+	assert.ErrorIs(t, s.Set(ctx, "key", 42), ErrValueType)
+	_, err = s.Get(ctx, 42)
+	assert.ErrorIs(t, err, ErrKeyType)
+
+	require.NoError(t, s.Delete(ctx, "key"))
+	require.NoError(t, s.Delete(ctx, "key"), "missing key is not an error")
+	_, err = s.Get(ctx, "key")
+	assert.ErrorIs(t, err, mcache.ErrKeyNotFound)
+
+	require.NoError(t, s.Clear(ctx))
+	_, err = s.Get(ctx, "forever")
+	assert.ErrorIs(t, err, mcache.ErrKeyNotFound)
+}
+
+func TestStoreTags(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore[string](mcache.NewCache[string]())
+	require.NoError(t, s.Set(ctx, "user:1", "alice", store.WithTags([]string{"users", "team:1"})))
+	require.NoError(t, s.Set(ctx, "user:2", "bob", store.WithTags([]string{"users"})))
+	require.NoError(t, s.Set(ctx, "team:1", "team"))
+
+	require.NoError(t, s.Invalidate(ctx, store.WithInvalidateTags([]string{"team:1"})))
+	_, err := s.Get(ctx, "user:1")
+	assert.ErrorIs(t, err, mcache.ErrKeyNotFound)
+	v, err := s.Get(ctx, "team:1")
+	require.NoError(t, err, "tags are apart from keys")
+	assert.Equal(t, "team", v)
+	_, err = s.Get(ctx, "user:2")
+	assert.NoError(t, err)
+
+	require.NoError(t, s.Invalidate(ctx, store.WithInvalidateTags([]string{"users", "unknown"})))
+	_, err = s.Get(ctx, "user:2")
+	assert.ErrorIs(t, err, mcache.ErrKeyNotFound)
+
+	// caches without dependencies don't support tags
+	bytes := NewStore[[]byte](bytecache.New(1 << 20))
+	assert.ErrorIs(t, bytes.Set(ctx, "key", []byte("value"), store.WithTags([]string{"tag"})), ErrTagsUnsupported)
+	require.NoError(t, bytes.Set(ctx, "key", []byte("value")))
+	v2, ttl, err := bytes.GetWithTTL(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), v2)
+	assert.Zero(t, ttl)
+}
+
+func TestStoreWithGocache(t *testing.T) {
+	ctx := context.Background()
+	full := NewStore[int](mcache.NewCache(mcache.WithMaxEntries[int](1), mcache.WithFullPolicy[int](mcache.Reject)))
+	c := cache.New[int](full)
+
+	require.NoError(t, c.Set(ctx, "key", 42))
+	v, err := c.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, 42, v)
+	assert.ErrorIs(t, c.Set(ctx, "other", 1), mcache.ErrCacheFull)
+
+	// keys of other types are turned into strings by gocache
+	require.NoError(t, c.Delete(ctx, "key"))
+	require.NoError(t, c.Set(ctx, struct{ ID int }{1}, 1))
+	v, err = c.Get(ctx, struct{ ID int }{1})
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+}
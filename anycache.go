@@ -0,0 +1,32 @@
+package mcache
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrTypeMismatch is returned by GetAs when the cached value has another type
+var ErrTypeMismatch = errors.New("value type mismatch")
+
+// AnyCache is a cache of values of mixed types in one keyspace, read with GetAs
+type AnyCache = Cache[any]
+
+// NewAnyCache is a constructor for AnyCache, options are the same as for NewCache.
+// Values persisted with GobCodec must be registered with gob.Register.
+func NewAnyCache(options ...func(*Cache[any])) *AnyCache {
+	return NewCache(options...)
+}
+
+// GetAs is Get of a value of type T, ErrTypeMismatch if the value has another type
+func GetAs[T any](c *AnyCache, key string) (T, error) {
+	var none T
+	v, err := c.Get(key)
+	if err != nil {
+		return none, err
+	}
+	typed, ok := v.(T)
+	if !ok {
+		return none, fmt.Errorf("%w: %q is %T, not %T", ErrTypeMismatch, key, v, none)
+	}
+	return typed, nil
+}
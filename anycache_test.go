@@ -0,0 +1,32 @@
+package mcache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAs(t *testing.T) {
+	type user struct{ Name string }
+	cache := NewAnyCache()
+	cache.Set("count", 42, 0)
+	cache.Set("user", user{Name: "alice"}, 0)
+	cache.Set("ptr", &user{Name: "bob"}, 0)
+
+	n, err := GetAs[int](cache, "count")
+	require.NoError(t, err)
+	assert.Equal(t, 42, n)
+	u, err := GetAs[user](cache, "user")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", u.Name)
+	p, err := GetAs[*user](cache, "ptr")
+	require.NoError(t, err)
+	assert.Equal(t, "bob", p.Name)
+
+	_, err = GetAs[string](cache, "count")
+	assert.ErrorIs(t, err, ErrTypeMismatch)
+	assert.EqualError(t, err, `value type mismatch: "count" is int, not string`)
+	_, err = GetAs[int](cache, "missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
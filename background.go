@@ -0,0 +1,126 @@
+package mcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// task is a function run periodically in a background goroutine until the cache is closed,
+// or until ctx is cancelled
+type task struct {
+	ctx      context.Context
+	interval time.Duration
+	fn       func()
+}
+
+// background manages goroutines started by options like WithCleanup and WithPersistence.
+// Options only register tasks, goroutines are started by NewCache after all options are applied.
+type background struct {
+	tasks []task
+	phase float64 // the first run of tasks is delayed by this part of their interval, see withPhase
+	done  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// withPhase is an option delaying the first run of background tasks by the phase part of their interval,
+// so tasks of stripes of StripedCache don't run all at the same moment
+func withPhase[T any](phase float64) func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.bg.phase = phase
+	}
+}
+
+// addTask registers fn to run every interval once the cache is constructed
+func (c *Cache[T]) addTask(interval time.Duration, fn func()) {
+	c.addTaskContext(context.Background(), interval, fn)
+}
+
+// addTaskContext registers fn to run every interval until ctx is cancelled.
+// Interval <= 0 disables the task, so options like WithCleanup(0) turn the goroutine off.
+func (c *Cache[T]) addTaskContext(ctx context.Context, interval time.Duration, fn func()) {
+	if interval <= 0 {
+		return
+	}
+	c.bg.tasks = append(c.bg.tasks, task{ctx: ctx, interval: interval, fn: fn})
+}
+
+// startTasks starts a goroutine for every registered task
+func (c *Cache[T]) startTasks() {
+	c.bg.done = make(chan struct{})
+	for _, t := range c.bg.tasks {
+		c.bg.wg.Add(1)
+		go func(t task) {
+			defer c.bg.wg.Done()
+			if delay := time.Duration(c.bg.phase * float64(t.interval)); delay > 0 {
+				timer := time.NewTimer(delay)
+				defer timer.Stop()
+				select {
+				case <-c.bg.done:
+					return
+				case <-t.ctx.Done():
+					return
+				case <-timer.C:
+				}
+			}
+			ticker := time.NewTicker(t.interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-c.bg.done:
+					return
+				case <-t.ctx.Done():
+					return
+				case <-ticker.C:
+					t.fn()
+				}
+			}
+		}(t)
+	}
+}
+
+// Close stops background goroutines started by options, stops applying invalidations WithInvalidator,
+// stops replications started by ReplicateTo, flushes pending writes WithWriteBehind,
+// saves the final snapshot WithPersistence and closes the log WithWAL.
+// Closed cache is unusable: Set returns false, Get, Has, Del, Clear,
+// LoadFrom and RecoverFromWAL return ErrClosed, Cleanup does nothing.
+// Stats, Dump, EntryInfo and Save keep working on the entries left in the cache.
+// Second Close returns ErrClosed.
+func (c *Cache[T]) Close() error {
+	c.Lock()
+	if c.closed {
+		c.Unlock()
+		return ErrClosed
+	}
+	c.closed = true
+	c.stopReplications()
+	c.Unlock()
+
+	close(c.bg.done)
+	c.bg.wg.Wait()
+	if c.invalidation != nil && c.invalidation.unsubscribe != nil {
+		c.invalidation.unsubscribe()
+	}
+
+	var errs []error
+	if err := c.Flush(); err != nil {
+		errs = append(errs, err)
+	}
+	if c.persistPath != "" {
+		if err := c.Save(c.persistPath); err != nil {
+			errs = append(errs, fmt.Errorf("failed to save final snapshot: %w", err))
+		}
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	if c.wal != nil {
+		if err := c.wal.file.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close WAL: %w", err))
+		}
+		c.wal = nil
+	}
+	return errors.Join(errs...)
+}
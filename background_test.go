@@ -0,0 +1,128 @@
+package mcache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClose(t *testing.T) {
+	dir := t.TempDir()
+	snapshot := filepath.Join(dir, "cache.gob")
+	walPath := filepath.Join(dir, "cache.wal")
+
+	cache := NewCache(
+		WithCleanup[string](time.Millisecond),
+		WithPersistence[string](snapshot, time.Hour),
+		WithWAL[string](walPath, time.Hour),
+	)
+	running := runtime.NumGoroutine()
+
+	cache.Set("key", "value", 0)
+	require.NoError(t, cache.Close())
+	// other tests may leave goroutines finishing in the background, so only the decrease is checked
+	assert.LessOrEqual(t, runtime.NumGoroutine(), running-3)
+
+	// final snapshot is saved on Close
+	_, err := os.Stat(snapshot)
+	assert.NoError(t, err)
+	restored, err := NewCacheFromFile[string](snapshot)
+	require.NoError(t, err)
+	v, err := restored.Get("key")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", v)
+
+	assert.False(t, cache.Set("key2", "value", 0))
+	_, err = cache.Get("key")
+	assert.ErrorIs(t, err, ErrClosed)
+	_, err = cache.Has("key")
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.ErrorIs(t, cache.Del("key"), ErrClosed)
+	assert.ErrorIs(t, cache.Clear(), ErrClosed)
+	assert.ErrorIs(t, cache.LoadFrom(mustOpen(t, snapshot)), ErrClosed)
+	assert.ErrorIs(t, cache.RecoverFromWAL(walPath), ErrClosed)
+	cache.Cleanup()
+	assert.ErrorIs(t, cache.Close(), ErrClosed)
+
+	// diagnostics keep working
+	assert.Equal(t, 1, cache.Stats().Entries)
+	_, err = cache.EntryInfo("key")
+	assert.NoError(t, err)
+
+	// plain cache closes as well
+	assert.NoError(t, NewCache[string]().Close())
+}
+
+func TestCloseErrors(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewCache(WithPersistence[string](filepath.Join(dir, "noSuchDir", "cache.gob"), time.Hour))
+	assert.ErrorContains(t, cache.Close(), "failed to save final snapshot")
+}
+
+func mustOpen(t *testing.T, path string) *os.File {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func TestWithCleanupContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cache := NewCache(WithCleanupContext[string](ctx, 10*time.Millisecond))
+
+	cache.Set("key", "value", time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 0, cache.Stats().Entries, "cleanup should run until ctx is cancelled")
+
+	cancel()
+	done := make(chan struct{})
+	go func() {
+		cache.bg.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("cleanup goroutine didn't exit after ctx cancellation")
+	}
+
+	cleanups := cache.Stats().Cleanups
+	cache.Set("key", "value", time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, cleanups, cache.Stats().Cleanups)
+	assert.Equal(t, 1, cache.Stats().Entries)
+
+	// Close works after the context is cancelled
+	assert.NoError(t, cache.Close())
+}
+
+func TestNonPositiveIntervals(t *testing.T) {
+	dir := t.TempDir()
+	for name, opt := range map[string]func(*Cache[string]){
+		"WithCleanup":         WithCleanup[string](0),
+		"WithCleanupContext":  WithCleanupContext[string](context.Background(), -time.Second),
+		"WithPersistence":     WithPersistence[string](filepath.Join(dir, "cache.gob"), 0),
+		"WithWAL":             WithWAL[string](filepath.Join(dir, "cache.wal"), 0),
+		"WithCoarseTime":      WithCoarseTime[string](0),
+		"WithCleanupNegative": WithCleanup[string](-time.Second),
+	} {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache(opt)
+			assert.Empty(t, cache.bg.tasks, "no task is registered")
+			cache.Set("key", "value", 20*time.Millisecond)
+			_, err := cache.Get("key")
+			require.NoError(t, err)
+			time.Sleep(30 * time.Millisecond)
+			_, err = cache.Get("key")
+			assert.ErrorIs(t, err, ErrExpired, "time is not frozen")
+			require.NoError(t, cache.Close())
+		})
+	}
+	assert.FileExists(t, filepath.Join(dir, "cache.gob"), "saved by Close without periodic saves")
+}
@@ -2,8 +2,11 @@ package mcache
 
 import (
 	"fmt"
+	"strconv"
 	"testing"
 	"time"
+
+	"github.com/stretchr/testify/assert"
 )
 
 // BenchmarkWrite
@@ -73,3 +76,155 @@ func BenchmarkConcurrentRWD(b *testing.B) {
 	c1.Clear()
 	c2.Clear()
 }
+
+// TestZeroAllocs checks that steady-state operations don't allocate
+func TestZeroAllocs(t *testing.T) {
+	cache := NewCache(WithSize[int](1))
+	cache.Set("key", 1, time.Hour)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = cache.Get("key")
+	})
+	assert.Zero(t, allocs, "Get")
+
+	allocs = testing.AllocsPerRun(100, func() {
+		_, _ = cache.Has("key")
+	})
+	assert.Zero(t, allocs, "Has")
+
+	allocs = testing.AllocsPerRun(100, func() {
+		cache.Set("key", 2, time.Hour) // live key is replaced
+	})
+	assert.Zero(t, allocs, "Set of a live key")
+
+	allocs = testing.AllocsPerRun(100, func() {
+		_ = cache.Del("key")
+		cache.Set("key", 3, time.Hour)
+	})
+	assert.Zero(t, allocs, "Del and Set")
+
+	_, _ = cache.Get("noSuchKey")
+	allocs = testing.AllocsPerRun(100, func() {
+		_, _ = cache.Get("noSuchKey")
+	})
+	assert.Zero(t, allocs, "Get of a missing key")
+}
+
+// BenchmarkGetHit reads the same live keys over and over, keys are built beforehand
+func BenchmarkGetHit(b *testing.B) {
+	cache := NewCache[int]()
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+		cache.Set(keys[i], i, time.Hour)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = cache.Get(keys[i%len(keys)])
+	}
+}
+
+// BenchmarkGetHitNoExpiration is BenchmarkGetHit WithNoExpiration
+func BenchmarkGetHitNoExpiration(b *testing.B) {
+	cache := NewCache(WithNoExpiration[int]())
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+		cache.Set(keys[i], i, time.Hour)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = cache.Get(keys[i%len(keys)])
+	}
+}
+
+// BenchmarkSetDel replaces the same keys over and over, keys are built beforehand
+func BenchmarkSetDel(b *testing.B) {
+	cache := NewCache[int]()
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		key := keys[i%len(keys)]
+		cache.Set(key, i, time.Hour)
+		_ = cache.Del(key)
+	}
+}
+
+// BenchmarkBackends compares backends on parallel reads of disjoint keys with rare writes
+func BenchmarkBackends(b *testing.B) {
+	for name, opt := range map[string]func(*Cache[int]){
+		"map":         func(*Cache[int]) {},
+		"copyOnWrite": WithCopyOnWrite[int](),
+		"syncMap":     WithSyncMapBackend[int](),
+	} {
+		b.Run(name, func(b *testing.B) {
+			cache := NewCache(opt)
+			keys := make([]string, 1024)
+			for i := range keys {
+				keys[i] = strconv.Itoa(i)
+				cache.Set(keys[i], i, 0)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					key := keys[i%len(keys)]
+					if i%1000 == 0 {
+						_ = cache.Del(key)
+						cache.Set(key, i, 0)
+					} else {
+						_, _ = cache.Get(key)
+					}
+					i++
+				}
+			})
+		})
+	}
+}
+
+// BenchmarkCounters compares parallel increments of CounterCache and Cache.Update
+func BenchmarkCounters(b *testing.B) {
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+
+	b.Run("CounterCache", func(b *testing.B) {
+		counters := NewCounterCache(time.Hour)
+		b.ReportAllocs()
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			i := 0
+			for pb.Next() {
+				counters.Add(keys[i%len(keys)], 1)
+				i++
+			}
+		})
+	})
+	b.Run("Update", func(b *testing.B) {
+		cache := NewCache[int64]()
+		for _, key := range keys {
+			cache.Set(key, 0, time.Hour)
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			i := 0
+			for pb.Next() {
+				_ = cache.Update(keys[i%len(keys)], func(v int64) int64 { return v + 1 })
+				i++
+			}
+		})
+	})
+}
@@ -0,0 +1,94 @@
+package mcache
+
+import (
+	"hash/maphash"
+	"math"
+	"sync/atomic"
+)
+
+// bloomFilter is a Bloom filter of keys, safe for concurrent use.
+// It's dropped and started empty once it holds the expected number of keys,
+// so the false positive rate never grows beyond the configured one.
+type bloomFilter struct {
+	bits     atomic.Pointer[[]atomic.Uint64]
+	m, k     uint64 // number of bits and hashes
+	expected uint64
+	added    atomic.Uint64
+	seed     maphash.Seed
+}
+
+// newBloomFilter is a constructor for bloomFilter of n keys with false positive rate fp
+func newBloomFilter(n int, fp float64) *bloomFilter {
+	n = max(n, 1)
+	fp = math.Min(math.Max(fp, 1e-9), 0.5)
+	m := uint64(math.Ceil(-float64(n) * math.Log(fp) / (math.Ln2 * math.Ln2)))
+	f := &bloomFilter{
+		m:        max(m, 64),
+		k:        max(uint64(math.Round(float64(m)/float64(n)*math.Ln2)), 1),
+		expected: uint64(n),
+		seed:     maphash.MakeSeed(),
+	}
+	f.reset()
+	return f
+}
+
+// reset drops all keys
+func (f *bloomFilter) reset() {
+	bits := make([]atomic.Uint64, (f.m+63)/64)
+	f.bits.Store(&bits)
+	f.added.Store(0)
+}
+
+// add adds the key
+func (f *bloomFilter) add(key string) {
+	if f.added.Add(1) > f.expected {
+		f.reset()
+	}
+	bits := *f.bits.Load()
+	h1, h2 := f.hash(key)
+	for i := uint64(0); i < f.k; i++ {
+		bit := (h1 + i*h2) % f.m
+		for w := &bits[bit/64]; ; {
+			old := w.Load()
+			if old&(1<<(bit%64)) != 0 || w.CompareAndSwap(old, old|1<<(bit%64)) {
+				break
+			}
+		}
+	}
+}
+
+// has checks if the key may have been added, false positives are possible, false negatives are not
+func (f *bloomFilter) has(key string) bool {
+	bits := *f.bits.Load()
+	h1, h2 := f.hash(key)
+	for i := uint64(0); i < f.k; i++ {
+		bit := (h1 + i*h2) % f.m
+		if bits[bit/64].Load()&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// hash returns two hashes of the key for double hashing, the second one is odd
+func (f *bloomFilter) hash(key string) (h1, h2 uint64) {
+	h := maphash.String(f.seed, key)
+	return h, h>>32 | h<<32 | 1
+}
+
+// WithNegativeLookupFilter is a functional option for protecting the backing store from misses of junk keys:
+// keys the store reported missing are added to a Bloom filter sized for expectedKeys keys
+// with false positive rate fp, and further misses of them return ErrKeyNotFound without a Load.
+// It works WithReadThrough and for LoadingCache, when the loader returns ErrKeyNotFound.
+// A key added to the store later is still reported missing, unless it's cached, until the filter
+// is dropped, which happens when the filter is full and on Clear.
+func WithNegativeLookupFilter[T any](expectedKeys int, fp float64) func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.negative = newBloomFilter(expectedKeys, fp)
+	}
+}
+
+// knownMissing checks if the key was reported missing by the store, WithNegativeLookupFilter
+func (c *Cache[T]) knownMissing(key string) bool {
+	return c.negative != nil && c.negative.has(key)
+}
@@ -0,0 +1,67 @@
+package mcache
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBloomFilter(t *testing.T) {
+	f := newBloomFilter(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		f.add(strconv.Itoa(i))
+	}
+	for i := 0; i < 1000; i++ {
+		assert.True(t, f.has(strconv.Itoa(i)))
+	}
+	fp := 0
+	for i := 1000; i < 11000; i++ {
+		if f.has(strconv.Itoa(i)) {
+			fp++
+		}
+	}
+	assert.Less(t, fp, 200, "false positive rate is about 1%")
+
+	// full filter is dropped
+	f.add("more")
+	assert.False(t, f.has("0"))
+	assert.True(t, f.has("more"))
+}
+
+func TestWithNegativeLookupFilter(t *testing.T) {
+	store := newMapStore[string]()
+	store.data["key"] = "value"
+	cache := NewCache(WithReadThrough[string](store), WithNegativeLookupFilter[string](100, 0.01))
+
+	for i := 0; i < 3; i++ {
+		_, err := cache.Get("junk")
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+	}
+	assert.Equal(t, int32(1), store.loads.Load())
+	_, err := cache.Get("key")
+	require.NoError(t, err)
+
+	require.NoError(t, cache.Clear())
+	_, err = cache.Get("junk")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.Equal(t, int32(3), store.loads.Load())
+}
+
+func TestLoadingCacheNegativeLookupFilter(t *testing.T) {
+	var loads atomic.Int32
+	cache := NewLoadingCache(func(_ context.Context, key string) (int, time.Duration, error) {
+		loads.Add(1)
+		return 0, 0, ErrKeyNotFound
+	}, WithNegativeLookupFilter[int](100, 0.01))
+
+	for i := 0; i < 3; i++ {
+		_, err := cache.Get(context.Background(), "junk")
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+	}
+	assert.Equal(t, int32(1), loads.Load())
+}
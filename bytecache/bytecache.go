@@ -0,0 +1,228 @@
+// Package bytecache provides a cache of byte slice values stored in large preallocated slabs,
+// addressed by offset (bigcache/freecache style). The index holds no pointers,
+// so the GC doesn't scan entries, no matter how many of them are cached.
+package bytecache
+
+import (
+	"encoding/binary"
+	"hash/maphash"
+	"sync"
+	"time"
+
+	"github.com/parMaster/mcache"
+)
+
+// DefaultSlabSize is a size of a slab, if WithSlabSize is not set
+const DefaultSlabSize = 1 << 20
+
+// entry header: expiration (unix nanoseconds, 0 if entry doesn't expire), key length, value length
+const headerSize = 8 + 2 + 4
+
+// Cache is a cache of byte slice values, implementing mcache.Cacher[[]byte].
+// Entries are appended to slabs, and when all slabs are full, the oldest one is reused,
+// evicting its entries. Space of deleted and replaced entries is reclaimed the same way.
+type Cache struct {
+	slabSize int
+	slabs    [][]byte          // ring of slabs, slab with id n is slabs[n%len(slabs)]
+	cur      uint32            // id of the slab being written
+	index    map[uint64]uint64 // key hash to entry position: slab id<<32 | offset
+	seed     maphash.Seed
+	mu       sync.RWMutex
+}
+
+// New is a constructor for Cache holding up to capacity bytes of entries.
+// Capacity is split into slabs, at least two of them, allocated on first use.
+func New(capacity int, options ...func(*Cache)) *Cache {
+	c := &Cache{
+		slabSize: DefaultSlabSize,
+		index:    make(map[uint64]uint64),
+		seed:     maphash.MakeSeed(),
+	}
+	for _, option := range options {
+		option(c)
+	}
+	c.slabs = make([][]byte, max(capacity/c.slabSize, 2))
+	return c
+}
+
+// WithSlabSize is a functional option for setting the slab size, the maximum size of a single entry
+func WithSlabSize(size int) func(*Cache) {
+	return func(c *Cache) {
+		c.slabSize = size
+	}
+}
+
+// Set stores a copy of the value, with the same rules as mcache.Cache.Set.
+// Returns false if the entry (with the key and a header) is larger than the slab size.
+// Space of the replaced entry is reclaimed when its slab is reused.
+func (c *Cache) Set(key string, value []byte, ttl time.Duration) bool {
+	size := headerSize + len(key) + len(value)
+	if size > c.slabSize || len(key) > 0xffff {
+		return false
+	}
+
+	h := maphash.String(c.seed, key)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiration int64
+	if ttl > 0 {
+		expiration = time.Now().Add(ttl).UnixNano()
+	}
+	slab := c.slab(size)
+	offset := len(*slab)
+	*slab = binary.LittleEndian.AppendUint64(*slab, uint64(expiration))
+	*slab = binary.LittleEndian.AppendUint16(*slab, uint16(len(key)))
+	*slab = binary.LittleEndian.AppendUint32(*slab, uint32(len(value)))
+	*slab = append(*slab, key...)
+	*slab = append(*slab, value...)
+	c.index[h] = uint64(c.cur)<<32 | uint64(offset)
+	return true
+}
+
+// Get returns a copy of the value, with the same rules as mcache.Cache.Get
+func (c *Cache) Get(key string) ([]byte, error) {
+	var value []byte
+	err := c.lookup(key, func(v []byte) { value = append([]byte(nil), v...) })
+	return value, err
+}
+
+// Has checks if the key exists, with the same rules as mcache.Cache.Has
+func (c *Cache) Has(key string) (bool, error) {
+	if err := c.lookup(key, func([]byte) {}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Del deletes the key, with the same rules as mcache.Cache.Del.
+// Space of the entry is reclaimed when its slab is reused.
+func (c *Cache) Del(key string) error {
+	h := maphash.String(c.seed, key)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	pos, ok := c.index[h]
+	if !ok {
+		return mcache.ErrKeyNotFound
+	}
+	k, _, exp := c.entry(pos)
+	if string(k) != key {
+		return mcache.ErrKeyNotFound
+	}
+	delete(c.index, h)
+	if expired(exp) {
+		return mcache.ErrExpired
+	}
+	return nil
+}
+
+// Cleanup deletes expired keys from the index, their space is reclaimed when slabs are reused
+func (c *Cache) Cleanup() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for h, pos := range c.index {
+		if _, _, exp := c.entry(pos); expired(exp) {
+			delete(c.index, h)
+		}
+	}
+}
+
+// Clear deletes all entries, allocated slabs are kept for reuse
+func (c *Cache) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.index = make(map[uint64]uint64)
+	for i := range c.slabs {
+		c.slabs[i] = c.slabs[i][:0]
+	}
+	c.cur = 0
+	return nil
+}
+
+// Len returns the number of entries, including expired but not yet removed
+func (c *Cache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.index)
+}
+
+// lookup calls fn with the value of the live key under the read lock.
+// Expired entry is deleted under the write lock, unless the key was set again meanwhile.
+func (c *Cache) lookup(key string, fn func(value []byte)) error {
+	h := maphash.String(c.seed, key)
+	c.mu.RLock()
+	pos, ok := c.index[h]
+	if !ok {
+		c.mu.RUnlock()
+		return mcache.ErrKeyNotFound
+	}
+	k, v, exp := c.entry(pos)
+	if string(k) != key {
+		c.mu.RUnlock()
+		return mcache.ErrKeyNotFound
+	}
+	if !expired(exp) {
+		fn(v)
+		c.mu.RUnlock()
+		return nil
+	}
+	c.mu.RUnlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.index[h] == pos {
+		delete(c.index, h)
+	}
+	return mcache.ErrExpired
+}
+
+// entry reads the key, value and expiration of the entry at pos, must be called under lock
+func (c *Cache) entry(pos uint64) (key, value []byte, expiration int64) {
+	slab := c.slabs[(pos>>32)%uint64(len(c.slabs))]
+	offset := int(pos & 0xffffffff)
+	expiration = int64(binary.LittleEndian.Uint64(slab[offset:]))
+	keyLen := int(binary.LittleEndian.Uint16(slab[offset+8:]))
+	valueLen := int(binary.LittleEndian.Uint32(slab[offset+10:]))
+	start := offset + headerSize
+	return slab[start : start+keyLen], slab[start+keyLen : start+keyLen+valueLen], expiration
+}
+
+// slab returns the slab to append an entry of size bytes to, switching to the next slab
+// if the current one is full, and evicting entries of the next slab if it's reused.
+// Must be called under the write lock.
+func (c *Cache) slab(size int) *[]byte {
+	slab := &c.slabs[c.cur%uint32(len(c.slabs))]
+	if len(*slab)+size <= c.slabSize {
+		if *slab == nil {
+			*slab = make([]byte, 0, c.slabSize)
+		}
+		return slab
+	}
+
+	c.cur++
+	slab = &c.slabs[c.cur%uint32(len(c.slabs))]
+	if *slab == nil {
+		*slab = make([]byte, 0, c.slabSize)
+		return slab
+	}
+	c.evict(uint64(c.cur - uint32(len(c.slabs))))
+	*slab = (*slab)[:0]
+	return slab
+}
+
+// evict deletes from the index all entries still pointing to the slab with the id, must be called under lock
+func (c *Cache) evict(id uint64) {
+	slab := c.slabs[id%uint64(len(c.slabs))]
+	for offset := 0; offset < len(slab); {
+		pos := id<<32 | uint64(offset)
+		key, value, _ := c.entry(pos)
+		if h := maphash.Bytes(c.seed, key); c.index[h] == pos {
+			delete(c.index, h)
+		}
+		offset += headerSize + len(key) + len(value)
+	}
+}
+
+func expired(expiration int64) bool {
+	return expiration != 0 && expiration < time.Now().UnixNano()
+}
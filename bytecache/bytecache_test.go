@@ -0,0 +1,112 @@
+package bytecache
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache"
+)
+
+func TestCache(t *testing.T) {
+	var cache mcache.Cacher[[]byte] = New(1 << 20)
+	value := []byte("value")
+	assert.True(t, cache.Set("key", value, 0))
+	assert.True(t, cache.Set("key", []byte("other"), 0))
+	assert.True(t, cache.Set("key", value, 0))
+	value[0] = 'V' // stored value is a copy
+
+	v, err := cache.Get("key")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value"), v)
+	v[0] = 'V' // returned value is a copy
+	v, err = cache.Get("key")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value"), v)
+
+	has, err := cache.Has("key")
+	assert.NoError(t, err)
+	assert.True(t, has)
+	_, err = cache.Get("noSuchKey")
+	assert.ErrorIs(t, err, mcache.ErrKeyNotFound)
+	has, err = cache.Has("noSuchKey")
+	assert.ErrorIs(t, err, mcache.ErrKeyNotFound)
+	assert.False(t, has)
+
+	require.NoError(t, cache.Del("key"))
+	assert.ErrorIs(t, cache.Del("key"), mcache.ErrKeyNotFound)
+	assert.True(t, cache.Set("key", []byte("new"), 0))
+
+	cache.Set("expired", nil, time.Millisecond)
+	cache.Set("expiredDel", nil, time.Millisecond)
+	cache.Set("expiredCleanup", nil, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	_, err = cache.Get("expired")
+	assert.ErrorIs(t, err, mcache.ErrExpired)
+	_, err = cache.Get("expired")
+	assert.ErrorIs(t, err, mcache.ErrKeyNotFound)
+	assert.ErrorIs(t, cache.Del("expiredDel"), mcache.ErrExpired)
+	assert.Equal(t, 2, cache.(*Cache).Len())
+	cache.Cleanup()
+	assert.Equal(t, 1, cache.(*Cache).Len())
+	assert.True(t, cache.Set("expired", []byte("again"), time.Hour))
+
+	require.NoError(t, cache.Clear())
+	assert.Equal(t, 0, cache.(*Cache).Len())
+	_, err = cache.Get("key")
+	assert.ErrorIs(t, err, mcache.ErrKeyNotFound)
+}
+
+func TestCacheEviction(t *testing.T) {
+	cache := New(4096, WithSlabSize(1024))
+	assert.Len(t, cache.slabs, 4)
+	assert.False(t, cache.Set("large", make([]byte, 1024), 0))
+
+	// 100 bytes per entry, 10 entries per slab
+	value := make([]byte, 100-headerSize-3)
+	for i := 0; i < 100; i++ {
+		require.True(t, cache.Set(strconv.Itoa(100+i), value, 0))
+	}
+	// only the last slabs are kept, the oldest are reused
+	assert.LessOrEqual(t, cache.Len(), 40)
+	assert.Greater(t, cache.Len(), 30)
+	_, err := cache.Get("100")
+	assert.ErrorIs(t, err, mcache.ErrKeyNotFound)
+	_, err = cache.Get("199")
+	assert.NoError(t, err)
+	for _, slab := range cache.slabs {
+		assert.Equal(t, 1024, cap(slab))
+	}
+
+	// deleted entries are skipped on eviction
+	require.NoError(t, cache.Del("199"))
+	for i := 0; i < 100; i++ {
+		require.True(t, cache.Set(strconv.Itoa(200+i), value, 0))
+	}
+	_, err = cache.Get("198")
+	assert.ErrorIs(t, err, mcache.ErrKeyNotFound)
+	assert.LessOrEqual(t, cache.Len(), 40)
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	cache := New(1<<16, WithSlabSize(1<<12))
+	wg := sync.WaitGroup{}
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func(r int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				key := strconv.Itoa(r*1000 + i)
+				cache.Set(key, []byte(key), time.Hour)
+				if v, err := cache.Get(key); err == nil {
+					assert.Equal(t, key, string(v))
+				}
+			}
+		}(r)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,82 @@
+package mcache
+
+import "time"
+
+// GetBytes is Get for a key held in a byte slice, like a key read from the network.
+// The key is not converted to a string on the fast path, so a hit doesn't allocate.
+func (c *Cache[T]) GetBytes(key []byte) (T, error) {
+	if c.validateReads {
+		if err := c.validateKey(string(key)); err != nil {
+			var none T
+			return none, err
+		}
+	}
+	return c.get(c.lookupBytes(key))
+}
+
+// SetBytes is Set for a key held in a byte slice.
+// Existing key is checked without converting it to a string, the key is copied only when it's stored.
+func (c *Cache[T]) SetBytes(key []byte, value T, ttl time.Duration) bool {
+	c.Lock()
+	defer c.Unlock()
+	if c.closed || (c.keyValidator != nil && c.validateKey(string(key)) != nil) {
+		return false
+	}
+	_, ok := c.data[string(key)]
+	if !ok {
+		_, ok = c.old[string(key)]
+	}
+	if !ok && (c.spilled == nil || !c.spilledLive(string(key))) && c.full() {
+		return false
+	}
+	k := string(key)
+	c.setLocked(k, value, ttl)
+	c.writeBack(k, pendingWrite[T]{value: value, ttl: ttl})
+	return true
+}
+
+// lookupBytes is lookup for a key held in a byte slice, map index expressions
+// with string(key) don't allocate. WithSyncMapBackend the key is converted.
+func (c *Cache[T]) lookupBytes(key []byte) (CacheItem[T], error) {
+	if c.syncMap {
+		return c.lookup(string(key))
+	}
+
+	var item CacheItem[T]
+	var ok, old bool
+	if c.cow {
+		data := c.view.Load()
+		if data == nil {
+			return CacheItem[T]{}, ErrClosed
+		}
+		item, ok = (*data)[string(key)]
+	} else {
+		c.RLock()
+		if c.closed {
+			c.RUnlock()
+			return CacheItem[T]{}, ErrClosed
+		}
+		item, ok = c.data[string(key)]
+		if !ok {
+			item, old = c.old[string(key)]
+			if !old && c.spilled != nil {
+				_, old = c.spilled[string(key)]
+			}
+		}
+		c.RUnlock()
+	}
+
+	if !ok && !old {
+		return CacheItem[T]{}, c.keyError(ErrKeyNotFound, string(key))
+	}
+	if ok && !c.expired(item) {
+		return item, nil
+	}
+	if c.keepExpired && c.expired(item) {
+		return CacheItem[T]{}, c.keyError(ErrExpired, string(key))
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	return c.lookupLocked(string(key))
+}
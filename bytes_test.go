@@ -0,0 +1,69 @@
+package mcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache/clocktest"
+)
+
+func TestBytesKeys(t *testing.T) {
+	for name, opt := range map[string]func(*Cache[int]){
+		"map":         func(*Cache[int]) {},
+		"copyOnWrite": WithCopyOnWrite[int](),
+		"syncMap":     WithSyncMapBackend[int](),
+	} {
+		t.Run(name, func(t *testing.T) {
+			clock := clocktest.New(time.Now())
+			cache := NewCache(opt, WithClock[int](clock))
+			key := []byte("key")
+			assert.True(t, cache.SetBytes(key, 1, time.Hour))
+			assert.True(t, cache.SetBytes(key, 2, time.Hour))
+			key[0] = 'K' // stored key is a copy
+
+			v, err := cache.Get("key")
+			assert.NoError(t, err)
+			assert.Equal(t, 2, v)
+			v, err = cache.GetBytes([]byte("key"))
+			assert.NoError(t, err)
+			assert.Equal(t, 2, v)
+			_, err = cache.GetBytes(key)
+			assert.ErrorIs(t, err, ErrKeyNotFound)
+
+			cache.SetBytes([]byte("expired"), 1, time.Millisecond)
+			clock.Advance(time.Millisecond + 1)
+			_, err = cache.GetBytes([]byte("expired"))
+			assert.ErrorIs(t, err, ErrExpired)
+			assert.True(t, cache.SetBytes([]byte("expired"), 2, 0))
+
+			stats := cache.Stats()
+			assert.Equal(t, uint64(2), stats.Hits)
+			assert.Equal(t, uint64(2), stats.Misses)
+
+			require.NoError(t, cache.Close())
+			assert.False(t, cache.SetBytes(key, 1, 0))
+			_, err = cache.GetBytes(key)
+			assert.ErrorIs(t, err, ErrClosed)
+		})
+	}
+}
+
+func TestBytesKeysZeroAllocs(t *testing.T) {
+	cache := NewCache(WithMaxEntries[int](1), WithFullPolicy[int](Reject))
+	key := []byte("key")
+	cache.SetBytes(key, 1, time.Hour)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = cache.GetBytes(key)
+	})
+	assert.Zero(t, allocs, "GetBytes")
+
+	rejected := []byte("rejected")
+	allocs = testing.AllocsPerRun(100, func() {
+		cache.SetBytes(rejected, 2, time.Hour)
+	})
+	assert.Zero(t, allocs, "SetBytes rejected by a full cache")
+}
@@ -0,0 +1,267 @@
+// Package cacheapi provides an HTTP admin API of a cache, so operators can inspect and poke
+// the cache of a running service. Endpoints, with JSON bodies:
+//
+//	GET    /keys        {"keys": [...], "next": "cursor"}, a page of keys, see mcache.Cache.KeysPage,
+//	                    with ?cursor= and ?limit= query parameters, if the cache has KeysPage method
+//	GET    /keys/{key}  {"key": "k", "value": ...}
+//	PUT    /keys/{key}  {"value": ..., "ttl": "1m"}, replacing the value, see mcache.Cache.Set
+//	DELETE /keys/{key}
+//	GET    /stats       mcache.Stats, if the cache has Stats method
+//	POST   /cleanup
+//	GET    /snapshot    entries written by mcache.Cache.SaveTo, if the cache has SaveTo and LoadFrom methods
+//	PUT    /snapshot    entries loaded with mcache.Cache.LoadFrom, replacing existing keys
+//
+// Errors are returned as {"error": "..."} with the matching status code.
+// Mount the handler under a prefix with http.StripPrefix.
+package cacheapi
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parMaster/mcache"
+)
+
+// StatsProvider is a cache with statistics, like mcache.Cache
+type StatsProvider interface {
+	Stats() mcache.Stats
+}
+
+// TrySetter is a cache returning the reason a value isn't set, like mcache.Cache,
+// PUT of other caches responds with 503 when Set returns false
+type TrySetter[T any] interface {
+	TrySet(key string, value T, ttl time.Duration) error
+}
+
+// KeysPager is a cache listing keys page by page, like mcache.Cache
+type KeysPager interface {
+	KeysPage(cursor string, limit int) (keys []string, next string)
+}
+
+// Snapshotter is a cache saving and loading its entries, like mcache.Cache
+type Snapshotter interface {
+	SaveTo(w io.Writer) error
+	LoadFrom(r io.Reader) error
+}
+
+// defaultPageSize is the number of keys returned by GET /keys without the limit parameter
+const defaultPageSize = 1000
+
+type options struct {
+	auth     func(r *http.Request) bool
+	readOnly bool
+}
+
+// WithAuth is an option for authorizing requests, unauthorized ones get 401
+func WithAuth(auth func(r *http.Request) bool) func(*options) {
+	return func(o *options) {
+		o.auth = auth
+	}
+}
+
+// ReadOnly is an option for rejecting requests changing the cache with 403
+func ReadOnly() func(*options) {
+	return func(o *options) {
+		o.readOnly = true
+	}
+}
+
+// keysPage is a JSON body of GET /keys response
+type keysPage struct {
+	Keys []string `json:"keys"`
+	Next string   `json:"next,omitempty"`
+}
+
+// entry is a JSON body of key requests
+type entry[T any] struct {
+	Key   string `json:"key,omitempty"`
+	Value T      `json:"value"`
+	TTL   string `json:"ttl,omitempty"`
+}
+
+type handler[T any] struct {
+	cache mcache.Cacher[T]
+	options
+}
+
+// Handler returns the admin API handler of the cache
+func Handler[T any](c mcache.Cacher[T], opts ...func(*options)) http.Handler {
+	h := &handler[T]{cache: c}
+	for _, opt := range opts {
+		opt(&h.options)
+	}
+	return h
+}
+
+func (h *handler[T]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.auth != nil && !h.auth(r) {
+		writeError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+	if h.readOnly && r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeError(w, http.StatusForbidden, errors.New("read-only"))
+		return
+	}
+
+	switch path := r.URL.Path; {
+	case strings.HasPrefix(path, "/keys/") && len(path) > len("/keys/"):
+		h.serveKey(w, r, strings.TrimPrefix(path, "/keys/"))
+	case path == "/keys" && r.Method == http.MethodGet:
+		h.serveKeys(w, r)
+	case path == "/stats" && r.Method == http.MethodGet:
+		sp, ok := h.cache.(StatsProvider)
+		if !ok {
+			writeError(w, http.StatusNotFound, errors.New("stats are not available"))
+			return
+		}
+		writeJSON(w, http.StatusOK, sp.Stats())
+	case path == "/cleanup" && r.Method == http.MethodPost:
+		h.cache.Cleanup()
+		w.WriteHeader(http.StatusNoContent)
+	case path == "/snapshot" && (r.Method == http.MethodGet || r.Method == http.MethodPut):
+		h.serveSnapshot(w, r)
+	case path == "/keys" || path == "/stats" || path == "/cleanup" || path == "/snapshot":
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+	default:
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+	}
+}
+
+// serveKeys serves a page of keys
+func (h *handler[T]) serveKeys(w http.ResponseWriter, r *http.Request) {
+	kp, ok := h.cache.(KeysPager)
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.New("keys are not available"))
+		return
+	}
+	limit := defaultPageSize
+	if l := r.URL.Query().Get("limit"); l != "" {
+		var err error
+		if limit, err = strconv.Atoi(l); err != nil || limit < 1 {
+			writeError(w, http.StatusBadRequest, errors.New("invalid limit"))
+			return
+		}
+	}
+	keys, next := kp.KeysPage(r.URL.Query().Get("cursor"), limit)
+	writeJSON(w, http.StatusOK, keysPage{Keys: keys, Next: next})
+}
+
+// serveSnapshot streams the snapshot of the cache or loads one into it.
+// Snapshots are not buffered, so an error after a part of the snapshot is sent aborts the connection.
+func (h *handler[T]) serveSnapshot(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.cache.(Snapshotter)
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.New("snapshots are not available"))
+		return
+	}
+	if r.Method == http.MethodPut {
+		if err := s.LoadFrom(r.Body); err != nil {
+			status := errorStatus(err)
+			if status == http.StatusInternalServerError {
+				status = http.StatusBadRequest // undecodable snapshot
+			}
+			writeError(w, status, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	sw := &sentWriter{w: w}
+	if err := s.SaveTo(sw); err != nil {
+		if !sw.sent {
+			writeError(w, errorStatus(err), err)
+			return
+		}
+		// the status is sent already, aborting the connection is the only way to tell
+		// the client the snapshot is truncated
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// sentWriter is a writer of the response body, reporting if anything is written
+type sentWriter struct {
+	w    io.Writer
+	sent bool
+}
+
+func (s *sentWriter) Write(p []byte) (int, error) {
+	s.sent = s.sent || len(p) > 0
+	return s.w.Write(p)
+}
+
+// serveKey serves requests of a single key
+func (h *handler[T]) serveKey(w http.ResponseWriter, r *http.Request, key string) {
+	switch r.Method {
+	case http.MethodGet:
+		v, err := h.cache.Get(key)
+		if err != nil {
+			writeError(w, errorStatus(err), err)
+			return
+		}
+		writeJSON(w, http.StatusOK, entry[T]{Key: key, Value: v})
+	case http.MethodPut:
+		var e entry[T]
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		var ttl time.Duration
+		if e.TTL != "" {
+			var err error
+			if ttl, err = time.ParseDuration(e.TTL); err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+		}
+		if ts, ok := h.cache.(TrySetter[T]); ok {
+			if err := ts.TrySet(key, e.Value, ttl); err != nil {
+				writeError(w, errorStatus(err), err)
+				return
+			}
+		} else if !h.cache.Set(key, e.Value, ttl) {
+			writeError(w, http.StatusServiceUnavailable, errors.New("value is not set"))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		if err := h.cache.Del(key); err != nil {
+			writeError(w, errorStatus(err), err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+	}
+}
+
+// errorStatus maps cache errors to HTTP status codes
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, mcache.ErrKeyNotFound), errors.Is(err, mcache.ErrExpired):
+		return http.StatusNotFound
+	case errors.Is(err, mcache.ErrInvalidKey):
+		return http.StatusBadRequest
+	case errors.Is(err, mcache.ErrCacheFull):
+		return http.StatusInsufficientStorage
+	case errors.Is(err, mcache.ErrClosed):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
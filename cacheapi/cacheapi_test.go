@@ -0,0 +1,195 @@
+package cacheapi
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache"
+)
+
+type testValue struct {
+	Name string `json:"name"`
+}
+
+func do(t *testing.T, h http.Handler, method, path, body string) (int, map[string]any) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+	var resp map[string]any
+	if w.Body.Len() > 0 {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+	return w.Code, resp
+}
+
+func TestHandler(t *testing.T) {
+	cache := mcache.NewCache[testValue]()
+	h := Handler[testValue](cache)
+
+	code, _ := do(t, h, http.MethodPut, "/keys/user/1", `{"value": {"name": "one"}, "ttl": "1m"}`)
+	assert.Equal(t, http.StatusNoContent, code)
+	code, _ = do(t, h, http.MethodPut, "/keys/user/2", `{"value": {"name": "two"}}`)
+	assert.Equal(t, http.StatusNoContent, code)
+	code, _ = do(t, h, http.MethodPut, "/keys/user/2", `{"value": {"name": "other"}}`)
+	assert.Equal(t, http.StatusNoContent, code)
+	v, err := cache.Get("user/2")
+	require.NoError(t, err)
+	assert.Equal(t, "other", v.Name)
+	require.NoError(t, cache.Del("user/2"))
+	info, err := cache.EntryInfo("user/1")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), info.Expiration, time.Second)
+
+	code, resp := do(t, h, http.MethodGet, "/keys/user/1", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, map[string]any{"key": "user/1", "value": map[string]any{"name": "one"}}, resp)
+
+	code, _ = do(t, h, http.MethodPut, "/keys/bad", `{"value": `)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = do(t, h, http.MethodPut, "/keys/bad", `{"value": {}, "ttl": "forever"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = do(t, h, http.MethodPatch, "/keys/bad", ``)
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+
+	code, resp = do(t, h, http.MethodGet, "/stats", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, float64(1), resp["Entries"])
+	code, _ = do(t, h, http.MethodPost, "/cleanup", "")
+	assert.Equal(t, http.StatusNoContent, code)
+	code, _ = do(t, h, http.MethodGet, "/cleanup", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+	code, _ = do(t, h, http.MethodGet, "/keys/", "")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, _ = do(t, h, http.MethodDelete, "/keys/user/1", "")
+	assert.Equal(t, http.StatusNoContent, code)
+	code, resp = do(t, h, http.MethodDelete, "/keys/user/1", "")
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.Equal(t, mcache.ErrKeyNotFound.Error(), resp["error"])
+	code, _ = do(t, h, http.MethodGet, "/keys/user/1", "")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	require.NoError(t, cache.Close())
+	code, _ = do(t, h, http.MethodGet, "/keys/user/1", "")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	code, _ = do(t, h, http.MethodPut, "/keys/user/1", `{"value": {}}`)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	code, _ = do(t, Handler[testValue](struct{ mcache.Cacher[testValue] }{cache}), http.MethodPut, "/keys/user/1", `{"value": {}}`)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+}
+
+func TestHandlerFull(t *testing.T) {
+	cache := mcache.NewCache(mcache.WithMaxEntries[testValue](1), mcache.WithFullPolicy[testValue](mcache.Reject))
+	h := Handler[testValue](cache)
+
+	code, _ := do(t, h, http.MethodPut, "/keys/1", `{"value": {}}`)
+	assert.Equal(t, http.StatusNoContent, code)
+	code, _ = do(t, h, http.MethodPut, "/keys/1", `{"value": {"name": "one"}}`)
+	assert.Equal(t, http.StatusNoContent, code, "existing key is replaced in a full cache")
+	code, resp := do(t, h, http.MethodPut, "/keys/2", `{"value": {}}`)
+	assert.Equal(t, http.StatusInsufficientStorage, code)
+	assert.Equal(t, mcache.ErrCacheFull.Error(), resp["error"])
+}
+
+func TestHandlerKeysAndSnapshot(t *testing.T) {
+	cache := mcache.NewCache[string]()
+	for _, k := range []string{"c", "a", "b"} {
+		cache.Set(k, "value "+k, time.Hour)
+	}
+	h := Handler[string](cache)
+
+	code, resp := do(t, h, http.MethodGet, "/keys?limit=2", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []any{"a", "b"}, resp["keys"])
+	code, resp = do(t, h, http.MethodGet, "/keys?cursor="+url.QueryEscape(resp["next"].(string)), "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, map[string]any{"keys": []any{"c"}}, resp)
+	code, _ = do(t, h, http.MethodGet, "/keys?limit=0", "")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = do(t, h, http.MethodDelete, "/keys", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/snapshot", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/octet-stream", w.Header().Get("Content-Type"))
+	snapshot := w.Body.Bytes()
+
+	restored := mcache.NewCache[string]()
+	code, _ = do(t, Handler[string](restored), http.MethodPut, "/snapshot", string(snapshot))
+	assert.Equal(t, http.StatusNoContent, code)
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, restored.Keys())
+	code, _ = do(t, Handler[string](restored), http.MethodPut, "/snapshot", "garbage")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = do(t, Handler[string](restored, ReadOnly()), http.MethodPut, "/snapshot", string(snapshot))
+	assert.Equal(t, http.StatusForbidden, code)
+
+	// an error after the snapshot is partly sent aborts the connection, so it's not taken for a complete one
+	unencodable := mcache.NewCache[any]()
+	unencodable.Set("key", struct{ Name string }{"unregistered gob type"}, 0)
+	srv := httptest.NewServer(Handler[any](unencodable))
+	defer srv.Close()
+	r, err := http.Get(srv.URL + "/snapshot")
+	if err == nil {
+		_, err = io.ReadAll(r.Body)
+		r.Body.Close()
+	}
+	assert.Error(t, err)
+
+	// an error before anything is sent is reported
+	code, resp = do(t, Handler[string](failingSnapshotter{cache}), http.MethodGet, "/snapshot", "")
+	assert.Equal(t, http.StatusInternalServerError, code)
+	assert.Equal(t, "snapshot failed", resp["error"])
+
+	// keys and snapshots are served only for caches having them
+	other := Handler[string](struct{ mcache.Cacher[string] }{cache})
+	code, _ = do(t, other, http.MethodGet, "/keys", "")
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = do(t, other, http.MethodGet, "/snapshot", "")
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+// failingSnapshotter is a cache failing to save snapshots
+type failingSnapshotter struct {
+	mcache.Cacher[string]
+}
+
+func (failingSnapshotter) SaveTo(io.Writer) error   { return errors.New("snapshot failed") }
+func (failingSnapshotter) LoadFrom(io.Reader) error { return nil }
+
+func TestHandlerOptions(t *testing.T) {
+	cache := mcache.NewCache[string]()
+	cache.Set("key", "value", 0)
+	h := Handler[string](cache, ReadOnly(), WithAuth(func(r *http.Request) bool {
+		return r.Header.Get("Authorization") == "Bearer secret"
+	}))
+
+	code, _ := do(t, h, http.MethodGet, "/keys/key", "")
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/keys/key", nil)
+	r.Header.Set("Authorization", "Bearer secret")
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodDelete, "/keys/key", nil)
+	r.Header.Set("Authorization", "Bearer secret")
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// stats are served only for caches having them
+	code, _ = do(t, Handler[string](struct{ mcache.Cacher[string] }{cache}), http.MethodGet, "/stats", "")
+	assert.Equal(t, http.StatusNotFound, code)
+}
@@ -0,0 +1,208 @@
+// Package cachetest provides a conformance suite for implementations of mcache.Cacher, so alternative
+// backends, wrappers and clients of remote caches can check they honor the contract of mcache.Cache:
+//
+//	func TestConformance(t *testing.T) {
+//		cachetest.Run(t, func() mcache.Cacher[[]byte] { return mybackend.New() },
+//			func(i int) []byte { return []byte(strconv.Itoa(i)) })
+//	}
+package cachetest
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache"
+)
+
+// TTL is the TTL of expiring entries set by the suite, they are checked after twice the TTL.
+// Expiration is checked with real time, as Cacher has no clock to control.
+const TTL = 50 * time.Millisecond
+
+// Run runs the conformance suite as subtests of t. newCache must return a new empty cache for every subtest,
+// it's closed after the subtest if it implements io.Closer. newValue must return a distinct value for every i,
+// values are compared with assert.Equal. The suite checks that:
+//   - Set replaces the value of an existing key, Get returns the last value set
+//   - Get, Has and Del of a missing key return mcache.ErrKeyNotFound
+//   - an entry expires after its TTL, Get and Has return mcache.ErrExpired or mcache.ErrKeyNotFound,
+//     and an entry set with zero TTL doesn't expire
+//   - Cleanup deletes expired entries only, Clear deletes all of them
+//   - concurrent operations are safe, no write is lost; run tests with -race to detect data races
+//
+// Caches evicting live entries, like mcache.Cache WithMaxEntries or WithGenerations, don't pass the suite.
+func Run[T any](t *testing.T, newCache func() mcache.Cacher[T], newValue func(i int) T) {
+	for _, tc := range []struct {
+		name string
+		test func(t *testing.T, c mcache.Cacher[T], value func(i int) T)
+	}{
+		{"SetGet", testSetGet[T]},
+		{"Missing", testMissing[T]},
+		{"Del", testDel[T]},
+		{"TTL", testTTL[T]},
+		{"Cleanup", testCleanup[T]},
+		{"Clear", testClear[T]},
+		{"Concurrent", testConcurrent[T]},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newCache()
+			if closer, ok := c.(io.Closer); ok {
+				t.Cleanup(func() { _ = closer.Close() })
+			}
+			tc.test(t, c, newValue)
+		})
+	}
+}
+
+func testSetGet[T any](t *testing.T, c mcache.Cacher[T], value func(i int) T) {
+	require.True(t, c.Set("key", value(1), 0))
+	v, err := c.Get("key")
+	require.NoError(t, err)
+	assert.Equal(t, value(1), v)
+	ok, err := c.Has("key")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	require.True(t, c.Set("key", value(2), time.Hour), "existing key is replaced")
+	v, err = c.Get("key")
+	require.NoError(t, err)
+	assert.Equal(t, value(2), v)
+
+	var zero T
+	require.True(t, c.Set("zero", zero, 0))
+	v, err = c.Get("zero")
+	require.NoError(t, err, "zero value is not a missing one")
+	assert.Equal(t, zero, v)
+}
+
+func testMissing[T any](t *testing.T, c mcache.Cacher[T], _ func(i int) T) {
+	v, err := c.Get("missing")
+	assert.ErrorIs(t, err, mcache.ErrKeyNotFound)
+	var zero T
+	assert.Equal(t, zero, v, "zero value is returned with an error")
+	ok, err := c.Has("missing")
+	assert.ErrorIs(t, err, mcache.ErrKeyNotFound)
+	assert.False(t, ok)
+	assert.ErrorIs(t, c.Del("missing"), mcache.ErrKeyNotFound)
+}
+
+func testDel[T any](t *testing.T, c mcache.Cacher[T], value func(i int) T) {
+	require.True(t, c.Set("key", value(1), 0))
+	require.True(t, c.Set("other", value(2), 0))
+	require.NoError(t, c.Del("key"))
+	_, err := c.Get("key")
+	assert.ErrorIs(t, err, mcache.ErrKeyNotFound)
+	assert.ErrorIs(t, c.Del("key"), mcache.ErrKeyNotFound, "second Del")
+	_, err = c.Get("other")
+	assert.NoError(t, err, "other keys are kept")
+
+	require.True(t, c.Set("key", value(3), 0), "deleted key is set again")
+	v, err := c.Get("key")
+	require.NoError(t, err)
+	assert.Equal(t, value(3), v)
+}
+
+func testTTL[T any](t *testing.T, c mcache.Cacher[T], value func(i int) T) {
+	require.True(t, c.Set("expiring", value(1), TTL))
+	require.True(t, c.Set("forever", value(1), 0))
+	require.True(t, c.Set("replaced", value(1), TTL))
+	require.True(t, c.Set("replaced", value(1), 0), "replacing the value replaces its TTL")
+	v, err := c.Get("expiring")
+	require.NoError(t, err, "entry is live before its TTL")
+	assert.Equal(t, value(1), v)
+
+	time.Sleep(2 * TTL)
+	v, err = c.Get("expiring")
+	assertExpired(t, err)
+	var zero T
+	assert.Equal(t, zero, v)
+	ok, err := c.Has("expiring")
+	assertExpired(t, err)
+	assert.False(t, ok)
+	for _, key := range []string{"forever", "replaced"} {
+		_, err = c.Get(key)
+		assert.NoError(t, err, "entry set with zero TTL doesn't expire: %s", key)
+	}
+
+	require.True(t, c.Set("expiring", value(2), 0), "expired key is set again")
+	v, err = c.Get("expiring")
+	require.NoError(t, err)
+	assert.Equal(t, value(2), v)
+}
+
+func testCleanup[T any](t *testing.T, c mcache.Cacher[T], value func(i int) T) {
+	for i := 0; i < 10; i++ {
+		require.True(t, c.Set("expiring"+strconv.Itoa(i), value(i), TTL))
+		require.True(t, c.Set("live"+strconv.Itoa(i), value(i), time.Hour))
+	}
+	time.Sleep(2 * TTL)
+	c.Cleanup()
+	for i := 0; i < 10; i++ {
+		_, err := c.Get("expiring" + strconv.Itoa(i))
+		assertExpired(t, err)
+		_, err = c.Get("live" + strconv.Itoa(i))
+		assert.NoError(t, err, "live entry is kept")
+	}
+}
+
+func testClear[T any](t *testing.T, c mcache.Cacher[T], value func(i int) T) {
+	for i := 0; i < 10; i++ {
+		require.True(t, c.Set(strconv.Itoa(i), value(i), 0))
+	}
+	require.NoError(t, c.Clear())
+	for i := 0; i < 10; i++ {
+		_, err := c.Get(strconv.Itoa(i))
+		assert.ErrorIs(t, err, mcache.ErrKeyNotFound)
+	}
+	require.True(t, c.Set("key", value(1), 0), "cleared cache is usable")
+	_, err := c.Get("key")
+	assert.NoError(t, err)
+}
+
+func testConcurrent[T any](t *testing.T, c mcache.Cacher[T], value func(i int) T) {
+	const workers, keys = 16, 100
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < keys; i++ {
+				// own keys are checked after all, shared ones are only contended
+				own := strconv.Itoa(w) + ":" + strconv.Itoa(i)
+				c.Set(own, value(w*keys+i), 0)
+				c.Set("shared"+strconv.Itoa(i), value(w*keys+i), time.Hour)
+				_, _ = c.Get("shared" + strconv.Itoa(i))
+				_, _ = c.Has("shared" + strconv.Itoa((i+1)%keys))
+				if i%10 == 0 {
+					_ = c.Del("shared" + strconv.Itoa(i))
+					c.Cleanup()
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	for w := 0; w < workers; w++ {
+		for i := 0; i < keys; i++ {
+			own := strconv.Itoa(w) + ":" + strconv.Itoa(i)
+			v, err := c.Get(own)
+			if !assert.NoError(t, err, own) {
+				return
+			}
+			assert.Equal(t, value(w*keys+i), v)
+		}
+	}
+}
+
+// assertExpired checks the error of an expired entry, either error is allowed,
+// as the entry may have been deleted already
+func assertExpired(t *testing.T, err error) {
+	t.Helper()
+	assert.True(t, errors.Is(err, mcache.ErrExpired) || errors.Is(err, mcache.ErrKeyNotFound),
+		"expired entry: %v", err)
+}
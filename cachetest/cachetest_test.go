@@ -0,0 +1,68 @@
+package cachetest
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/parMaster/mcache"
+	"github.com/parMaster/mcache/bytecache"
+)
+
+// stringValue is the value factory of caches of strings
+func stringValue(i int) string {
+	return "value " + strconv.Itoa(i)
+}
+
+// bytesValue is the value factory of caches of byte slices
+func bytesValue(i int) []byte {
+	return []byte("value " + strconv.Itoa(i))
+}
+
+// TestCache runs the suite on backends of mcache.Cache. WithGenerations is not included,
+// as its Cleanup evicts live entries not accessed during two runs by design.
+func TestCache(t *testing.T) {
+	for name, opt := range map[string]func(*mcache.Cache[string]){
+		"map":            func(*mcache.Cache[string]) {},
+		"copyOnWrite":    mcache.WithCopyOnWrite[string](),
+		"syncMap":        mcache.WithSyncMapBackend[string](),
+		"expirationHeap": mcache.WithExpirationHeap[string](),
+		"timingWheel":    mcache.WithTimingWheel[string](10 * time.Millisecond),
+		"sampledCleanup": mcache.WithSampledCleanup[string](5),
+		"lazyDeletion":   mcache.WithLazyDeletion[string](false),
+	} {
+		t.Run(name, func(t *testing.T) {
+			Run(t, func() mcache.Cacher[string] { return mcache.NewCache(opt) }, stringValue)
+		})
+	}
+}
+
+func TestStripedCache(t *testing.T) {
+	Run(t, func() mcache.Cacher[string] { return mcache.NewStripedCache[string](4) }, stringValue)
+}
+
+func TestNamespace(t *testing.T) {
+	Run(t, func() mcache.Cacher[string] {
+		cache := mcache.NewCache[string]()
+		cache.Set("other:key", "value", 0)
+		return cache.Namespace("ns:")
+	}, stringValue)
+}
+
+func TestShardedClient(t *testing.T) {
+	Run(t, func() mcache.Cacher[string] {
+		client := mcache.NewShardedClient[string](100)
+		for i := 0; i < 3; i++ {
+			client.AddNode("node"+strconv.Itoa(i), mcache.NewCache[string]())
+		}
+		return client
+	}, stringValue)
+}
+
+func TestCoalescer(t *testing.T) {
+	Run(t, func() mcache.Cacher[string] { return mcache.NewCoalescer[string](mcache.NewCache[string]()) }, stringValue)
+}
+
+func TestByteCache(t *testing.T) {
+	Run(t, func() mcache.Cacher[[]byte] { return bytecache.New(1 << 20) }, bytesValue)
+}
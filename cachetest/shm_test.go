@@ -0,0 +1,21 @@
+//go:build linux || darwin || freebsd
+
+package cachetest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache"
+	"github.com/parMaster/mcache/shm"
+)
+
+func TestShm(t *testing.T) {
+	Run(t, func() mcache.Cacher[[]byte] {
+		c, err := shm.Open(filepath.Join(t.TempDir(), "cache"), 1<<14, 64)
+		require.NoError(t, err)
+		return c
+	}, bytesValue)
+}
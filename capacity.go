@@ -0,0 +1,221 @@
+package mcache
+
+import "time"
+
+// evictionSamples is a number of entries sampled to pick the least recently used one to evict
+const evictionSamples = 5
+
+// WithMaxEntries is a functional option for limiting the number of entries. When a new key is stored
+// in a full cache, an expired entry or the least recently used one is evicted to make room.
+// LRU is approximated like in Redis: the least recently used of a few sampled entries is evicted,
+// so there is no list to update on every Get. Recency is tracked WithEntryStats, which is enabled.
+// Evictions are counted in Stats.
+func WithMaxEntries[T any](n int) func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.maxEntries = n
+		c.entryStats = true
+	}
+}
+
+// FullPolicy is a behavior of a cache full WithMaxEntries, set WithFullPolicy
+type FullPolicy int
+
+// Full policies
+const (
+	Evict       FullPolicy = iota // evict an entry to make room for a new key, the default
+	Reject                        // reject new keys, Set returns false and TrySet returns ErrCacheFull
+	EvictOldest                   // evict the oldest inserted entry (FIFO), tracking insertion order
+)
+
+// WithFullPolicy is a functional option for the behavior of a cache full WithMaxEntries:
+// Evict evicts an entry to make room for a new key, Reject rejects new keys for explicit backpressure,
+// unless there is an expired entry to remove among sampled ones.
+// Only Set and its variants reject keys, LoadFrom and other bulk writes may exceed the limit.
+// EvictOldest evicts the entry inserted first, regardless of access and priority, like WithInsertionOrder.
+func WithFullPolicy[T any](policy FullPolicy) func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.fullPolicy = policy
+		if policy == EvictOldest {
+			WithInsertionOrder[T]()(c)
+		}
+	}
+}
+
+// full checks if a new key is rejected WithFullPolicy(Reject), removing an expired entry
+// among sampled ones to make room, if there is one. Must be called under lock.
+func (c *Cache[T]) full() bool {
+	if c.maxEntries <= 0 || c.fullPolicy != Reject || len(c.data)+len(c.old) < c.maxEntries {
+		return false
+	}
+	n, now := 0, c.now()
+	for k, v := range c.data {
+		if n == evictionSamples {
+			break
+		}
+		n++
+		if v.expiredAt(now) {
+			c.remove(k, v)
+			c.evicted(1)
+			return false
+		}
+	}
+	return true
+}
+
+// makeRoom evicts entries until there is room for a new key, must be called under lock
+func (c *Cache[T]) makeRoom() {
+	for len(c.data)+len(c.old) >= c.maxEntries && c.evictOne() {
+	}
+}
+
+// evictOne evicts an entry of the old generation, if there is one, or the least recently used
+// of sampled entries of the lowest priority, preferring expired ones, or the oldest inserted entry
+// WithFullPolicy(EvictOldest). Low priority entries are sampled from their own pool, so they are
+// evicted first even if they are rare among the others. Pinned entries are skipped.
+// Returns false if there is nothing to evict.
+// Must be called under lock.
+func (c *Cache[T]) evictOne() bool {
+	if c.fullPolicy == EvictOldest {
+		return c.evictOldest()
+	}
+	for k, v := range c.old {
+		if _, ok := c.pinned[k]; ok {
+			continue
+		}
+		c.dropOld(k)
+		c.evicted(1)
+		if !c.expired(v) {
+			c.spill(k, v)
+		}
+		return true
+	}
+
+	now := c.now()
+	victim, item, found := sampleVictim(c, c.lowPriority, now)
+	if !found {
+		victim, item, found = sampleVictim(c, c.data, now)
+	}
+	if !found {
+		return false
+	}
+	c.remove(victim, item)
+	c.evicted(1)
+	if !item.expiredAt(now) {
+		c.spill(victim, item)
+	}
+	return true
+}
+
+// sampleVictim picks an entry to evict among sampled keys of the pool: the first expired one,
+// or the least recently used of the lowest priority. Pinned entries are skipped.
+// Must be called under lock.
+func sampleVictim[T, P any](c *Cache[T], pool map[string]P, now int64) (victim string, item CacheItem[T], found bool) {
+	var used int64
+	var prio Priority
+	n := 0
+	for k := range pool {
+		if n == evictionSamples {
+			break
+		}
+		if _, ok := c.pinned[k]; ok {
+			continue
+		}
+		v, ok := c.data[k]
+		if !ok {
+			continue
+		}
+		n++
+		if v.expiredAt(now) {
+			return k, v, true
+		}
+		if u, p := lastUsed(v), priorityOf(v); !found || p < prio || (p == prio && u < used) {
+			victim, item, used, prio, found = k, v, u, p, true
+		}
+	}
+	return victim, item, found
+}
+
+// lastUsed returns the last access time of the item, or its creation time if it was never accessed
+func lastUsed[T any](item CacheItem[T]) int64 {
+	if item.meta == nil {
+		return 0
+	}
+	if accessed := item.meta.accessed.Load(); accessed != 0 {
+		return accessed
+	}
+	return item.meta.created.UnixNano()
+}
+
+// Priority is a priority of an entry in capacity eviction, see SetWithPriority
+type Priority int8
+
+// Priorities of entries, entries set with Set have PriorityNormal
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+)
+
+// SetWithPriority is Set of an entry with the priority in capacity eviction WithMaxEntries:
+// entries of lower priority are evicted first, regardless of recency, so cheap to rebuild values
+// lose to expensive ones. PriorityLow keys are sampled from a pool of their own, high and normal
+// ones are compared among sampled entries. Without WithMaxEntries it's Set.
+func (c *Cache[T]) SetWithPriority(key string, value T, ttl time.Duration, priority Priority) bool {
+	c.Lock()
+	defer c.Unlock()
+	if c.trySetLocked(key, value, ttl, true) != nil {
+		return false
+	}
+	if item := c.data[key]; item.meta != nil {
+		item.meta.priority = priority
+		if priority == PriorityLow {
+			if c.lowPriority == nil {
+				c.lowPriority = make(map[string]struct{})
+			}
+			c.lowPriority[key] = struct{}{}
+		}
+	}
+	return true
+}
+
+// priorityOf returns the priority of the item
+func priorityOf[T any](item CacheItem[T]) Priority {
+	if item.meta == nil {
+		return PriorityNormal
+	}
+	return item.meta.priority
+}
+
+// Pin exempts the key from capacity eviction WithMaxEntries, so a handful of entries, like configs,
+// are never evicted, even when the cache is full. The key doesn't have to exist, it's pinned
+// when it's set. A cache full of pinned entries grows beyond its limit.
+func (c *Cache[T]) Pin(key string) {
+	c.Lock()
+	defer c.Unlock()
+	if c.pinned == nil {
+		c.pinned = make(map[string]bool)
+	}
+	c.pinned[key] = false
+}
+
+// PinForever is Pin, exempting the key from TTL expiry as well:
+// the current entry of the key and entries set later never expire
+func (c *Cache[T]) PinForever(key string) {
+	c.Lock()
+	defer c.Unlock()
+	if c.pinned == nil {
+		c.pinned = make(map[string]bool)
+	}
+	c.pinned[key] = true
+	if item, ok := c.data[key]; ok && item.expiration != 0 && !c.expired(item) {
+		item.expiration, item.node = 0, nil // node is released by store
+		c.store(key, item)
+	}
+}
+
+// Unpin makes the key evictable again, entries set later expire with their TTLs
+func (c *Cache[T]) Unpin(key string) {
+	c.Lock()
+	defer c.Unlock()
+	delete(c.pinned, key)
+}
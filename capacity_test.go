@@ -0,0 +1,194 @@
+package mcache
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache/clocktest"
+)
+
+func TestWithMaxEntries(t *testing.T) {
+	// all entries are sampled in a cache this small, so the least recently used one is always evicted
+	clock := clocktest.New(time.Now())
+	cache := NewCache(WithMaxEntries[int](evictionSamples), WithClock[int](clock))
+	for i := 0; i < evictionSamples; i++ {
+		cache.Set(strconv.Itoa(i), i, 0)
+		clock.Advance(time.Second)
+	}
+	// recently used keys survive
+	for i := 2; i < evictionSamples; i++ {
+		_, err := cache.Get(strconv.Itoa(i))
+		require.NoError(t, err)
+	}
+	clock.Advance(time.Second)
+
+	cache.Set("new", 0, 0)
+	clock.Advance(time.Second)
+	cache.Set("newer", 0, 0)
+	assert.Equal(t, evictionSamples, cache.Stats().Entries)
+	assert.Equal(t, uint64(2), cache.Stats().Evictions)
+	for _, key := range []string{"0", "1"} {
+		has, _ := cache.Has(key)
+		assert.False(t, has, key)
+	}
+
+	// replacing a key doesn't evict
+	require.NoError(t, cache.Del("newer"))
+	cache.Set("newer", 1, 0)
+	assert.Equal(t, uint64(2), cache.Stats().Evictions)
+
+	// large cache stays within the limit
+	cache = NewCache(WithMaxEntries[int](100))
+	for i := 0; i < 1000; i++ {
+		cache.Set(strconv.Itoa(i), i, 0)
+	}
+	assert.Equal(t, 100, cache.Stats().Entries)
+	assert.Equal(t, uint64(900), cache.Stats().Evictions)
+}
+
+func TestWithMaxEntriesExpiredFirst(t *testing.T) {
+	cache := NewCache(WithMaxEntries[int](evictionSamples), WithGenerations[int]())
+	cache.Set("old", 0, 0)
+	cache.CleanupN() // moves the key to the old generation
+	cache.Set("expired", 0, time.Nanosecond)
+	for i := 0; i < evictionSamples-2; i++ {
+		cache.Set(strconv.Itoa(i), i, 0)
+	}
+	time.Sleep(time.Millisecond)
+
+	// old generation is evicted first, expired entries next
+	cache.Set("new", 1, 0)
+	_, err := cache.Get("old")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	cache.Set("newer", 1, 0)
+	_, err = cache.Get("expired")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	for i := 0; i < evictionSamples-2; i++ {
+		has, _ := cache.Has(strconv.Itoa(i))
+		assert.True(t, has, i)
+	}
+}
+
+func TestPin(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	cache := NewCache(WithMaxEntries[int](3), WithClock[int](clock), WithExpirationHeap[int]())
+	cache.Pin("config")
+	cache.Set("config", 1, 0)
+	cache.Set("flags", 2, time.Second)
+	cache.PinForever("flags")
+	cache.PinForever("limits")
+	cache.Set("limits", 3, time.Second)
+
+	for i := 0; i < 10; i++ {
+		clock.Advance(time.Second)
+		cache.Set(strconv.Itoa(i), i, 0)
+	}
+	cache.Cleanup()
+	for _, key := range []string{"config", "flags", "limits", "9"} {
+		has, _ := cache.Has(key)
+		assert.True(t, has, key)
+	}
+	assert.Equal(t, 4, cache.Stats().Entries, "full of pinned entries")
+
+	info, err := cache.EntryInfo("limits")
+	require.NoError(t, err)
+	assert.True(t, info.Expiration.IsZero())
+
+	cache.Unpin("config")
+	cache.Unpin("flags")
+	cache.Set("new", 0, 0)
+	cache.Set("newer", 0, 0)
+	assert.Equal(t, 3, cache.Stats().Entries)
+	has, _ := cache.Has("limits")
+	assert.True(t, has)
+}
+
+func TestSetWithPriority(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	cache := NewCache(WithMaxEntries[int](evictionSamples), WithClock[int](clock))
+	assert.True(t, cache.SetWithPriority("expensive", 0, 0, PriorityHigh))
+	assert.True(t, cache.SetWithPriority("expensive", 1, 0, PriorityHigh))
+	for i := 0; i < evictionSamples-3; i++ {
+		cache.Set(strconv.Itoa(i), i, 0)
+	}
+	clock.Advance(time.Second)
+	cache.SetWithPriority("cheap1", 0, 0, PriorityLow)
+	cache.SetWithPriority("cheap2", 0, 0, PriorityLow)
+
+	// low priority entries are evicted first, even though they are the most recent ones
+	cache.Set("new1", 0, 0)
+	cache.Set("new2", 0, 0)
+	for _, key := range []string{"cheap1", "cheap2"} {
+		has, _ := cache.Has(key)
+		assert.False(t, has, key)
+	}
+	// then normal ones, high priority entry is evicted last
+	for i := 0; i < evictionSamples; i++ {
+		clock.Advance(time.Second)
+		cache.Set("more"+strconv.Itoa(i), 0, 0)
+	}
+	has, _ := cache.Has("expensive")
+	assert.True(t, has)
+
+	// without WithMaxEntries it's Set
+	plain := NewCache[int]()
+	assert.True(t, plain.SetWithPriority("key", 1, 0, PriorityLow))
+}
+
+func TestSetWithPriorityRareLowEntry(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	cache := NewCache(WithMaxEntries[int](1000), WithClock[int](clock))
+	cache.SetWithPriority("cheap", 0, 0, PriorityLow)
+	clock.Advance(time.Second)
+	for i := 0; i < 999; i++ {
+		cache.Set(strconv.Itoa(i), i, 0)
+	}
+
+	// the only low priority entry is evicted, although it's unlikely to be sampled among normal ones
+	cache.Set("new", 0, 0)
+	has, _ := cache.Has("cheap")
+	assert.False(t, has)
+	assert.Equal(t, 1000, cache.Stats().Entries)
+	assert.Empty(t, cache.lowPriority)
+
+	// keys replaced with Set, deleted or cleared leave the pool
+	cache = NewCache(WithMaxEntries[int](10), WithClock[int](clock))
+	cache.SetWithPriority("a", 0, 0, PriorityLow)
+	cache.SetWithPriority("b", 0, 0, PriorityLow)
+	cache.SetWithPriority("c", 0, time.Millisecond, PriorityLow)
+	cache.Set("a", 1, 0)
+	require.NoError(t, cache.Del("b"))
+	clock.Advance(time.Second)
+	cache.Cleanup()
+	assert.Empty(t, cache.lowPriority)
+	cache.SetWithPriority("d", 0, 0, PriorityLow)
+	require.NoError(t, cache.Clear())
+	assert.Empty(t, cache.lowPriority)
+}
+
+func TestWithFullPolicyReject(t *testing.T) {
+	cache := NewCache(WithMaxEntries[int](3), WithFullPolicy[int](Reject))
+	require.NoError(t, cache.TrySet("a", 1, 0))
+	require.NoError(t, cache.TrySet("b", 2, 0))
+	require.NoError(t, cache.TrySet("expired", 3, time.Nanosecond))
+	time.Sleep(time.Millisecond)
+
+	require.NoError(t, cache.TrySet("a", 1, 0), "existing key is replaced")
+	assert.ErrorIs(t, cache.Add("a", 1, 0), ErrKeyExists)
+	require.NoError(t, cache.TrySet("c", 3, 0), "expired entry makes room")
+	assert.ErrorIs(t, cache.TrySet("d", 4, 0), ErrCacheFull)
+	assert.False(t, cache.Set("d", 4, 0))
+	assert.False(t, cache.SetBytes([]byte("d"), 4, 0))
+	assert.False(t, cache.SetNegative("d", time.Second))
+	assert.Equal(t, 3, cache.Stats().Entries)
+
+	require.NoError(t, cache.Del("a"))
+	require.NoError(t, cache.TrySet("d", 4, 0))
+
+	require.NoError(t, cache.Close())
+	assert.ErrorIs(t, cache.TrySet("e", 5, 0), ErrClosed)
+}
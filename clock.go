@@ -0,0 +1,85 @@
+package mcache
+
+import "time"
+
+// Clock is a source of the current time for expiration and entry metadata,
+// set WithClock to control time in tests.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc is an adapter to use a function as a Clock
+type ClockFunc func() time.Time
+
+// Now returns f()
+func (f ClockFunc) Now() time.Time { return f() }
+
+// systemClock is the default Clock, reading time.Now
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// WithClock is a functional option for setting the source of the current time,
+// used instead of time.Now for expiration and entry metadata. Durations of cleanup runs
+// and intervals of background goroutines are still measured with real time.
+func WithClock[T any](clock Clock) func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.clock = clock
+	}
+}
+
+// WithNowFunc is WithClock for a function returning the current time
+func WithNowFunc[T any](now func() time.Time) func(*Cache[T]) {
+	return WithClock[T](ClockFunc(now))
+}
+
+// expired checks if the item is expired by the cache clock, the clock isn't read if item doesn't expire
+func (c *Cache[T]) expired(item CacheItem[T]) bool {
+	return item.expiration != 0 && item.expiredAt(c.now())
+}
+
+// now returns the time passed since the cache epoch. With the system clock it's measured
+// with the monotonic clock, so wall clock steps don't move expiration of entries.
+func (c *Cache[T]) now() int64 {
+	if c.coarse {
+		return c.coarseNow.Load()
+	}
+	return int64(c.clock.Now().Sub(c.epoch))
+}
+
+// WithCoarseTime is a functional option for reading the current time from a value updated
+// by a background goroutine every resolution, instead of reading the clock in every operation.
+// Expiration is checked with up to resolution delay, 10ms is plenty for most TTLs.
+// The goroutine is stopped by Close. Resolution <= 0 leaves the clock read in every operation.
+func WithCoarseTime[T any](resolution time.Duration) func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		if resolution <= 0 {
+			return
+		}
+		c.coarse = true
+		c.addTask(resolution, func() {
+			c.coarseNow.Store(int64(c.clock.Now().Sub(c.epoch)))
+		})
+	}
+}
+
+// deadline converts the wall clock time to the time since the cache epoch, never 0 (no expiration)
+func (c *Cache[T]) deadline(t time.Time) int64 {
+	if d := int64(t.Round(0).Sub(c.epoch.Round(0))); d != 0 {
+		return d
+	}
+	return 1
+}
+
+// wallTime converts the time since the cache epoch to the wall clock time
+func (c *Cache[T]) wallTime(d int64) time.Time {
+	return c.epoch.Round(0).Add(time.Duration(d))
+}
+
+// expirationTime returns item expiration as the wall clock time, zero if item doesn't expire
+func (c *Cache[T]) expirationTime(item CacheItem[T]) time.Time {
+	if item.expiration == 0 {
+		return time.Time{}
+	}
+	return c.wallTime(item.expiration)
+}
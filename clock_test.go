@@ -0,0 +1,107 @@
+package mcache
+
+import (
+	"bytes"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache/clocktest"
+)
+
+func TestWithClock(t *testing.T) {
+	var now atomic.Int64
+	now.Store(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano())
+	clock := func() time.Time { return time.Unix(0, now.Load()) }
+	advance := func(d time.Duration) { now.Add(int64(d)) }
+
+	for name, opt := range map[string]func(*Cache[string]){
+		"map":     func(*Cache[string]) {},
+		"heap":    WithExpirationHeap[string](),
+		"wheel":   WithTimingWheel[string](time.Second),
+		"cow":     WithCopyOnWrite[string](),
+		"syncMap": WithSyncMapBackend[string](),
+	} {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache(opt, WithNowFunc[string](clock), WithEntryStats[string]())
+			defer cache.Close()
+			cache.Set("minute", "value", time.Minute)
+			cache.Set("hour", "value", time.Hour)
+
+			info, err := cache.EntryInfo("minute")
+			require.NoError(t, err)
+			assert.Equal(t, clock(), info.Created)
+			assert.Equal(t, clock().Add(time.Minute), info.Expiration)
+
+			advance(time.Minute + time.Nanosecond)
+			_, err = cache.Get("minute")
+			assert.ErrorIs(t, err, ErrExpired)
+			_, err = cache.Get("hour")
+			assert.NoError(t, err)
+			info, err = cache.EntryInfo("hour")
+			require.NoError(t, err)
+			assert.Equal(t, clock(), info.LastAccess)
+
+			advance(time.Hour)
+			removed, _ := cache.CleanupN()
+			assert.Equal(t, 1, removed)
+			assert.Equal(t, 0, cache.Stats().Entries)
+		})
+	}
+}
+
+func TestMonotonicExpiration(t *testing.T) {
+	cache := NewCache[string]()
+	cache.Set("key", "value", time.Hour)
+	assert.Greater(t, cache.data["key"].expiration, int64(0))
+	assert.LessOrEqual(t, cache.data["key"].expiration, int64(time.Hour)+int64(time.Since(cache.epoch)))
+
+	// exported expiration is a wall clock time, without a monotonic reading
+	info, err := cache.EntryInfo("key")
+	require.NoError(t, err)
+	assert.Equal(t, info.Expiration.Round(0), info.Expiration)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), info.Expiration, time.Second)
+
+	// and it's converted to the epoch of the loading cache
+	buf := bytes.Buffer{}
+	require.NoError(t, cache.SaveTo(&buf))
+	epoch := time.Now().Add(-24 * time.Hour)
+	loaded := NewCache(WithNowFunc[string](func() time.Time { return epoch }))
+	require.NoError(t, loaded.LoadFrom(&buf))
+	assert.InDelta(t, int64(25*time.Hour), loaded.data["key"].expiration, float64(time.Second))
+	loadedInfo, err := loaded.EntryInfo("key")
+	require.NoError(t, err)
+	assert.True(t, info.Expiration.Equal(loadedInfo.Expiration))
+
+	// expiration before the epoch is never mistaken for no expiration
+	assert.Equal(t, int64(1), loaded.deadline(epoch))
+	assert.Equal(t, int64(-1), loaded.deadline(epoch.Add(-1)))
+}
+
+func TestWithCoarseTime(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	cache := NewCache(WithClock[string](clock), WithCoarseTime[string](time.Millisecond))
+	defer cache.Close()
+	cache.Set("key", "value", time.Minute)
+
+	// time is updated in background
+	cache.Lock()
+	clock.Advance(2 * time.Minute)
+	_, err := cache.lookupLocked("key")
+	cache.Unlock()
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		_, err := cache.Get("key")
+		return errors.Is(err, ErrExpired)
+	}, time.Second, time.Millisecond)
+
+	// ttl counts from the coarse time
+	cache.Set("key", "value", time.Minute)
+	info, err := cache.EntryInfo("key")
+	require.NoError(t, err)
+	assert.True(t, clock.Now().Add(time.Minute).Equal(info.Expiration))
+}
@@ -0,0 +1,59 @@
+// Package clocktest provides a fake clock for deterministic, sleep-free tests of code using mcache.
+// Set it on the cache WithClock and move time forward with Advance instead of sleeping:
+//
+//	clock := clocktest.New(time.Now())
+//	cache := mcache.NewCache(mcache.WithClock[string](clock))
+//	cache.Set("key", "value", time.Minute)
+//	clock.Advance(time.Minute + 1) // "key" is expired now
+package clocktest
+
+import (
+	"sync"
+	"time"
+)
+
+// Cleaner is a cache that can delete its expired entries, like mcache.Cache
+type Cleaner interface {
+	Cleanup()
+}
+
+// Clock is a fake clock, it only moves when told to. Implements mcache.Clock, safe for concurrent use.
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// New is a constructor for Clock showing now
+func New(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now returns the current time of the clock
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Set sets the clock to now, it may move the clock backwards
+func (c *Clock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// AdvanceAndCleanup moves the clock forward by d and runs Cleanup of all caches synchronously,
+// so expired entries are deleted when it returns, without waiting for background cleanup
+func (c *Clock) AdvanceAndCleanup(d time.Duration, caches ...Cleaner) {
+	c.Advance(d)
+	for _, cache := range caches {
+		cache.Cleanup()
+	}
+}
@@ -0,0 +1,39 @@
+package clocktest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := New(start)
+	assert.Equal(t, start, clock.Now())
+	clock.Advance(time.Minute)
+	assert.Equal(t, start.Add(time.Minute), clock.Now())
+	clock.Set(start)
+	assert.Equal(t, start, clock.Now())
+}
+
+func TestAdvanceAndCleanup(t *testing.T) {
+	clock := New(time.Now())
+	cache := mcache.NewCache(mcache.WithClock[string](clock))
+	other := mcache.NewStripedCache(4, mcache.WithClock[string](clock))
+	cache.Set("key", "value", time.Minute)
+	other.Set("key", "value", time.Minute)
+
+	clock.AdvanceAndCleanup(time.Minute, cache, other)
+	assert.Equal(t, 1, cache.Stats().Entries)
+	assert.Equal(t, 1, other.Stats().Entries)
+
+	clock.AdvanceAndCleanup(time.Nanosecond, cache, other)
+	assert.Equal(t, 0, cache.Stats().Entries)
+	assert.Equal(t, 0, other.Stats().Entries)
+	_, err := cache.Get("key")
+	require.ErrorIs(t, err, mcache.ErrKeyNotFound)
+}
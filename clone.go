@@ -0,0 +1,41 @@
+package mcache
+
+import "time"
+
+// WithValueCopier is a functional option for copying values by Clone, like maps, slices
+// or pointers to structs, so the clone doesn't share them with the cache. The clone copies values
+// with the same function.
+func WithValueCopier[T any](copy func(T) T) func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.copier = copy
+	}
+}
+
+// Clone returns an independent cache with live entries of the cache and their remaining TTLs,
+// for test fixtures or scratch caches forked from a seeded one. Values are copied WithValueCopier,
+// otherwise they are copied by assignment. The clone is created with the clock and the value copier
+// of the cache and options, it doesn't inherit other options, entry metadata and pinned keys.
+// Entries spilled WithOverflow are not cloned.
+func (c *Cache[T]) Clone(options ...func(*Cache[T])) *Cache[T] {
+	s := c.snapshot()
+	clone := NewCache(append([]func(*Cache[T]){WithClock[T](c.clock), WithValueCopier[T](c.copier)}, options...)...)
+
+	clone.Lock()
+	defer clone.Unlock()
+	for k, v := range s.data {
+		if v.expiredAt(s.at) {
+			continue
+		}
+		var ttl time.Duration
+		if v.expiration != 0 {
+			ttl = time.Duration(v.expiration - s.at)
+		}
+		if c.copier != nil {
+			v.value = c.copier(v.value)
+		}
+		item := clone.ttlItem(v.value, ttl, v.negative)
+		clone.store(k, item)
+		clone.logSet(k, item)
+	}
+	return clone
+}
@@ -0,0 +1,49 @@
+package mcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache/clocktest"
+)
+
+func TestClone(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	cache := NewCache(WithClock[[]int](clock), WithValueCopier[[]int](func(v []int) []int {
+		return append([]int(nil), v...)
+	}))
+	require.True(t, cache.Set("forever", []int{1}, 0))
+	require.True(t, cache.Set("minute", []int{2}, time.Minute))
+	require.True(t, cache.Set("expired", []int{3}, time.Second))
+	clock.Advance(2 * time.Second)
+
+	clone := cache.Clone()
+	assert.Equal(t, 2, clone.Stats().Entries)
+	v, err := clone.Get("forever")
+	require.NoError(t, err)
+	v[0] = 100
+	v, err = cache.Get("forever")
+	require.NoError(t, err)
+	assert.Equal(t, []int{1}, v, "values are copied")
+
+	require.NoError(t, clone.Del("forever"))
+	ok, err := cache.Has("forever")
+	assert.NoError(t, err)
+	assert.True(t, ok, "clone is independent")
+
+	info, err := clone.EntryInfo("minute")
+	require.NoError(t, err)
+	assert.True(t, clock.Now().Add(58*time.Second).Equal(info.Expiration), "remaining TTL is kept")
+	clock.Advance(time.Minute)
+	_, err = clone.Get("minute")
+	assert.ErrorIs(t, err, ErrExpired)
+
+	clone = cache.Clone(WithNoExpiration[[]int]())
+	_, err = clone.Get("minute")
+	assert.ErrorIs(t, err, ErrKeyNotFound, "expired entries are not cloned")
+	_, err = clone.Get("forever")
+	assert.NoError(t, err)
+}
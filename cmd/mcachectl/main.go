@@ -0,0 +1,273 @@
+// Command mcachectl is a client of the HTTP admin API of a cache, served by cacheapi.Handler,
+// so operators can inspect and change the cache of a running service:
+//
+//	mcachectl [flags] keys            list keys
+//	mcachectl [flags] get KEY         print the value as JSON
+//	mcachectl [flags] set KEY VALUE [TTL]
+//	                                  set the value, JSON or a plain string, TTL like 1h
+//	mcachectl [flags] del KEY         delete the key
+//	mcachectl [flags] stats           print statistics
+//	mcachectl [flags] cleanup         delete expired entries
+//	mcachectl [flags] dump FILE       save entries to the file, - for stdout
+//	mcachectl [flags] restore FILE    load entries from the file, - for stdin
+//
+// Flags:
+//
+//	-addr     URL the handler is mounted at, $MCACHE_ADDR or http://localhost:8080 by default
+//	-auth     Authorization header value, $MCACHE_AUTH by default
+//	-timeout  request timeout, 30s by default; dump and restore are not limited by it as a whole,
+//	          only waiting for the response is, so large snapshots are not cut off
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "mcachectl:", err)
+		os.Exit(1)
+	}
+}
+
+// errUsage is returned for invalid command lines
+var errUsage = errors.New("usage: mcachectl [-addr URL] [-auth VALUE] [-timeout DURATION] " +
+	"keys | get KEY | set KEY VALUE [TTL] | del KEY | stats | cleanup | dump FILE | restore FILE")
+
+// run executes the command line args, reading snapshots to restore from stdin and writing output to stdout
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("mcachectl", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	addr := fs.String("addr", env("MCACHE_ADDR", "http://localhost:8080"), "URL the handler is mounted at")
+	auth := fs.String("auth", os.Getenv("MCACHE_AUTH"), "Authorization header value")
+	timeout := fs.Duration("timeout", 30*time.Second, "request timeout")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w\n%v", err, errUsage)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = *timeout
+	c := &client{
+		addr:      strings.TrimSuffix(*addr, "/"),
+		auth:      *auth,
+		http:      &http.Client{Timeout: *timeout},
+		snapshots: &http.Client{Transport: transport},
+	}
+
+	args = fs.Args()
+	if len(args) == 0 {
+		return errUsage
+	}
+	cmd, args := args[0], args[1:]
+	switch {
+	case cmd == "keys" && len(args) == 0:
+		return c.keys(stdout)
+	case cmd == "get" && len(args) == 1:
+		return c.get(stdout, args[0])
+	case cmd == "set" && (len(args) == 2 || len(args) == 3):
+		ttl := ""
+		if len(args) == 3 {
+			ttl = args[2]
+		}
+		return c.set(args[0], args[1], ttl)
+	case cmd == "del" && len(args) == 1:
+		return c.do(http.MethodDelete, "/keys/"+url.PathEscape(args[0]), nil, nil)
+	case cmd == "stats" && len(args) == 0:
+		return c.stats(stdout)
+	case cmd == "cleanup" && len(args) == 0:
+		return c.do(http.MethodPost, "/cleanup", nil, nil)
+	case cmd == "dump" && len(args) == 1:
+		return c.dump(stdout, args[0])
+	case cmd == "restore" && len(args) == 1:
+		return c.restore(stdin, args[0])
+	default:
+		return errUsage
+	}
+}
+
+// env returns the value of the environment variable, or def if it's not set
+func env(name, def string) string {
+	if v, ok := os.LookupEnv(name); ok {
+		return v
+	}
+	return def
+}
+
+// client sends requests to the admin API
+type client struct {
+	addr      string
+	auth      string
+	http      *http.Client
+	snapshots *http.Client // streams snapshots, without the timeout of the whole request
+}
+
+// do sends the request and copies the response body to out, if it's not nil.
+// Responses with error statuses are returned as errors with the error message of the API.
+func (c *client) do(method, path string, body io.Reader, out io.Writer) error {
+	return c.send(c.http, method, path, body, out)
+}
+
+// stream is do for snapshots, which may take longer than the timeout
+func (c *client) stream(method, path string, body io.Reader, out io.Writer) error {
+	return c.send(c.snapshots, method, path, body, out)
+}
+
+// send sends the request with the client, see do
+func (c *client) send(hc *http.Client, method, path string, body io.Reader, out io.Writer) error {
+	req, err := http.NewRequest(method, c.addr+path, body)
+	if err != nil {
+		return err
+	}
+	if c.auth != "" {
+		req.Header.Set("Authorization", c.auth)
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var e struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&e) != nil || e.Error == "" {
+			e.Error = http.StatusText(resp.StatusCode)
+		}
+		return fmt.Errorf("%s %s: %d %s", method, path, resp.StatusCode, e.Error)
+	}
+	if out == nil {
+		return nil
+	}
+	if _, err = io.Copy(out, resp.Body); err != nil {
+		return fmt.Errorf("%s %s: failed to read response: %w", method, path, err)
+	}
+	return nil
+}
+
+// getJSON sends GET request and decodes the JSON response into v
+func (c *client) getJSON(path string, v any) error {
+	var buf bytes.Buffer
+	if err := c.do(http.MethodGet, path, nil, &buf); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(buf.Bytes(), v); err != nil {
+		return fmt.Errorf("GET %s: failed to decode response: %w", path, err)
+	}
+	return nil
+}
+
+// keys prints all keys, one per line, requesting them page by page
+func (c *client) keys(stdout io.Writer) error {
+	cursor := ""
+	for {
+		var page struct {
+			Keys []string `json:"keys"`
+			Next string   `json:"next"`
+		}
+		if err := c.getJSON("/keys?cursor="+url.QueryEscape(cursor), &page); err != nil {
+			return err
+		}
+		for _, k := range page.Keys {
+			fmt.Fprintln(stdout, k)
+		}
+		if page.Next == "" {
+			return nil
+		}
+		cursor = page.Next
+	}
+}
+
+// get prints the value of the key as JSON
+func (c *client) get(stdout io.Writer, key string) error {
+	var e struct {
+		Value json.RawMessage `json:"value"`
+	}
+	if err := c.getJSON("/keys/"+url.PathEscape(key), &e); err != nil {
+		return err
+	}
+	return printJSON(stdout, e.Value)
+}
+
+// set sets the value of the key, value is sent as is if it's valid JSON, otherwise as a JSON string
+func (c *client) set(key, value, ttl string) error {
+	v := json.RawMessage(value)
+	if !json.Valid(v) {
+		v, _ = json.Marshal(value)
+	}
+	body, err := json.Marshal(struct {
+		Value json.RawMessage `json:"value"`
+		TTL   string          `json:"ttl,omitempty"`
+	}{v, ttl})
+	if err != nil {
+		return err
+	}
+	return c.do(http.MethodPut, "/keys/"+url.PathEscape(key), bytes.NewReader(body), nil)
+}
+
+// stats prints statistics of the cache
+func (c *client) stats(stdout io.Writer) error {
+	var stats json.RawMessage
+	if err := c.getJSON("/stats", &stats); err != nil {
+		return err
+	}
+	return printJSON(stdout, stats)
+}
+
+// dump saves the snapshot of the cache to the file, "-" for stdout.
+// The file is not created if the request fails.
+func (c *client) dump(stdout io.Writer, path string) error {
+	if path == "-" {
+		return c.stream(http.MethodGet, "/snapshot", nil, stdout)
+	}
+	// the snapshot is streamed to a temporary file, renamed when it's complete,
+	// so a failed dump never leaves a truncated file in place of the previous one
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // no-op after the rename
+	if err = c.stream(http.MethodGet, "/snapshot", nil, f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// restore loads the snapshot from the file, "-" for stdin, into the cache
+func (c *client) restore(stdin io.Reader, path string) error {
+	r := stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	return c.stream(http.MethodPut, "/snapshot", r, nil)
+}
+
+// printJSON prints the JSON value indented
+func printJSON(w io.Writer, v json.RawMessage) error {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, v, "", "  "); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+	_, err := w.Write(buf.Bytes())
+	return err
+}
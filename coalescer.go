@@ -0,0 +1,132 @@
+package mcache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNoBulkLoader is returned by Coalescer.GetBatched of a Coalescer created without WithBatchWindow
+var ErrNoBulkLoader = errors.New("no bulk loader")
+
+// Coalescer wraps any Cacher, local caches or clients of remote ones, merging concurrent loads
+// of the same missing key into a single loader call, independently of LoadingCache.
+// WithBatchWindow misses of different keys arriving within a short window are loaded
+// with a single call of the bulk loader. All methods of the Cacher are available as is.
+type Coalescer[T any] struct {
+	Cacher[T]
+	loads  flightGroup[T]
+	window time.Duration
+	bulk   BulkLoader[T]
+	ttl    time.Duration
+	mu     sync.Mutex
+	batch  *loadBatch[T] // batch collecting keys during the current window
+}
+
+// loadBatch is a set of keys loaded with a single call of the bulk loader
+type loadBatch[T any] struct {
+	keys   []string
+	values map[string]T
+	err    error
+	done   chan struct{}
+}
+
+// NewCoalescer is a constructor for Coalescer wrapping the cache
+func NewCoalescer[T any](cache Cacher[T], options ...func(*Coalescer[T])) *Coalescer[T] {
+	c := &Coalescer[T]{Cacher: cache}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// WithBatchWindow is an option of Coalescer for GetBatched: keys missed within the window
+// after the first miss are loaded together with a single call of bulk, values are set with ttl.
+// Each miss waits up to the window for the batch to fill.
+func WithBatchWindow[T any](window time.Duration, bulk BulkLoader[T], ttl time.Duration) func(*Coalescer[T]) {
+	return func(c *Coalescer[T]) {
+		c.window, c.bulk, c.ttl = window, bulk, ttl
+	}
+}
+
+// GetOrLoad returns the value of the key, loading it with loader on a miss and setting it with the returned ttl.
+// Concurrent calls of the same missing key share a single loader call, made with ctx of the first of them.
+// Loader error is returned as is, and nothing is set.
+func (c *Coalescer[T]) GetOrLoad(ctx context.Context, key string, loader Loader[T]) (T, error) {
+	v, err := c.Get(key)
+	if !missed(err) {
+		return v, err
+	}
+	return c.loads.do(key, func() (T, error) {
+		// the key may have been loaded by a call which has just finished
+		if v, err := c.Get(key); !missed(err) {
+			return v, err
+		}
+		v, ttl, err := loader(ctx, key)
+		if err != nil {
+			return v, err
+		}
+		c.Set(key, v, ttl)
+		return v, nil
+	})
+}
+
+// GetBatched returns the value of the key, loading it with the bulk loader set WithBatchWindow on a miss,
+// together with other keys missed within the window. Keys missing in the result of the bulk loader
+// return ErrKeyNotFound. Bulk loader is called with ctx of the first call of the batch.
+func (c *Coalescer[T]) GetBatched(ctx context.Context, key string) (T, error) {
+	var none T
+	if c.bulk == nil {
+		return none, ErrNoBulkLoader
+	}
+	v, err := c.Get(key)
+	if !missed(err) {
+		return v, err
+	}
+	return c.loads.do(key, func() (T, error) {
+		b := c.enqueue(ctx, key)
+		<-b.done
+		if b.err != nil {
+			return none, b.err
+		}
+		v, ok := b.values[key]
+		if !ok {
+			return none, ErrKeyNotFound
+		}
+		return v, nil
+	})
+}
+
+// enqueue adds the key to the current batch, starting a new one if there is none
+func (c *Coalescer[T]) enqueue(ctx context.Context, key string) *loadBatch[T] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.batch == nil {
+		b := &loadBatch[T]{done: make(chan struct{})}
+		c.batch = b
+		time.AfterFunc(c.window, func() { c.flush(ctx, b) })
+	}
+	c.batch.keys = append(c.batch.keys, key)
+	return c.batch
+}
+
+// flush loads keys of the batch with the bulk loader and sets the loaded values
+func (c *Coalescer[T]) flush(ctx context.Context, b *loadBatch[T]) {
+	c.mu.Lock()
+	c.batch = nil
+	c.mu.Unlock()
+
+	defer close(b.done)
+	if b.values, b.err = c.bulk(ctx, b.keys); b.err != nil {
+		return
+	}
+	for key, v := range b.values {
+		c.Set(key, v, c.ttl)
+	}
+}
+
+// missed checks if the error of Get is a miss to load, closed caches and negative entries are not loaded
+func missed(err error) bool {
+	return err != nil && !errors.Is(err, ErrClosed) && !errors.Is(err, ErrNegativeCached)
+}
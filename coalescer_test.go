@@ -0,0 +1,105 @@
+package mcache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoalescerGetOrLoad(t *testing.T) {
+	cache := NewStripedCache[string](4)
+	c := NewCoalescer[string](cache)
+	var _ Cacher[string] = c
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	loader := func(_ context.Context, key string) (string, time.Duration, error) {
+		calls.Add(1)
+		<-release
+		if key == "bad" {
+			return "", 0, errors.New("failed")
+		}
+		return "value of " + key, time.Hour, nil
+	}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := c.GetOrLoad(context.Background(), "key", loader)
+			assert.NoError(t, err)
+			assert.Equal(t, "value of key", v)
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), calls.Load())
+
+	v, err := cache.Get("key")
+	require.NoError(t, err)
+	assert.Equal(t, "value of key", v)
+	_, err = c.GetOrLoad(context.Background(), "key", loader)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), calls.Load(), "hit doesn't load")
+
+	_, err = c.GetOrLoad(context.Background(), "bad", loader)
+	assert.EqualError(t, err, "failed")
+	has, _ := c.Has("bad")
+	assert.False(t, has)
+}
+
+func TestCoalescerGetBatched(t *testing.T) {
+	var batches [][]string
+	var mu sync.Mutex
+	bulk := func(_ context.Context, keys []string) (map[string]int, error) {
+		mu.Lock()
+		batches = append(batches, keys)
+		mu.Unlock()
+		values := map[string]int{}
+		for _, key := range keys {
+			if key != "missing" {
+				values[key] = len(key)
+			}
+		}
+		return values, nil
+	}
+	cache := NewCache[int]()
+	c := NewCoalescer[int](cache, WithBatchWindow(50*time.Millisecond, bulk, time.Hour))
+
+	wg := sync.WaitGroup{}
+	for _, key := range []string{"a", "bb", "bb", "ccc", "missing"} {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			v, err := c.GetBatched(context.Background(), key)
+			if key == "missing" {
+				assert.ErrorIs(t, err, ErrKeyNotFound)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, len(key), v)
+		}(key)
+	}
+	wg.Wait()
+	require.Len(t, batches, 1)
+	assert.ElementsMatch(t, []string{"a", "bb", "ccc", "missing"}, batches[0])
+
+	v, err := cache.Get("ccc")
+	require.NoError(t, err)
+	assert.Equal(t, 3, v)
+	v, err = c.GetBatched(context.Background(), "ccc")
+	require.NoError(t, err)
+	assert.Equal(t, 3, v)
+	assert.Len(t, batches, 1, "hit doesn't load")
+
+	_, err = NewCoalescer[int](cache).GetBatched(context.Background(), "key")
+	assert.ErrorIs(t, err, ErrNoBulkLoader)
+}
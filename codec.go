@@ -0,0 +1,70 @@
+package mcache
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// Entry is a serialized form of a cache entry, written and read by codecs.
+type Entry[T any] struct {
+	Key        string    `json:"key"`
+	Value      T         `json:"value"`
+	Expiration time.Time `json:"expiration"` // zero if entry doesn't expire
+}
+
+// Codec serializes entries for persistence. Encoder writes a stream of entries,
+// Decoder reads them back one by one and returns io.EOF at the end of the stream.
+type Codec[T any] interface {
+	NewEncoder(w io.Writer) Encoder[T]
+	NewDecoder(r io.Reader) Decoder[T]
+}
+
+// Encoder writes entries to a stream.
+type Encoder[T any] interface {
+	Encode(e *Entry[T]) error
+}
+
+// Decoder reads entries from a stream.
+type Decoder[T any] interface {
+	Decode(e *Entry[T]) error
+}
+
+// GobCodec is a Codec using encoding/gob, used by default.
+// Interface values must be registered with gob.Register.
+type GobCodec[T any] struct{}
+
+type gobEncoder[T any] struct{ enc *gob.Encoder }
+type gobDecoder[T any] struct{ dec *gob.Decoder }
+
+// NewEncoder implements Codec.
+func (GobCodec[T]) NewEncoder(w io.Writer) Encoder[T] { return gobEncoder[T]{gob.NewEncoder(w)} }
+
+// NewDecoder implements Codec.
+func (GobCodec[T]) NewDecoder(r io.Reader) Decoder[T] { return gobDecoder[T]{gob.NewDecoder(r)} }
+
+func (g gobEncoder[T]) Encode(e *Entry[T]) error { return g.enc.Encode(e) }
+func (g gobDecoder[T]) Decode(e *Entry[T]) error { return g.dec.Decode(e) }
+
+// JSONCodec is a Codec using encoding/json, entries are written as a stream of JSON objects.
+type JSONCodec[T any] struct{}
+
+type jsonEncoder[T any] struct{ enc *json.Encoder }
+type jsonDecoder[T any] struct{ dec *json.Decoder }
+
+// NewEncoder implements Codec.
+func (JSONCodec[T]) NewEncoder(w io.Writer) Encoder[T] { return jsonEncoder[T]{json.NewEncoder(w)} }
+
+// NewDecoder implements Codec.
+func (JSONCodec[T]) NewDecoder(r io.Reader) Decoder[T] { return jsonDecoder[T]{json.NewDecoder(r)} }
+
+func (j jsonEncoder[T]) Encode(e *Entry[T]) error { return j.enc.Encode(e) }
+func (j jsonDecoder[T]) Decode(e *Entry[T]) error { return j.dec.Decode(e) }
+
+// WithCodec is a functional option for setting a Codec used by persistence, GobCodec by default.
+func WithCodec[T any](codec Codec[T]) func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.codec = codec
+	}
+}
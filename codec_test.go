@@ -0,0 +1,41 @@
+package mcache
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONCodec(t *testing.T) {
+	cache := NewCache(WithCodec[testValue](JSONCodec[testValue]{}))
+	cache.Set("key", testValue{Name: "name", Tags: []string{"tag"}}, 0)
+	cache.Set("hour", testValue{Name: "hour"}, time.Hour)
+
+	buf := bytes.Buffer{}
+	require.NoError(t, cache.SaveTo(&buf))
+	assert.Contains(t, buf.String(), `{"key":"key","value":{"Name":"name","Tags":["tag"]},"expiration":"0001-01-01T00:00:00Z"}`)
+	assert.Equal(t, 2, strings.Count(buf.String(), "\n"))
+
+	loaded := NewCache(WithCodec[testValue](JSONCodec[testValue]{}))
+	require.NoError(t, loaded.LoadFrom(&buf))
+	v, err := loaded.Get("key")
+	assert.NoError(t, err)
+	assert.Equal(t, testValue{Name: "name", Tags: []string{"tag"}}, v)
+	info, err := loaded.EntryInfo("hour")
+	assert.NoError(t, err)
+	assert.False(t, info.Expiration.IsZero())
+
+	// gob decoder can't read JSON snapshot
+	path := filepath.Join(t.TempDir(), "cache.json")
+	require.NoError(t, cache.Save(path))
+	_, err = NewCacheFromFile[testValue](path)
+	assert.Error(t, err)
+	loaded, err = NewCacheFromFile(path, WithCodec[testValue](JSONCodec[testValue]{}))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, loaded.Stats().Entries)
+}
@@ -0,0 +1,30 @@
+package mcache
+
+import (
+	"compress/gzip"
+	"io"
+)
+
+// Compression wraps snapshot streams written by SaveTo and read by LoadFrom.
+// zstd or any other algorithm can be plugged by implementing it, e.g. with github.com/klauspost/compress.
+type Compression interface {
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// Gzip is a Compression using compress/gzip with default compression level.
+var Gzip Compression = gzipCompression{}
+
+type gzipCompression struct{}
+
+func (gzipCompression) NewWriter(w io.Writer) (io.WriteCloser, error) { return gzip.NewWriter(w), nil }
+func (gzipCompression) NewReader(r io.Reader) (io.ReadCloser, error)  { return gzip.NewReader(r) }
+
+// WithSnapshotCompression is a functional option for compressing snapshots,
+// compression is streaming, so memory usage doesn't depend on the snapshot size.
+// WAL is not compressed.
+func WithSnapshotCompression[T any](compression Compression) func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.compression = compression
+	}
+}
@@ -0,0 +1,67 @@
+package mcache
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithSnapshotCompression(t *testing.T) {
+	cache := NewCache[string]()
+	compressed := NewCache(WithSnapshotCompression[string](Gzip))
+	for i := 0; i < 1000; i++ {
+		cache.Set("key_"+strconv.Itoa(i), strings.Repeat("value", 10), time.Hour)
+		compressed.Set("key_"+strconv.Itoa(i), strings.Repeat("value", 10), time.Hour)
+	}
+
+	plain, gz := bytes.Buffer{}, bytes.Buffer{}
+	require.NoError(t, cache.SaveTo(&plain))
+	require.NoError(t, compressed.SaveTo(&gz))
+	assert.Less(t, gz.Len(), plain.Len()/5)
+
+	loaded := NewCache(WithSnapshotCompression[string](Gzip))
+	require.NoError(t, loaded.LoadFrom(&gz))
+	assert.Equal(t, 1000, loaded.Stats().Entries)
+
+	// uncompressed stream can't be read
+	assert.Error(t, loaded.LoadFrom(&plain))
+
+	path := filepath.Join(t.TempDir(), "cache.gob.gz")
+	require.NoError(t, compressed.Save(path))
+	loaded, err := NewCacheFromFile(path, WithSnapshotCompression[string](Gzip))
+	require.NoError(t, err)
+	v, err := loaded.Get("key_999")
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Repeat("value", 10), v)
+}
+
+func TestWithSnapshotCompressionWriteError(t *testing.T) {
+	cache := NewCache(WithSnapshotCompression[string](Gzip))
+	cache.Set("key", "value", 0)
+	// gzip header is written right away, a small snapshot is buffered until the compression is finished
+	err := cache.SaveTo(&failingWriter{ok: 1})
+	assert.ErrorContains(t, err, "failed to finish compression")
+	assert.ErrorIs(t, err, errWriteFailed)
+}
+
+var errWriteFailed = errors.New("write failed")
+
+// failingWriter is a writer failing all writes but the first ok ones
+type failingWriter struct {
+	ok int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.ok == 0 {
+		return 0, errWriteFailed
+	}
+	w.ok--
+	return len(p), nil
+}
@@ -0,0 +1,71 @@
+package mcache
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrNotModified is returned by GetConditional and GetIfModifiedSince when the client's copy is current
+var ErrNotModified = errors.New("not modified")
+
+// Validated is a value cached with its HTTP validators, like a rendered response,
+// so 304 Not Modified responses are served from the cache, see GetConditional
+type Validated[T any] struct {
+	Value        T
+	ETag         string    // entity tag with quotes, like "v1" or W/"v1" for a weak one
+	LastModified time.Time // zero if unknown
+}
+
+// MatchesETag checks if the entity tag matches the If-None-Match header value: a list of entity tags
+// or "*". Tags are compared weakly, like for If-None-Match, so W/"v1" matches "v1".
+func (v Validated[T]) MatchesETag(ifNoneMatch string) bool {
+	if v.ETag == "" || ifNoneMatch == "" {
+		return false
+	}
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+	etag := strings.TrimPrefix(v.ETag, "W/")
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// GetConditional is Get of the validated value, ErrNotModified with the value if its entity tag matches
+// ifNoneMatch, the If-None-Match header of the request. Other errors are the same as for Get.
+//
+//	v, err := mcache.GetConditional(cache, key, r.Header.Get("If-None-Match"))
+//	if errors.Is(err, mcache.ErrNotModified) {
+//		w.Header().Set("ETag", v.ETag)
+//		w.WriteHeader(http.StatusNotModified)
+//		return
+//	}
+func GetConditional[T any](c *Cache[Validated[T]], key, ifNoneMatch string) (Validated[T], error) {
+	v, err := c.Get(key)
+	if err != nil {
+		return v, err
+	}
+	if v.MatchesETag(ifNoneMatch) {
+		return v, ErrNotModified
+	}
+	return v, nil
+}
+
+// GetIfModifiedSince is Get of the validated value, ErrNotModified with the value if it wasn't modified
+// after since, the parsed If-Modified-Since header of the request. Timestamps are compared with
+// the one second resolution of HTTP dates, zero since or LastModified never match.
+// If-None-Match takes precedence over If-Modified-Since, check GetConditional first if both are sent.
+func GetIfModifiedSince[T any](c *Cache[Validated[T]], key string, since time.Time) (Validated[T], error) {
+	v, err := c.Get(key)
+	if err != nil {
+		return v, err
+	}
+	if !since.IsZero() && !v.LastModified.IsZero() && !v.LastModified.Truncate(time.Second).After(since) {
+		return v, ErrNotModified
+	}
+	return v, nil
+}
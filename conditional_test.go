@@ -0,0 +1,45 @@
+package mcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetConditional(t *testing.T) {
+	modified := time.Date(2024, 1, 2, 3, 4, 5, 600, time.UTC)
+	cache := NewCache[Validated[string]]()
+	cache.Set("page", Validated[string]{Value: "<html>", ETag: `"v1"`, LastModified: modified}, 0)
+	cache.Set("weak", Validated[string]{Value: "<html>", ETag: `W/"v2"`}, 0)
+	cache.Set("plain", Validated[string]{Value: "<html>"}, 0)
+
+	v, err := GetConditional(cache, "page", "")
+	require.NoError(t, err)
+	assert.Equal(t, "<html>", v.Value)
+	for _, header := range []string{`"v1"`, `W/"v1"`, `"v0", "v1"`, `*`} {
+		v, err = GetConditional(cache, "page", header)
+		assert.ErrorIs(t, err, ErrNotModified, header)
+		assert.Equal(t, `"v1"`, v.ETag, header)
+	}
+	_, err = GetConditional(cache, "page", `"v2"`)
+	assert.NoError(t, err)
+	_, err = GetConditional(cache, "weak", `"v2"`)
+	assert.ErrorIs(t, err, ErrNotModified)
+	_, err = GetConditional(cache, "plain", `*`)
+	assert.NoError(t, err, "value without an entity tag never matches")
+	_, err = GetConditional(cache, "missing", `"v1"`)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	_, err = GetIfModifiedSince(cache, "page", modified.Truncate(time.Second))
+	assert.ErrorIs(t, err, ErrNotModified)
+	_, err = GetIfModifiedSince(cache, "page", modified.Add(time.Hour))
+	assert.ErrorIs(t, err, ErrNotModified)
+	_, err = GetIfModifiedSince(cache, "page", modified.Add(-time.Second))
+	assert.NoError(t, err)
+	_, err = GetIfModifiedSince(cache, "page", time.Time{})
+	assert.NoError(t, err)
+	_, err = GetIfModifiedSince(cache, "plain", modified)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,231 @@
+package mcache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidConfig is returned for a Config that can't be turned into a cache
+var ErrInvalidConfig = errors.New("invalid cache config")
+
+// Config is a declarative cache configuration, for caches driven by configuration files
+// or environment variables instead of functional options. Zero values are the defaults of NewCache.
+type Config struct {
+	Size                int        `json:"size"`                 // initial size, WithSize
+	MaxEntries          int        `json:"max_entries"`          // limit of entries, WithMaxEntries
+	Eviction            FullPolicy `json:"eviction"`             // "evict", "reject" or "evict_oldest", WithFullPolicy
+	Shards              int        `json:"shards"`               // number of stripes, NewStripedCacheFromConfig
+	NegativeTTL         Duration   `json:"negative_ttl"`         // WithNegativeTTL
+	ErrorTTL            Duration   `json:"error_ttl"`            // WithErrorTTL
+	CleanupInterval     Duration   `json:"cleanup_interval"`     // WithCleanup
+	PersistencePath     string     `json:"persistence_path"`     // WithPersistence
+	PersistenceInterval Duration   `json:"persistence_interval"` // WithPersistence, required with the path
+}
+
+// Duration is a time.Duration read from JSON and environment as a string like "1m30s",
+// JSON numbers are nanoseconds
+type Duration time.Duration
+
+// String returns the duration formatted like time.Duration
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting both strings and numbers
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var ns int64
+	if err := json.Unmarshal(data, &ns); err == nil {
+		*d = Duration(ns)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string or a number of nanoseconds: %w", err)
+	}
+	return d.UnmarshalText([]byte(s))
+}
+
+// String returns the policy name used in Config
+func (p FullPolicy) String() string {
+	switch p {
+	case Evict:
+		return "evict"
+	case Reject:
+		return "reject"
+	case EvictOldest:
+		return "evict_oldest"
+	}
+	return "FullPolicy(" + strconv.Itoa(int(p)) + ")"
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (p FullPolicy) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, empty text is Evict
+func (p *FullPolicy) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "", "evict":
+		*p = Evict
+	case "reject":
+		*p = Reject
+	case "evict_oldest":
+		*p = EvictOldest
+	default:
+		return fmt.Errorf("unknown full policy %q", text)
+	}
+	return nil
+}
+
+// ReadConfig reads Config from JSON, unknown fields are rejected to catch typos
+func ReadConfig(r io.Reader) (Config, error) {
+	var cfg Config
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return Config{}, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	return cfg, nil
+}
+
+// LoadConfigFile reads Config from the JSON file, see ReadConfig
+func LoadConfigFile(path string) (Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer f.Close()
+	return ReadConfig(f)
+}
+
+// ApplyEnv overrides fields with environment variables named by the JSON field name in upper case
+// after the prefix, like MCACHE_MAX_ENTRIES=1000 or MCACHE_CLEANUP_INTERVAL=1m for the "MCACHE_" prefix.
+// Unset and empty variables keep the fields as is.
+func (cfg *Config) ApplyEnv(prefix string) error {
+	ints := map[string]*int{
+		"SIZE":        &cfg.Size,
+		"MAX_ENTRIES": &cfg.MaxEntries,
+		"SHARDS":      &cfg.Shards,
+	}
+	durations := map[string]*Duration{
+		"NEGATIVE_TTL":         &cfg.NegativeTTL,
+		"ERROR_TTL":            &cfg.ErrorTTL,
+		"CLEANUP_INTERVAL":     &cfg.CleanupInterval,
+		"PERSISTENCE_INTERVAL": &cfg.PersistenceInterval,
+	}
+
+	for name, field := range ints {
+		if v := os.Getenv(prefix + name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("%w: %s%s: %w", ErrInvalidConfig, prefix, name, err)
+			}
+			*field = n
+		}
+	}
+	for name, field := range durations {
+		if v := os.Getenv(prefix + name); v != "" {
+			if err := field.UnmarshalText([]byte(v)); err != nil {
+				return fmt.Errorf("%w: %s%s: %w", ErrInvalidConfig, prefix, name, err)
+			}
+		}
+	}
+	if v := os.Getenv(prefix + "EVICTION"); v != "" {
+		if err := cfg.Eviction.UnmarshalText([]byte(v)); err != nil {
+			return fmt.Errorf("%w: %sEVICTION: %w", ErrInvalidConfig, prefix, err)
+		}
+	}
+	if v := os.Getenv(prefix + "PERSISTENCE_PATH"); v != "" {
+		cfg.PersistencePath = v
+	}
+	return nil
+}
+
+// Validate checks the config for negative numbers and inconsistent fields
+func (cfg Config) Validate() error {
+	switch {
+	case cfg.Size < 0, cfg.MaxEntries < 0, cfg.Shards < 0:
+		return fmt.Errorf("%w: size, max_entries and shards can't be negative", ErrInvalidConfig)
+	case cfg.NegativeTTL < 0, cfg.ErrorTTL < 0, cfg.CleanupInterval < 0, cfg.PersistenceInterval < 0:
+		return fmt.Errorf("%w: durations can't be negative", ErrInvalidConfig)
+	case cfg.Eviction < Evict || cfg.Eviction > EvictOldest:
+		return fmt.Errorf("%w: unknown eviction %s", ErrInvalidConfig, cfg.Eviction)
+	case cfg.PersistencePath != "" && cfg.PersistenceInterval == 0:
+		return fmt.Errorf("%w: persistence_interval is required with persistence_path", ErrInvalidConfig)
+	case cfg.PersistencePath != "" && cfg.Shards > 1:
+		return fmt.Errorf("%w: persistence can't be used with shards", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// ConfigOptions returns functional options equivalent to the config, except Shards
+func ConfigOptions[T any](cfg Config) ([]func(*Cache[T]), error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	var options []func(*Cache[T])
+	if cfg.Size > 0 {
+		options = append(options, WithSize[T](cfg.Size))
+	}
+	if cfg.MaxEntries > 0 {
+		options = append(options, WithMaxEntries[T](cfg.MaxEntries), WithFullPolicy[T](cfg.Eviction))
+	}
+	if cfg.NegativeTTL > 0 {
+		options = append(options, WithNegativeTTL[T](time.Duration(cfg.NegativeTTL)))
+	}
+	if cfg.ErrorTTL > 0 {
+		options = append(options, WithErrorTTL[T](time.Duration(cfg.ErrorTTL)))
+	}
+	if cfg.CleanupInterval > 0 {
+		options = append(options, WithCleanup[T](time.Duration(cfg.CleanupInterval)))
+	}
+	if cfg.PersistencePath != "" {
+		options = append(options, WithPersistence[T](cfg.PersistencePath, time.Duration(cfg.PersistenceInterval)))
+	}
+	return options, nil
+}
+
+// NewCacheFromConfig is a constructor for Cache configured by cfg, options are applied after the config.
+// Config with more than one shard is rejected, it's a config for NewStripedCacheFromConfig.
+func NewCacheFromConfig[T any](cfg Config, options ...func(*Cache[T])) (*Cache[T], error) {
+	if cfg.Shards > 1 {
+		return nil, fmt.Errorf("%w: %d shards, use NewStripedCacheFromConfig", ErrInvalidConfig, cfg.Shards)
+	}
+	opts, err := ConfigOptions[T](cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewCache(append(opts, options...)...), nil
+}
+
+// NewStripedCacheFromConfig is a constructor for StripedCache of cfg.Shards stripes configured by cfg,
+// see NewStripedCache. Size and MaxEntries are the limits of a single stripe.
+func NewStripedCacheFromConfig[T any](cfg Config, options ...func(*Cache[T])) (*StripedCache[T], error) {
+	opts, err := ConfigOptions[T](cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewStripedCache(cfg.Shards, append(opts, options...)...), nil
+}
@@ -0,0 +1,100 @@
+package mcache
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadConfig(t *testing.T) {
+	cfg, err := ReadConfig(strings.NewReader(`{
+		"size": 100,
+		"max_entries": 2,
+		"eviction": "reject",
+		"negative_ttl": "30s",
+		"error_ttl": 1000000000,
+		"cleanup_interval": "1m"
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, Config{
+		Size:            100,
+		MaxEntries:      2,
+		Eviction:        Reject,
+		NegativeTTL:     Duration(30 * time.Second),
+		ErrorTTL:        Duration(time.Second),
+		CleanupInterval: Duration(time.Minute),
+	}, cfg)
+
+	cache, err := NewCacheFromConfig[int](cfg)
+	require.NoError(t, err)
+	defer cache.Close()
+	assert.True(t, cache.Set("a", 1, 0))
+	assert.True(t, cache.Set("b", 2, 0))
+	assert.ErrorIs(t, cache.TrySet("c", 3, 0), ErrCacheFull)
+	assert.Equal(t, 30*time.Second, cache.negativeTTL)
+
+	_, err = ReadConfig(strings.NewReader(`{"max_entires": 10}`))
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+	_, err = ReadConfig(strings.NewReader(`{"eviction": "random"}`))
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+	_, err = ReadConfig(strings.NewReader(`{"cleanup_interval": "soon"}`))
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+}
+
+func TestConfigApplyEnv(t *testing.T) {
+	t.Setenv("TEST_MCACHE_MAX_ENTRIES", "10")
+	t.Setenv("TEST_MCACHE_SHARDS", "4")
+	t.Setenv("TEST_MCACHE_EVICTION", "Reject")
+	t.Setenv("TEST_MCACHE_CLEANUP_INTERVAL", "5m")
+
+	cfg := Config{Size: 50, MaxEntries: 1}
+	require.NoError(t, cfg.ApplyEnv("TEST_MCACHE_"))
+	assert.Equal(t, Config{
+		Size:            50,
+		MaxEntries:      10,
+		Shards:          4,
+		Eviction:        Reject,
+		CleanupInterval: Duration(5 * time.Minute),
+	}, cfg)
+
+	_, err := NewCacheFromConfig[int](cfg)
+	assert.ErrorIs(t, err, ErrInvalidConfig, "shards need a striped cache")
+	striped, err := NewStripedCacheFromConfig[int](cfg)
+	require.NoError(t, err)
+	defer striped.Close()
+	assert.Len(t, striped.stripes, 4)
+
+	t.Setenv("TEST_MCACHE_SIZE", "many")
+	assert.ErrorIs(t, cfg.ApplyEnv("TEST_MCACHE_"), ErrInvalidConfig)
+}
+
+func TestConfigValidate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	tbl := []struct {
+		name string
+		cfg  Config
+		ok   bool
+	}{
+		{"zero", Config{}, true},
+		{"negative size", Config{Size: -1}, false},
+		{"negative ttl", Config{NegativeTTL: -1}, false},
+		{"unknown eviction", Config{Eviction: 7}, false},
+		{"persistence", Config{PersistencePath: path, PersistenceInterval: Duration(time.Minute)}, true},
+		{"persistence without interval", Config{PersistencePath: path}, false},
+		{"persistence with shards", Config{PersistencePath: path, PersistenceInterval: 1, Shards: 2}, false},
+	}
+	for _, tt := range tbl {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.ok {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidConfig)
+		})
+	}
+}
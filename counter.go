@@ -0,0 +1,128 @@
+package mcache
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// counterSamples is the number of counters checked for expiration when a new counter is added
+const counterSamples = 5
+
+// CounterCache is a cache of int64 counters expiring a window after their first increment,
+// for pre-aggregating metrics. Counters are updated atomically under the read lock,
+// so increments of existing counters don't contend, and values are not boxed in CacheItems.
+type CounterCache struct {
+	window   time.Duration
+	clock    Clock
+	counters map[string]*windowCounter
+	sync.RWMutex
+}
+
+// windowCounter is a counter of a single window
+type windowCounter struct {
+	n          atomic.Int64
+	expiration int64 // unix nanoseconds
+}
+
+// NewCounterCache is a constructor for CounterCache with counters expiring in window
+func NewCounterCache(window time.Duration, options ...func(*CounterCache)) *CounterCache {
+	c := &CounterCache{window: window, clock: systemClock{}, counters: make(map[string]*windowCounter)}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// WithCounterClock is an option for setting the source of the current time of CounterCache
+func WithCounterClock(clock Clock) func(*CounterCache) {
+	return func(c *CounterCache) {
+		c.clock = clock
+	}
+}
+
+// Add adds n to the counter of the key and returns the new value. The counter of a missing key
+// or an expired one starts from zero with a new window.
+func (c *CounterCache) Add(key string, n int64) int64 {
+	now := c.clock.Now().UnixNano()
+	c.RLock()
+	counter, ok := c.counters[key]
+	if ok && now < counter.expiration {
+		v := counter.n.Add(n)
+		c.RUnlock()
+		return v
+	}
+	c.RUnlock()
+
+	c.Lock()
+	defer c.Unlock()
+	// a concurrent Add could have started the window already
+	counter, ok = c.counters[key]
+	if !ok || now >= counter.expiration {
+		if !ok {
+			c.expireSample(now)
+		}
+		counter = &windowCounter{expiration: now + int64(c.window)}
+		c.counters[key] = counter
+	}
+	return counter.n.Add(n)
+}
+
+// Get returns the value of the counter of the key, 0 if it's missing or expired
+func (c *CounterCache) Get(key string) int64 {
+	now := c.clock.Now().UnixNano()
+	c.RLock()
+	defer c.RUnlock()
+	if counter, ok := c.counters[key]; ok && now < counter.expiration {
+		return counter.n.Load()
+	}
+	return 0
+}
+
+// Counts returns values of live counters by keys
+func (c *CounterCache) Counts() map[string]int64 {
+	now := c.clock.Now().UnixNano()
+	c.RLock()
+	defer c.RUnlock()
+	counts := make(map[string]int64, len(c.counters))
+	for key, counter := range c.counters {
+		if now < counter.expiration {
+			counts[key] = counter.n.Load()
+		}
+	}
+	return counts
+}
+
+// Del deletes the counter of the key
+func (c *CounterCache) Del(key string) {
+	c.Lock()
+	defer c.Unlock()
+	delete(c.counters, key)
+}
+
+// Cleanup deletes expired counters. A few counters are checked on every new counter added,
+// so Cleanup is needed only to free memory of counters expired at once.
+func (c *CounterCache) Cleanup() {
+	now := c.clock.Now().UnixNano()
+	c.Lock()
+	defer c.Unlock()
+	for key, counter := range c.counters {
+		if now >= counter.expiration {
+			delete(c.counters, key)
+		}
+	}
+}
+
+// expireSample deletes expired counters among counterSamples checked, must be called under lock
+func (c *CounterCache) expireSample(now int64) {
+	n := 0
+	for key, counter := range c.counters {
+		if n == counterSamples {
+			return
+		}
+		n++
+		if now >= counter.expiration {
+			delete(c.counters, key)
+		}
+	}
+}
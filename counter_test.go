@@ -0,0 +1,72 @@
+package mcache
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/parMaster/mcache/clocktest"
+)
+
+func TestCounterCache(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	c := NewCounterCache(time.Minute, WithCounterClock(clock))
+
+	assert.Equal(t, int64(1), c.Add("requests", 1))
+	assert.Equal(t, int64(11), c.Add("requests", 10))
+	assert.Equal(t, int64(-1), c.Add("errors", -1))
+	assert.Equal(t, int64(11), c.Get("requests"))
+	assert.Equal(t, int64(0), c.Get("missing"))
+	assert.Equal(t, map[string]int64{"requests": 11, "errors": -1}, c.Counts())
+
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, int64(12), c.Add("requests", 1), "window starts with the first increment")
+	c.Del("errors")
+	assert.Equal(t, int64(0), c.Get("errors"))
+
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, int64(0), c.Get("requests"), "window expired")
+	assert.Empty(t, c.Counts())
+	assert.Equal(t, int64(5), c.Add("requests", 5), "new window")
+
+	c.Add("other", 1)
+	clock.Advance(time.Minute)
+	c.Cleanup()
+	assert.Empty(t, c.counters)
+}
+
+func TestCounterCacheSampledCleanup(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	c := NewCounterCache(time.Second, WithCounterClock(clock))
+	for i := 0; i < 100; i++ {
+		c.Add(strconv.Itoa(i), 1)
+	}
+	clock.Advance(time.Second)
+	for i := 0; i < 100; i++ {
+		c.Add("new"+strconv.Itoa(i), 1)
+	}
+	assert.Less(t, len(c.counters), 200, "expired counters are deleted when new ones are added")
+}
+
+func TestCounterCacheConcurrent(t *testing.T) {
+	c := NewCounterCache(time.Hour)
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.Add("total", 1)
+				c.Add(strconv.Itoa(i%10), 2)
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, int64(10000), c.Get("total"))
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, int64(2000), c.Get(strconv.Itoa(i)))
+	}
+}
@@ -0,0 +1,79 @@
+package mcache
+
+import "maps"
+
+// WithCopyOnWrite is a functional option for read-mostly caches, like configs or feature flags.
+// Get and Has read an immutable snapshot of the map, loaded atomically, without taking any lock,
+// so they never wait for writers. Writers copy the map once per locked operation, apply
+// all changes of the operation to the copy (a whole LoadFrom batch or Cleanup run at once)
+// and publish it when the lock is released. Every write costs O(n), so it's only
+// a good choice when writes are rare compared to reads.
+func WithCopyOnWrite[T any]() func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.cow = true
+		c.syncMap = false
+	}
+}
+
+// Unlock releases the write lock, publishing the snapshot changed under it WithCopyOnWrite,
+// or dropping the sync.Map WithSyncMapBackend once the cache is closed
+func (c *Cache[T]) Unlock() {
+	if c.cow && (c.dirty || c.closed) {
+		c.publish()
+	}
+	if c.syncMap && c.closed {
+		c.smap.Store(nil)
+	}
+	c.RWMutex.Unlock()
+}
+
+// publish makes the current map visible to lock-free readers, must be called under lock.
+// Nil snapshot tells readers the cache is closed.
+func (c *Cache[T]) publish() {
+	c.dirty = false
+	if c.closed {
+		c.view.Store(nil)
+		return
+	}
+	data := c.data
+	c.view.Store(&data)
+}
+
+// mutate copies the published map before its first change under the lock, must be called under lock
+func (c *Cache[T]) mutate() {
+	if c.cow && !c.dirty {
+		c.data = maps.Clone(c.data)
+		c.dirty = true
+	}
+}
+
+// replaceData replaces the map with a new one, must be called under lock
+func (c *Cache[T]) replaceData(data map[string]CacheItem[T]) {
+	c.data = data
+	c.dirty = c.cow
+	if c.syncMap {
+		c.smap.Store(newSyncMap(data))
+	}
+}
+
+// lookupView is lookup from the published snapshot WithCopyOnWrite, expired item is deleted under lock
+func (c *Cache[T]) lookupView(key string) (CacheItem[T], error) {
+	data := c.view.Load()
+	if data == nil {
+		return CacheItem[T]{}, ErrClosed
+	}
+	item, ok := (*data)[key]
+	if !ok {
+		return CacheItem[T]{}, c.keyError(ErrKeyNotFound, key)
+	}
+	if !c.expired(item) {
+		return item, nil
+	}
+	if c.keepExpired {
+		return CacheItem[T]{}, c.keyError(ErrExpired, key)
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	return c.lookupLocked(key)
+}
@@ -0,0 +1,83 @@
+package mcache
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache/clocktest"
+)
+
+func TestWithCopyOnWrite(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	cache := NewCache(WithCopyOnWrite[string](), WithSize[string](10), WithClock[string](clock))
+	assert.True(t, cache.Set("key", "value", 0))
+	assert.ErrorIs(t, cache.Add("key", "other", 0), ErrKeyExists)
+	cache.Set("expired", "value", time.Millisecond)
+	cache.Set("deleted", "value", 0)
+	require.NoError(t, cache.Del("deleted"))
+
+	v, err := cache.Get("key")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", v)
+	_, err = cache.Get("deleted")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	// readers don't take the lock
+	cache.Lock()
+	has, err := cache.Has("key")
+	cache.Unlock()
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	// expired key is deleted from the published snapshot
+	clock.Advance(time.Millisecond + 1)
+	_, err = cache.Get("expired")
+	assert.ErrorIs(t, err, ErrExpired)
+	_, err = cache.Get("expired")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	cache.Set("expired", "value", time.Millisecond)
+	clock.Advance(time.Millisecond + 1)
+	cache.Cleanup()
+	_, err = cache.Get("expired")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	require.NoError(t, cache.Clear())
+	_, err = cache.Get("key")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	require.NoError(t, cache.Close())
+	_, err = cache.Get("key")
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestWithCopyOnWriteConcurrent(t *testing.T) {
+	cache := NewCache(WithCopyOnWrite[int](), WithInPlaceCleanup[int]())
+	for i := 0; i < 100; i++ {
+		cache.Set(strconv.Itoa(i), i, 0)
+	}
+
+	wg := sync.WaitGroup{}
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				v, err := cache.Get(strconv.Itoa(i % 100))
+				if assert.NoError(t, err) {
+					assert.Equal(t, i%100, v)
+				}
+			}
+		}()
+	}
+	for i := 100; i < 200; i++ {
+		cache.Set(strconv.Itoa(i), i, time.Microsecond)
+		cache.Cleanup()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,77 @@
+package mcache
+
+import "time"
+
+// depGraph is a graph of dependencies between keys, declared with SetWithDeps.
+// All methods are called under the cache write lock.
+type depGraph struct {
+	dependents map[string]map[string]struct{} // keys depending on the key
+	deps       map[string][]string            // keys the key depends on
+}
+
+// link records that the key depends on deps
+func (g *depGraph) link(key string, deps []string) {
+	if g.dependents == nil {
+		g.dependents = make(map[string]map[string]struct{})
+		g.deps = make(map[string][]string)
+	}
+	for _, dep := range deps {
+		if g.dependents[dep] == nil {
+			g.dependents[dep] = make(map[string]struct{})
+		}
+		g.dependents[dep][key] = struct{}{}
+	}
+	g.deps[key] = append(g.deps[key], deps...)
+}
+
+// unlink drops dependencies of the key, it's called for every removed key, so the graph
+// doesn't keep keys which are gone
+func (g *depGraph) unlink(key string) {
+	for _, dep := range g.deps[key] {
+		delete(g.dependents[dep], key)
+		if len(g.dependents[dep]) == 0 {
+			delete(g.dependents, dep)
+		}
+	}
+	delete(g.deps, key)
+}
+
+// SetWithDeps is Set of a value derived from other keys, like an aggregate of cached rows:
+// when any of deps is updated or deleted, the key is deleted, cascading further to keys depending on it.
+// Setting the key again replaces its dependencies.
+// Dependencies don't have to exist, and they are not kept in snapshots, WAL or replicas.
+func (c *Cache[T]) SetWithDeps(key string, value T, ttl time.Duration, deps ...string) bool {
+	c.Lock()
+	defer c.Unlock()
+	if c.trySetLocked(key, value, ttl, true) != nil {
+		return false
+	}
+	if len(deps) > 0 {
+		c.deps.link(key, deps)
+	}
+	return true
+}
+
+// changed deletes keys depending on the updated or deleted key, and drops dependencies of the key itself.
+// Must be called under lock.
+func (c *Cache[T]) changed(key string) {
+	if c.deps.deps == nil {
+		return
+	}
+	c.deps.unlink(key)
+	queue := []string{key}
+	for len(queue) > 0 {
+		dep := queue[0]
+		queue = queue[1:]
+		for dependent := range c.deps.dependents[dep] {
+			c.deps.unlink(dependent)
+			if item, ok := c.data[dependent]; ok {
+				c.remove(dependent, item)
+				c.logDel(dependent)
+			}
+			c.dropOld(dependent)
+			queue = append(queue, dependent)
+		}
+		delete(c.deps.dependents, dep)
+	}
+}
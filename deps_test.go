@@ -0,0 +1,88 @@
+package mcache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetWithDeps(t *testing.T) {
+	cache := NewCache[int]()
+	cache.Set("row:1", 1, 0)
+	cache.Set("row:2", 2, 0)
+	assert.True(t, cache.SetWithDeps("sum", 3, 0, "row:1", "row:2"))
+	assert.True(t, cache.SetWithDeps("sum", 4, 0, "row:1"))
+	assert.True(t, cache.SetWithDeps("report", 3, 0, "sum"))
+	assert.True(t, cache.SetWithDeps("other", 2, 0, "row:2"))
+
+	// deleting a dependency cascades to dependents of dependents
+	require.NoError(t, cache.Del("row:1"))
+	for _, key := range []string{"sum", "report"} {
+		has, _ := cache.Has(key)
+		assert.False(t, has, key)
+	}
+	has, _ := cache.Has("other")
+	assert.True(t, has)
+
+	// updating a dependency invalidates dependents too
+	cache.Set("expiring", 1, time.Millisecond)
+	cache.SetWithDeps("derived", 1, 0, "expiring")
+	time.Sleep(2 * time.Millisecond)
+	assert.True(t, cache.Set("expiring", 2, 0))
+	has, _ = cache.Has("derived")
+	assert.False(t, has)
+
+	// setting a key again without deps drops its dependencies
+	cache.SetWithDeps("derived", 1, 0, "row:2")
+	require.NoError(t, cache.Del("derived"))
+	cache.Set("derived", 2, 0)
+	_, err := cache.DelPrefix("row:")
+	require.NoError(t, err)
+	has, _ = cache.Has("derived")
+	assert.True(t, has)
+	has, _ = cache.Has("other")
+	assert.False(t, has, "deleted with DelPrefix of its dependency")
+
+	// dependency cycles terminate, setting b invalidates a
+	cache.SetWithDeps("a", 1, 0, "b")
+	cache.SetWithDeps("b", 1, 0, "a")
+	assert.ErrorIs(t, cache.Del("a"), ErrKeyNotFound)
+	has, _ = cache.Has("b")
+	assert.False(t, has)
+
+	cache.SetWithDeps("c", 1, 0, "d")
+	require.NoError(t, cache.Clear())
+	assert.Empty(t, cache.deps.deps)
+}
+
+func TestSetWithDepsExpiredDependents(t *testing.T) {
+	for name, opts := range map[string][]func(*Cache[int]){
+		"map":         nil,
+		"heap":        {WithExpirationHeap[int]()},
+		"wheel":       {WithTimingWheel[int](time.Millisecond)},
+		"generations": {WithGenerations[int]()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache(opts...)
+			cache.Set("row", 1, 0)
+			for i := 0; i < 10; i++ {
+				assert.True(t, cache.SetWithDeps(fmt.Sprintf("derived:%d", i), i, time.Millisecond, "row", "other"))
+			}
+			time.Sleep(5 * time.Millisecond)
+			cache.Cleanup()
+			cache.Cleanup() // generations drop entries on the second rotation
+			assert.Empty(t, cache.deps.deps)
+			assert.Empty(t, cache.deps.dependents)
+		})
+	}
+
+	// evicted dependents are unlinked too
+	cache := NewCache(WithMaxEntries[int](1))
+	cache.SetWithDeps("derived", 1, 0, "row")
+	cache.Set("new", 2, 0)
+	assert.Empty(t, cache.deps.deps)
+	assert.Empty(t, cache.deps.dependents)
+}
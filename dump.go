@@ -0,0 +1,69 @@
+package mcache
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// Dump writes a human-readable table of entries sorted by key: remaining TTL,
+// approximate value size in bytes (deep WithDeepSizing), and hit count (if WithEntryStats is set).
+// Entries are copied under the read lock and written after it's released,
+// so it's safe to call on a live cache, writes to a slow w don't block the cache.
+func (c *Cache[T]) Dump(w io.Writer) error {
+	type row struct {
+		key  string
+		ttl  string
+		size int
+		hits uint64
+	}
+
+	now := c.now()
+	c.RLock()
+	rows := make([]row, 0, len(c.data)+len(c.old))
+	for _, data := range c.dataMaps() {
+		for k, v := range data {
+			r := row{key: k, ttl: "-", size: c.sizeOf(v.value)}
+			if v.meta != nil {
+				r.hits = v.meta.hits.Load()
+			}
+			if v.expiration != 0 {
+				r.ttl = "expired"
+				if left := time.Duration(v.expiration - now); left > 0 {
+					r.ttl = left.Round(time.Millisecond).String()
+				}
+			}
+			rows = append(rows, r)
+		}
+	}
+	c.RUnlock()
+
+	sort.Slice(rows, func(i, j int) bool { return rows[i].key < rows[j].key })
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tTTL\tSIZE\tHITS")
+	for _, r := range rows {
+		fmt.Fprintf(tw, "%q\t%s\t%d\t%d\n", r.key, r.ttl, r.size, r.hits)
+	}
+	return tw.Flush()
+}
+
+// valueSize approximates size of the value in bytes: size of the type itself
+// plus length of the string or slice contents, referenced memory is not followed otherwise
+func valueSize(v any) int {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return 0
+	}
+	size := int(rv.Type().Size())
+	switch rv.Kind() {
+	case reflect.String:
+		size += rv.Len()
+	case reflect.Slice:
+		size += rv.Len() * int(rv.Type().Elem().Size())
+	}
+	return size
+}
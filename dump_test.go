@@ -0,0 +1,38 @@
+package mcache
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDump(t *testing.T) {
+	cache := NewCache(WithEntryStats[string]())
+	cache.Set("b", "value", 0)
+	cache.Set("a", "long value", time.Hour)
+	cache.Set("c", "", time.Millisecond)
+	cache.Get("b")
+	cache.Get("b")
+	time.Sleep(10 * time.Millisecond)
+
+	buf := bytes.Buffer{}
+	assert.NoError(t, cache.Dump(&buf))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 4)
+	assert.Equal(t, []string{"KEY", "TTL", "SIZE", "HITS"}, strings.Fields(lines[0]))
+	a := strings.Fields(lines[1])
+	assert.Equal(t, `"a"`, a[0])
+	assert.True(t, strings.HasPrefix(a[1], "59m59."), a[1])
+	assert.Equal(t, []string{"26", "0"}, a[2:])
+	assert.Equal(t, []string{`"b"`, "-", "21", "2"}, strings.Fields(lines[2]))
+	assert.Equal(t, []string{`"c"`, "expired", "16", "0"}, strings.Fields(lines[3]))
+}
+
+func TestValueSize(t *testing.T) {
+	assert.Equal(t, 8, valueSize(int64(1)))
+	assert.Equal(t, 24+3*8, valueSize([]int64{1, 2, 3}))
+	assert.Equal(t, 0, valueSize(nil))
+}
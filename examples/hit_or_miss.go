@@ -16,17 +16,14 @@ func demo_hit_or_miss() {
 	fmt.Println("\r\n Hit or Miss:")
 	fmt.Println("------------------------------------------------------------")
 
-	cache := mcache.NewCache[string]()
 	// expensive_func will be called only once
 	// because result will be saved in cache
+	memoized := mcache.Memoize(func(string) (string, error) {
+		fmt.Println("cache miss, calling expensive_func")
+		return expensive_func(), nil
+	}, 0)
 	for i := 0; i < 10; i++ {
-		v, err := cache.Get("expensive value")
-		if err != nil {
-			fmt.Println("cache miss, calling expensive_func")
-			v = expensive_func()
-			cache.Set("expensive value", v, 0)
-			continue
-		}
-		fmt.Println("cache hit - " + v)
+		v, _ := memoized("expensive value")
+		fmt.Println("result - " + v)
 	}
 }
@@ -0,0 +1,205 @@
+package mcache
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// expiryIndex tracks expiration times of entries alongside the map,
+// so expired entries can be found without scanning all of them.
+// All methods are called under the cache write lock.
+type expiryIndex interface {
+	add(node *expiryNode)
+	remove(node *expiryNode)
+	popExpired(now int64, fn func(node *expiryNode)) // calls fn for every expired node removed
+	len() int
+	clear()
+}
+
+// expiryNode is an entry of an expiryIndex
+type expiryNode struct {
+	key        string
+	expiration int64 // nanoseconds since the cache epoch
+
+	index int // position in expiryHeap
+
+	prev, next *expiryNode  // neighbours in a timingWheel slot list
+	slot       **expiryNode // head of the timingWheel slot list
+}
+
+// nodePool recycles nodes of deleted and expired entries, so churn-heavy workloads
+// with an expiration index don't allocate a node on every Set
+var nodePool = sync.Pool{New: func() any { return &expiryNode{} }}
+
+// newNode returns a node from the pool, initialized with the key and expiration
+func newNode(key string, expiration int64) *expiryNode {
+	node := nodePool.Get().(*expiryNode)
+	node.key, node.expiration = key, expiration
+	return node
+}
+
+// releaseNode returns the node removed from the index to the pool
+func releaseNode(node *expiryNode) {
+	*node = expiryNode{}
+	nodePool.Put(node)
+}
+
+// expiryHeap is a min-heap of entries ordered by expiration, implements heap.Interface and expiryIndex
+type expiryHeap []*expiryNode
+
+func (h expiryHeap) Len() int           { return len(h) }
+func (h expiryHeap) Less(i, j int) bool { return h[i].expiration < h[j].expiration }
+
+func (h expiryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *expiryHeap) Push(x any) {
+	node := x.(*expiryNode)
+	node.index = len(*h)
+	*h = append(*h, node)
+}
+
+func (h *expiryHeap) Pop() any {
+	old := *h
+	n := len(old)
+	node := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return node
+}
+
+func (h *expiryHeap) add(node *expiryNode)    { heap.Push(h, node) }
+func (h *expiryHeap) remove(node *expiryNode) { heap.Remove(h, node.index) }
+func (h *expiryHeap) len() int                { return len(*h) }
+func (h *expiryHeap) clear()                  { *h = expiryHeap{} }
+
+func (h *expiryHeap) popExpired(now int64, fn func(node *expiryNode)) {
+	for len(*h) > 0 && (*h)[0].expiration < now {
+		fn(heap.Pop(h).(*expiryNode))
+	}
+}
+
+// store puts the item to the map, replacing the existing one, must be called under lock.
+// All writes to the map go through store and remove, to keep the expiration index in sync.
+// Every stored item gets a new version, so a rewritten entry never keeps its version.
+func (c *Cache[T]) store(key string, item CacheItem[T]) {
+	c.mutate()
+	if c.indexing {
+		c.reindex(key, item)
+	}
+	old, ok := c.data[key]
+	if ok {
+		c.untrack(old)
+	}
+	if priorityOf(item) != PriorityLow {
+		delete(c.lowPriority, key)
+	}
+	if c.spilled != nil {
+		c.dropSpilled(key)
+	}
+	if !ok && c.maxEntries > 0 && c.fullPolicy != Reject {
+		if _, ok = c.old[key]; !ok {
+			c.makeRoom()
+		}
+	}
+	if c.expiry != nil && item.expiration != 0 {
+		item.node = newNode(key, item.expiration)
+		c.expiry.add(item.node)
+	}
+	c.version++
+	item.version = c.version
+	c.data[key] = item
+	if c.generations {
+		delete(c.old, key)
+	}
+	if c.syncMap {
+		c.smap.Load().Store(key, item)
+	}
+}
+
+// remove deletes the key with its current item from the map, must be called under lock
+func (c *Cache[T]) remove(key string, item CacheItem[T]) {
+	c.mutate()
+	c.untrack(item)
+	if c.indexing {
+		c.unindex(key, item)
+	}
+	c.forget(key)
+	delete(c.data, key)
+	if c.syncMap {
+		c.smap.Load().Delete(key)
+	}
+}
+
+// forget drops the removed key from the dependency graph and the pool of low priority keys
+func (c *Cache[T]) forget(key string) {
+	c.deps.unlink(key)
+	delete(c.lowPriority, key)
+}
+
+// untrack removes the item from the expiration index
+func (c *Cache[T]) untrack(item CacheItem[T]) {
+	if item.node != nil {
+		c.expiry.remove(item.node)
+		releaseNode(item.node)
+	}
+}
+
+// popExpired deletes expired entries popping them from the expiration index, must be called under lock
+func (c *Cache[T]) popExpired() (removed int) {
+	c.expiry.popExpired(c.now(), func(node *expiryNode) {
+		c.mutate()
+		if c.indexing {
+			c.unindex(node.key, c.data[node.key])
+		}
+		c.forget(node.key)
+		delete(c.data, node.key)
+		if c.syncMap {
+			c.smap.Load().Delete(node.key)
+		}
+		releaseNode(node)
+		removed++
+	})
+	return removed
+}
+
+// NextExpiry returns the earliest expiration time among the entries, false if no entry expires.
+// It's O(1) WithExpirationHeap, and scans all entries otherwise.
+// Returned time may be in the past, if expired entries are not removed yet.
+func (c *Cache[T]) NextExpiry() (time.Time, bool) {
+	c.RLock()
+	defer c.RUnlock()
+	if h, ok := c.expiry.(*expiryHeap); ok {
+		if len(*h) == 0 {
+			return time.Time{}, false
+		}
+		return c.wallTime((*h)[0].expiration), true
+	}
+
+	var next int64
+	for _, data := range c.dataMaps() {
+		for _, v := range data {
+			if v.expiration != 0 && (next == 0 || v.expiration < next) {
+				next = v.expiration
+			}
+		}
+	}
+	if next == 0 {
+		return time.Time{}, false
+	}
+	return c.wallTime(next), true
+}
+
+// WithExpirationHeap is a functional option for keeping entries in a min-heap ordered by expiration,
+// alongside the map. Cleanup pops only the expired entries instead of scanning all of them,
+// so its cost depends on the number of expired entries, not on the cache size,
+// at the cost of O(log n) heap update on every Set and delete.
+func WithExpirationHeap[T any]() func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.expiry = &expiryHeap{}
+	}
+}
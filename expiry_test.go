@@ -0,0 +1,97 @@
+package mcache
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithExpirationHeap(t *testing.T) {
+	cache := NewCache(WithExpirationHeap[int]())
+	_, ok := cache.NextExpiry()
+	assert.False(t, ok)
+
+	for i := 0; i < 100; i++ {
+		cache.Set("live_"+strconv.Itoa(i), i, time.Hour+time.Duration(i)*time.Second)
+		cache.Set("expired_"+strconv.Itoa(i), i, time.Millisecond)
+		cache.Set("forever_"+strconv.Itoa(i), i, 0)
+	}
+	assert.Equal(t, 200, cache.expiry.len(), "entries without expiration are not in the heap")
+
+	next, ok := cache.NextExpiry()
+	assert.True(t, ok)
+	assert.True(t, next.Before(time.Now().Add(time.Millisecond)))
+
+	require.NoError(t, cache.Del("live_0"))
+	require.NoError(t, cache.Del("forever_0"))
+	assert.Equal(t, 199, cache.expiry.len())
+	time.Sleep(10 * time.Millisecond)
+
+	// expired key is rewritten, and its old heap node is replaced
+	assert.True(t, cache.Set("expired_0", 0, time.Hour))
+	_, err := cache.Get("expired_1")
+	assert.ErrorIs(t, err, ErrExpired)
+	assert.Equal(t, 198, cache.expiry.len())
+
+	removed, _ := cache.CleanupN()
+	assert.Equal(t, 98, removed)
+	assert.Equal(t, 100, cache.expiry.len())
+	assert.Equal(t, 199, cache.Stats().Entries)
+	for i, node := range *cache.expiry.(*expiryHeap) {
+		assert.Equal(t, i, node.index)
+		assert.Same(t, node, cache.data[node.key].node)
+	}
+
+	next, ok = cache.NextExpiry()
+	assert.True(t, ok)
+	info, err := cache.EntryInfo("expired_0")
+	require.NoError(t, err)
+	assert.Equal(t, info.Expiration, next)
+
+	require.NoError(t, cache.Clear())
+	assert.Equal(t, 0, cache.expiry.len())
+}
+
+func TestNextExpiryWithoutHeap(t *testing.T) {
+	cache := NewCache[int]()
+	cache.Set("forever", 0, 0)
+	_, ok := cache.NextExpiry()
+	assert.False(t, ok)
+
+	cache.Set("hour", 0, time.Hour)
+	cache.Set("minute", 0, time.Minute)
+	next, ok := cache.NextExpiry()
+	assert.True(t, ok)
+	info, err := cache.EntryInfo("minute")
+	require.NoError(t, err)
+	assert.Equal(t, info.Expiration, next)
+}
+
+func TestExpiryNodeReuse(t *testing.T) {
+	for name, opt := range map[string]func(*Cache[int]){
+		"heap":  WithExpirationHeap[int](),
+		"wheel": WithTimingWheel[int](time.Hour),
+	} {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache(opt)
+			defer cache.Close()
+			cache.Set("key", 1, time.Hour)
+
+			// nodes of deleted entries are recycled, pool may drop some of them under race detector
+			allocs := testing.AllocsPerRun(100, func() {
+				_ = cache.Del("key")
+				cache.Set("key", 1, time.Hour)
+			})
+			assert.Less(t, allocs, 1.0)
+			assert.Equal(t, 1, cache.expiry.len())
+
+			// recycled node is reset
+			node := newNode("key", 1)
+			releaseNode(node)
+			assert.Equal(t, expiryNode{}, *node)
+		})
+	}
+}
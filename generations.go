@@ -0,0 +1,54 @@
+package mcache
+
+// WithGenerations is a functional option for keeping entries in two generations, instead of a single map.
+// New entries go to the current generation, and entries of the old generation move to the current one
+// when they are accessed with Get or Has. Cleanup doesn't scan entries, it drops the old generation
+// wholesale and makes the current one old, so entries not accessed during two Cleanup intervals are evicted
+// even if they don't expire yet. It bounds both the memory held by stale entries and the Cleanup cost
+// of very large caches, Cleanup interval is set WithCleanup.
+// It can't be combined with WithCopyOnWrite, WithSyncMapBackend, WithExpirationHeap and WithTimingWheel.
+func WithGenerations[T any]() func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.generations = true
+	}
+}
+
+// rotate drops the old generation and starts a new one, returns the number of dropped entries.
+// Must be called under lock.
+func (c *Cache[T]) rotate() (removed int) {
+	removed = len(c.old)
+	if c.indexing || len(c.deps.deps) > 0 || len(c.lowPriority) > 0 {
+		for k, v := range c.old {
+			if c.indexing {
+				c.unindex(k, v)
+			}
+			c.forget(k)
+		}
+	}
+	c.old = c.data
+	c.data = make(map[string]CacheItem[T], c.initialSize)
+	return removed
+}
+
+// promote moves the item of the old generation to the current one, must be called under lock
+func (c *Cache[T]) promote(key string, item CacheItem[T]) {
+	delete(c.old, key)
+	c.data[key] = item
+}
+
+// dropOld deletes the key from the old generation, must be called under lock
+func (c *Cache[T]) dropOld(key string) {
+	if item, ok := c.old[key]; ok {
+		if c.indexing {
+			c.unindex(key, item)
+		}
+		c.forget(key)
+		delete(c.old, key)
+	}
+}
+
+// dataMaps returns the current and old generations, old one is nil unless WithGenerations is set.
+// Must be called under lock.
+func (c *Cache[T]) dataMaps() []map[string]CacheItem[T] {
+	return []map[string]CacheItem[T]{c.data, c.old}
+}
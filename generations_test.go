@@ -0,0 +1,71 @@
+package mcache
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache/clocktest"
+)
+
+func TestWithGenerations(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	cache := NewCache(WithGenerations[string](), WithClock[string](clock))
+	cache.Set("accessed", "value", 0)
+	cache.Set("idle", "value", 0)
+	cache.Set("deleted", "value", 0)
+	cache.Set("replaced", "value", time.Millisecond)
+	cache.Set("expired", "value", time.Millisecond)
+
+	removed, _ := cache.CleanupN()
+	assert.Equal(t, 0, removed)
+	assert.Equal(t, 5, cache.Stats().Entries)
+	assert.Len(t, cache.old, 5)
+
+	// old generation is still visible
+	assert.ErrorIs(t, cache.Add("idle", "other", 0), ErrKeyExists)
+	info, err := cache.EntryInfo("idle")
+	assert.NoError(t, err)
+	assert.True(t, info.Expiration.IsZero())
+	next, ok := cache.NextExpiry()
+	assert.True(t, ok)
+	assert.False(t, next.IsZero())
+	buf := bytes.Buffer{}
+	require.NoError(t, cache.Dump(&buf))
+	assert.Contains(t, buf.String(), `"idle"`)
+
+	// accessed entries move to the current generation
+	v, err := cache.Get("accessed")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", v)
+	assert.Contains(t, cache.data, "accessed")
+	assert.NotContains(t, cache.old, "accessed")
+	require.NoError(t, cache.Del("deleted"))
+	clock.Advance(time.Millisecond + 1)
+	assert.True(t, cache.Set("replaced", "new", 0))
+	assert.NotContains(t, cache.old, "replaced")
+	_, err = cache.Has("expired")
+	assert.ErrorIs(t, err, ErrExpired)
+	assert.Equal(t, 3, cache.Stats().Entries)
+
+	// entries not accessed during two cleanups are dropped
+	removed, _ = cache.CleanupN()
+	assert.Equal(t, 1, removed)
+	_, err = cache.Get("idle")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	_, err = cache.GetBytes([]byte("accessed"))
+	assert.NoError(t, err)
+	assert.True(t, cache.SetBytes([]byte("replaced"), "other", 0))
+
+	saved := bytes.Buffer{}
+	require.NoError(t, cache.SaveTo(&saved))
+	loaded := NewCache[string]()
+	require.NoError(t, loaded.LoadFrom(&saved))
+	assert.Equal(t, 2, loaded.Stats().Entries)
+
+	require.NoError(t, cache.Clear())
+	assert.Equal(t, 0, cache.Stats().Entries)
+}
@@ -0,0 +1,162 @@
+package mcache
+
+import "errors"
+
+// ErrNoIndex is returned for an index not registered WithIndex
+var ErrNoIndex = errors.New("index not found")
+
+// valueIndex is an inverted index of values extracted from cached values to keys
+type valueIndex[T any] struct {
+	extract func(T) string
+	keys    map[string]map[string]struct{}
+}
+
+// add indexes the value of the key
+func (ix *valueIndex[T]) add(key string, value T) {
+	v := ix.extract(value)
+	keys, ok := ix.keys[v]
+	if !ok {
+		keys = map[string]struct{}{}
+		ix.keys[v] = keys
+	}
+	keys[key] = struct{}{}
+}
+
+// del drops the value of the key from the index
+func (ix *valueIndex[T]) del(key string, value T) {
+	v := ix.extract(value)
+	if keys, ok := ix.keys[v]; ok {
+		delete(keys, key)
+		if len(keys) == 0 {
+			delete(ix.keys, v)
+		}
+	}
+}
+
+// WithIndex is a functional option for registering an index of values extracted from cached values
+// with extract, like a status of orders, queried with WhereIndex. The index is updated on every change
+// of the cache, so extract must be fast and return the same result for the same value.
+// Negative entries and entries spilled WithOverflow are not indexed.
+func WithIndex[T any](name string, extract func(T) string) func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		if c.indexes == nil {
+			c.indexes = map[string]*valueIndex[T]{}
+		}
+		c.indexes[name] = &valueIndex[T]{extract: extract, keys: map[string]map[string]struct{}{}}
+		c.indexing = true
+	}
+}
+
+// index adds the item of the key to all indexes, ordered keys and insertion order, must be called under lock
+func (c *Cache[T]) index(key string, item CacheItem[T]) {
+	if c.ordered != nil {
+		c.insertOrdered(key)
+	}
+	if c.insertion != nil {
+		c.insertion.push(key)
+	}
+	if item.negative {
+		return
+	}
+	for _, ix := range c.indexes {
+		ix.add(key, item.value)
+	}
+}
+
+// reindex replaces the indexed item of the key, the previous one is looked up in both generations.
+// Must be called under lock.
+func (c *Cache[T]) reindex(key string, item CacheItem[T]) {
+	prev, ok := c.data[key]
+	if !ok {
+		prev, ok = c.old[key]
+	}
+	if ok && !prev.negative {
+		for _, ix := range c.indexes {
+			ix.del(key, prev.value)
+		}
+	}
+	if ok && c.insertion != nil && c.expired(prev) {
+		c.insertion.remove(key) // set again after expiration, it's a new insertion
+	}
+	c.index(key, item)
+}
+
+// unindex drops the item of the key from all indexes, ordered keys and insertion order, must be called under lock
+func (c *Cache[T]) unindex(key string, item CacheItem[T]) {
+	if c.ordered != nil {
+		c.ordered.stale++
+	}
+	if c.insertion != nil {
+		c.insertion.remove(key)
+	}
+	if item.negative {
+		return
+	}
+	for _, ix := range c.indexes {
+		ix.del(key, item.value)
+	}
+}
+
+// Where returns live entries with values matching fn, scanning all entries, see Filter.
+// WhereIndex narrows the scan down to the entries found in an index.
+func (c *Cache[T]) Where(fn func(value T) bool) map[string]T {
+	return c.Filter(func(_ string, value T) bool { return fn(value) })
+}
+
+// WhereIndex returns live entries with the value indexed by the named index WithIndex
+// and matching fn, nil fn matches all of them. Only the entries found in the index are checked,
+// so it doesn't degrade to a full scan like Where. Returns ErrNoIndex for an unknown index.
+func (c *Cache[T]) WhereIndex(name, value string, fn func(value T) bool) (map[string]T, error) {
+	c.RLock()
+	defer c.RUnlock()
+	result := map[string]T{}
+	err := c.indexed(name, value, func(k string, v T) {
+		if fn == nil || fn(v) {
+			result[k] = v
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetByIndex returns live entries with the value indexed by the named index WithIndex,
+// like sessions of a user in a cache keyed by session ID. Returns ErrNoIndex for an unknown index.
+func (c *Cache[T]) GetByIndex(name, value string) (map[string]T, error) {
+	return c.WhereIndex(name, value, nil)
+}
+
+// IndexKeys returns live keys with the value indexed by the named index WithIndex, in no particular order.
+// Returns ErrNoIndex for an unknown index.
+func (c *Cache[T]) IndexKeys(name, value string) ([]string, error) {
+	c.RLock()
+	defer c.RUnlock()
+	keys := []string{}
+	err := c.indexed(name, value, func(k string, _ T) {
+		keys = append(keys, k)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// indexed calls fn for live entries with the value indexed by the named index, must be called under lock
+func (c *Cache[T]) indexed(name, value string, fn func(key string, value T)) error {
+	ix, ok := c.indexes[name]
+	if !ok {
+		return ErrNoIndex
+	}
+	now := c.now()
+	for k := range ix.keys[value] {
+		item, ok := c.data[k]
+		if !ok {
+			item, ok = c.old[k]
+		}
+		if ok && !item.expiredAt(now) {
+			fn(k, item.value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package mcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache/clocktest"
+)
+
+type order struct {
+	status string
+	total  int
+}
+
+func TestWhere(t *testing.T) {
+	for name, opt := range map[string]func(*Cache[order]){
+		"map":         func(*Cache[order]) {},
+		"generations": WithGenerations[order](),
+		"heap":        WithExpirationHeap[order](),
+	} {
+		t.Run(name, func(t *testing.T) {
+			clock := clocktest.New(time.Now())
+			cache := NewCache(opt, WithClock[order](clock), WithIndex[order]("status", func(o order) string { return o.status }))
+			require.True(t, cache.Set("1", order{"pending", 10}, 0))
+			require.True(t, cache.Set("2", order{"pending", 200}, time.Minute))
+			require.True(t, cache.Set("3", order{"shipped", 30}, 0))
+			require.True(t, cache.Set("4", order{"pending", 40}, time.Second))
+
+			large := cache.Where(func(o order) bool { return o.total > 20 })
+			assert.Len(t, large, 3)
+
+			pending, err := cache.WhereIndex("status", "pending", nil)
+			require.NoError(t, err)
+			assert.Len(t, pending, 3)
+			pending, err = cache.WhereIndex("status", "pending", func(o order) bool { return o.total > 20 })
+			require.NoError(t, err)
+			assert.Equal(t, map[string]order{"2": {"pending", 200}, "4": {"pending", 40}}, pending)
+
+			clock.Advance(2 * time.Second)
+			cache.Cleanup()
+			require.NoError(t, cache.Del("1"))
+			require.True(t, cache.Set("4", order{"shipped", 40}, 0))
+			pending, err = cache.WhereIndex("status", "pending", nil)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]order{"2": {"pending", 200}}, pending)
+			shipped, err := cache.WhereIndex("status", "shipped", nil)
+			require.NoError(t, err)
+			assert.Len(t, shipped, 2)
+
+			require.NoError(t, cache.Clear())
+			assert.Empty(t, cache.indexes["status"].keys)
+			_, err = cache.WhereIndex("customer", "1", nil)
+			assert.ErrorIs(t, err, ErrNoIndex)
+		})
+	}
+}
+
+func TestGetByIndex(t *testing.T) {
+	type session struct{ user string }
+	clock := clocktest.New(time.Now())
+	cache := NewCache(WithClock[session](clock), WithMaxEntries[session](5),
+		WithIndex[session]("user", func(s session) string { return s.user }))
+
+	require.True(t, cache.Set("s1", session{"alice"}, time.Minute))
+	require.True(t, cache.Set("s2", session{"alice"}, time.Hour))
+	require.True(t, cache.Set("s3", session{"bob"}, time.Hour))
+	require.True(t, cache.Set("tmp:s4", session{"alice"}, 0))
+
+	sessions, err := cache.GetByIndex("user", "alice")
+	require.NoError(t, err)
+	assert.Len(t, sessions, 3)
+	keys, err := cache.IndexKeys("user", "bob")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"s3"}, keys)
+
+	clock.Advance(2 * time.Minute) // s1 expires
+	keys, err = cache.IndexKeys("user", "alice")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"s2", "tmp:s4"}, keys, "expired entries are skipped before cleanup")
+	_, err = cache.Get("s1")
+	assert.ErrorIs(t, err, ErrExpired)
+	n, err := cache.DelPrefix("tmp:")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, map[string]struct{}{"s2": {}}, cache.indexes["user"].keys["alice"], "index is updated")
+
+	for i := 0; i < 10; i++ {
+		cache.Set(string(rune('a'+i)), session{"carol"}, 0)
+	}
+	total := 0
+	for _, keys := range cache.indexes["user"].keys {
+		total += len(keys)
+	}
+	assert.Equal(t, 5, total, "evicted entries are dropped from the index")
+
+	_, err = cache.GetByIndex("session", "s1")
+	assert.ErrorIs(t, err, ErrNoIndex)
+	_, err = cache.IndexKeys("session", "s1")
+	assert.ErrorIs(t, err, ErrNoIndex)
+}
@@ -0,0 +1,111 @@
+package mcache
+
+import "container/list"
+
+// insertionOrder is a list of keys in order of insertion, with an element of every key to remove it in O(1)
+type insertionOrder struct {
+	keys  *list.List
+	elems map[string]*list.Element
+}
+
+func newInsertionOrder() *insertionOrder {
+	return &insertionOrder{keys: list.New(), elems: map[string]*list.Element{}}
+}
+
+// push appends the key, unless it's already in the list
+func (o *insertionOrder) push(key string) {
+	if _, ok := o.elems[key]; !ok {
+		o.elems[key] = o.keys.PushBack(key)
+	}
+}
+
+// remove drops the key from the list
+func (o *insertionOrder) remove(key string) {
+	if e, ok := o.elems[key]; ok {
+		o.keys.Remove(e)
+		delete(o.elems, key)
+	}
+}
+
+// reset drops all keys
+func (o *insertionOrder) reset() {
+	o.keys.Init()
+	clear(o.elems)
+}
+
+// WithInsertionOrder is a functional option for tracking the order keys are inserted in,
+// for OldestKeys and RangeByInsertion. Setting a live key again keeps its place,
+// a key set again after it expired is a new insertion. Entries spilled WithOverflow are not included.
+// WithFullPolicy(EvictOldest) tracks insertion order as well.
+func WithInsertionOrder[T any]() func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		if c.insertion == nil {
+			c.insertion = newInsertionOrder()
+		}
+		c.indexing = true
+	}
+}
+
+// OldestKeys returns up to n live keys in order of insertion, the oldest first, like for diagnostics
+// of the oldest entries. Returns nil for a cache created without WithInsertionOrder.
+func (c *Cache[T]) OldestKeys(n int) []string {
+	c.RLock()
+	defer c.RUnlock()
+	if c.insertion == nil {
+		return nil
+	}
+	keys := make([]string, 0, min(n, len(c.insertion.elems)))
+	now := c.now()
+	for e := c.insertion.keys.Front(); e != nil && len(keys) < n; e = e.Next() {
+		if k := e.Value.(string); c.live(k, now) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// RangeByInsertion calls fn for live entries in order of insertion, the oldest first, until fn returns false.
+// fn is called under the read lock and must not call the cache. Does nothing for a cache
+// created without WithInsertionOrder.
+func (c *Cache[T]) RangeByInsertion(fn func(key string, value T) bool) {
+	c.RLock()
+	defer c.RUnlock()
+	if c.insertion == nil {
+		return
+	}
+	now := c.now()
+	for e := c.insertion.keys.Front(); e != nil; e = e.Next() {
+		k := e.Value.(string)
+		item, ok := c.data[k]
+		if !ok {
+			item, ok = c.old[k]
+		}
+		if ok && !item.expiredAt(now) && !item.negative && !fn(k, item.value) {
+			return
+		}
+	}
+}
+
+// evictOldest evicts the oldest inserted entry which isn't pinned, must be called under lock
+func (c *Cache[T]) evictOldest() bool {
+	for e := c.insertion.keys.Front(); e != nil; e = e.Next() {
+		k := e.Value.(string)
+		if _, ok := c.pinned[k]; ok {
+			continue
+		}
+		item, ok := c.data[k]
+		if ok {
+			c.remove(k, item)
+		} else if item, ok = c.old[k]; ok {
+			c.dropOld(k)
+		} else {
+			continue
+		}
+		c.evicted(1)
+		if !c.expired(item) {
+			c.spill(k, item)
+		}
+		return true
+	}
+	return false
+}
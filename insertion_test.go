@@ -0,0 +1,56 @@
+package mcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache/clocktest"
+)
+
+func TestWithInsertionOrder(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	cache := NewCache(WithClock[int](clock), WithInsertionOrder[int]())
+	for i, k := range []string{"c", "a", "d", "b"} {
+		require.True(t, cache.Set(k, i, time.Minute))
+	}
+	assert.Equal(t, []string{"c", "a"}, cache.OldestKeys(2))
+
+	require.NoError(t, cache.Del("a"))
+	require.True(t, cache.Set("a", 10, 0))
+	assert.Equal(t, []string{"c", "d", "b", "a"}, cache.OldestKeys(10))
+
+	clock.Advance(2 * time.Minute)
+	require.True(t, cache.Set("d", 20, 0), "set again after expiration")
+	assert.Equal(t, []string{"a", "d"}, cache.OldestKeys(10))
+
+	var keys []string
+	cache.RangeByInsertion(func(key string, value int) bool {
+		keys = append(keys, key)
+		return false
+	})
+	assert.Equal(t, []string{"a"}, keys)
+
+	cache.Cleanup()
+	assert.Equal(t, 2, cache.insertion.keys.Len())
+	require.NoError(t, cache.Clear())
+	assert.Empty(t, cache.OldestKeys(10))
+	assert.Nil(t, NewCache[int]().OldestKeys(10))
+}
+
+func TestWithFullPolicyEvictOldest(t *testing.T) {
+	cache := NewCache(WithMaxEntries[int](3), WithFullPolicy[int](EvictOldest))
+	for i, k := range []string{"a", "b", "c"} {
+		require.True(t, cache.Set(k, i, 0))
+	}
+	_, err := cache.Get("a") // recency doesn't matter
+	require.NoError(t, err)
+	cache.PinForever("b")
+
+	require.True(t, cache.Set("d", 3, 0))
+	require.True(t, cache.Set("e", 4, 0))
+	assert.Equal(t, []string{"b", "d", "e"}, cache.OldestKeys(10))
+	assert.Equal(t, uint64(2), cache.Stats().Evictions)
+}
@@ -0,0 +1,164 @@
+package mcache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync"
+)
+
+// Invalidation is a message about keys deleted by one of the caches sharing an Invalidator
+type Invalidation struct {
+	Key    string // deleted key, or prefix of deleted keys if Prefix is set
+	Prefix bool
+	Source string // id of the publishing cache, which skips its own messages
+}
+
+// Invalidator is a pub/sub transport of invalidations between cache instances,
+// like Redis pub/sub or NATS. InvalidationBus implements it in process.
+type Invalidator interface {
+	Publish(ctx context.Context, msg Invalidation) error
+	// Subscribe calls fn for every published message, including own ones, until unsubscribe is called
+	Subscribe(fn func(msg Invalidation)) (unsubscribe func(), err error)
+}
+
+// invalidation is the state of a cache created WithInvalidator
+type invalidation struct {
+	inv         Invalidator
+	id          string
+	unsubscribe func()
+}
+
+// WithInvalidator is a functional option for keeping caches of multiple processes coherent:
+// Del and DelPrefix publish invalidations with inv, and invalidations published by other caches
+// are applied locally, without publishing them again.
+// Publish and Subscribe errors are logged, see WithLogger.
+func WithInvalidator[T any](inv Invalidator) func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		id := make([]byte, 8)
+		rand.Read(id) //nolint:errcheck // never fails
+		c.invalidation = &invalidation{inv: inv, id: hex.EncodeToString(id)}
+	}
+}
+
+// subscribe starts applying remote invalidations, called by NewCache WithInvalidator
+func (c *Cache[T]) subscribe() {
+	unsubscribe, err := c.invalidation.inv.Subscribe(c.applyInvalidation)
+	if err != nil {
+		c.logger.Warn("mcache invalidation: failed to subscribe", "err", err)
+		return
+	}
+	c.invalidation.unsubscribe = unsubscribe
+}
+
+// broadcast publishes the invalidation, if WithInvalidator is set
+func (c *Cache[T]) broadcast(msg Invalidation) {
+	if c.invalidation == nil {
+		return
+	}
+	msg.Source = c.invalidation.id
+	if err := c.invalidation.inv.Publish(context.Background(), msg); err != nil {
+		c.logger.Warn("mcache invalidation: failed to publish", "key", msg.Key, "err", err)
+	}
+}
+
+// applyInvalidation deletes keys invalidated by another cache
+func (c *Cache[T]) applyInvalidation(msg Invalidation) {
+	if msg.Source == c.invalidation.id {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	if msg.Prefix {
+		c.delPrefixLocked(msg.Key)
+		return
+	}
+	c.delLocked(msg.Key) //nolint:errcheck // missing key is fine
+}
+
+// InvalidationBus is an in-process Invalidator, for caches of a single process
+// and for testing. Messages are delivered synchronously by Publish.
+type InvalidationBus struct {
+	mu   sync.RWMutex
+	subs map[int]func(msg Invalidation)
+	next int
+}
+
+// NewInvalidationBus is a constructor for InvalidationBus
+func NewInvalidationBus() *InvalidationBus {
+	return &InvalidationBus{subs: make(map[int]func(msg Invalidation))}
+}
+
+// Publish delivers the message to all subscribers
+func (b *InvalidationBus) Publish(_ context.Context, msg Invalidation) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, fn := range b.subs {
+		fn(msg)
+	}
+	return nil
+}
+
+// Subscribe adds fn to subscribers
+func (b *InvalidationBus) Subscribe(fn func(msg Invalidation)) (unsubscribe func(), err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	id := b.next
+	b.next++
+	b.subs[id] = fn
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subs, id)
+	}, nil
+}
+
+// DelPrefix deletes all keys starting with the prefix, returns the number of deleted live keys.
+// If cache is closed, return ErrClosed.
+func (c *Cache[T]) DelPrefix(prefix string) (int, error) {
+	c.Lock()
+	if c.closed {
+		c.Unlock()
+		return 0, ErrClosed
+	}
+	n := c.delPrefixLocked(prefix)
+	c.Unlock()
+	c.broadcast(Invalidation{Key: prefix, Prefix: true})
+	return n, nil
+}
+
+// delPrefixLocked is DelPrefix under the write lock
+func (c *Cache[T]) delPrefixLocked(prefix string) (deleted int) {
+	if c.closed {
+		return 0
+	}
+	now := c.now()
+	for k, v := range c.data {
+		if strings.HasPrefix(k, prefix) {
+			if !v.expiredAt(now) {
+				deleted++
+			}
+			c.remove(k, v)
+			c.logDel(k)
+			c.changed(k)
+		}
+	}
+	for k, v := range c.old {
+		if strings.HasPrefix(k, prefix) {
+			if !v.expiredAt(now) {
+				deleted++
+			}
+			c.dropOld(k)
+		}
+	}
+	for k := range c.spilled {
+		if strings.HasPrefix(k, prefix) {
+			if c.spilledLive(k) {
+				deleted++
+			}
+			c.dropSpilled(k)
+		}
+	}
+	return deleted
+}
@@ -0,0 +1,76 @@
+package mcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDelPrefix(t *testing.T) {
+	cache := NewCache[string](WithGenerations[string]())
+	cache.Set("user:1", "a", 0)
+	cache.Set("user:2", "b", 0)
+	cache.Set("order:1", "c", 0)
+	cache.CleanupN() // moves keys to the old generation
+	cache.Set("user:3", "d", 0)
+	cache.Set("user:4", "e", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	n, err := cache.DelPrefix("user:")
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	for _, key := range []string{"user:1", "user:2", "user:3", "user:4"} {
+		_, err = cache.Get(key)
+		assert.ErrorIs(t, err, ErrKeyNotFound, key)
+	}
+	v, err := cache.Get("order:1")
+	require.NoError(t, err)
+	assert.Equal(t, "c", v)
+
+	require.NoError(t, cache.Close())
+	_, err = cache.DelPrefix("order:")
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestWithInvalidator(t *testing.T) {
+	bus := NewInvalidationBus()
+	caches := make([]*Cache[string], 3)
+	for i := range caches {
+		caches[i] = NewCache(WithInvalidator[string](bus))
+		caches[i].Set("key", "value", 0)
+		caches[i].Set("user:1", "value", 0)
+		caches[i].Set("user:2", "value", 0)
+	}
+
+	// key deleted in one cache is deleted in all of them
+	require.NoError(t, caches[0].Del("key"))
+	for _, c := range caches {
+		has, _ := c.Has("key")
+		assert.False(t, has)
+	}
+	// even if it's missing in the publishing one
+	caches[1].Set("key", "value", 0)
+	assert.ErrorIs(t, caches[0].Del("key"), ErrKeyNotFound)
+	has, _ := caches[1].Has("key")
+	assert.False(t, has)
+
+	n, err := caches[2].DelPrefix("user:")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	for _, c := range caches {
+		has, _ = c.Has("user:1")
+		assert.False(t, has)
+	}
+
+	// closed cache doesn't publish and doesn't apply invalidations anymore
+	require.NoError(t, caches[0].Close())
+	caches[0].Set("key", "value", 0)
+	caches[1].Set("key", "value", 0)
+	assert.ErrorIs(t, caches[0].Del("key"), ErrClosed)
+	has, _ = caches[1].Has("key")
+	assert.True(t, has)
+	require.NoError(t, caches[1].Del("key"))
+	assert.Len(t, bus.subs, 2)
+}
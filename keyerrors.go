@@ -0,0 +1,31 @@
+package mcache
+
+import "fmt"
+
+// WithKeyErrors is a functional option for wrapping ErrKeyNotFound, ErrExpired and ErrKeyExists
+// with the key, like `key not found: "k"`, so the failed key of a batch of calls is known from the error.
+// Errors stay comparable with errors.Is, but not with ==. Wrapping allocates on every miss,
+// so misses are not free anymore.
+func WithKeyErrors[T any]() func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.keyErrors = true
+	}
+}
+
+// keyError wraps the error with the key WithKeyErrors
+func (c *Cache[T]) keyError(err error, key string) error {
+	if !c.keyErrors {
+		return err
+	}
+	return wrapKey(err, key)
+}
+
+// wrapKey wraps ErrKeyNotFound, ErrExpired and ErrKeyExists with the key,
+// other and already wrapped errors are returned as is
+func wrapKey(err error, key string) error {
+	switch err {
+	case ErrKeyNotFound, ErrExpired, ErrKeyExists:
+		return fmt.Errorf("%w: %q", err, key)
+	}
+	return err
+}
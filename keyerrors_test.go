@@ -0,0 +1,44 @@
+package mcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache/clocktest"
+)
+
+func TestWithKeyErrors(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	cache := NewCache(WithKeyErrors[int](), WithClock[int](clock))
+	cache.Set("key", 1, 0)
+	cache.Set("expired", 1, time.Millisecond)
+	clock.Advance(time.Millisecond + 1)
+
+	_, err := cache.Get("missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.EqualError(t, err, `key not found: "missing"`)
+	_, err = cache.Has("expired")
+	assert.ErrorIs(t, err, ErrExpired)
+	assert.EqualError(t, err, `key expired: "expired"`)
+	_, err = cache.GetBytes([]byte("missing"))
+	assert.EqualError(t, err, `key not found: "missing"`)
+	_, err = cache.EntryInfo("missing")
+	assert.EqualError(t, err, `key not found: "missing"`)
+	assert.EqualError(t, cache.Del("missing"), `key not found: "missing"`)
+	assert.EqualError(t, cache.Add("key", 2, 0), `key exists: "key"`)
+	assert.EqualError(t, cache.Replace("missing", 2, 0), `key not found: "missing"`)
+	_, err = cache.Snapshot().Get("missing")
+	assert.EqualError(t, err, `key not found: "missing"`)
+
+	require.NoError(t, cache.Close())
+	_, err = cache.Get("key")
+	assert.Equal(t, ErrClosed, err, "errors without a key are not wrapped")
+
+	// sentinels are returned as is by default
+	plain := NewCache[int]()
+	_, err = plain.Get("missing")
+	assert.Equal(t, ErrKeyNotFound, err)
+}
@@ -0,0 +1,49 @@
+package mcache
+
+import "sync"
+
+// keyLock is a mutex of a single key, shared by refs callers holding or waiting for it
+type keyLock struct {
+	sync.Mutex
+	refs int
+}
+
+// keyLocks is a set of per-key mutexes, a mutex exists only while it's held or waited for
+type keyLocks struct {
+	mu    sync.Mutex
+	locks map[string]*keyLock
+}
+
+// LockKey locks a mutex named by the key and returns the function unlocking it, which must be called once.
+// It doesn't lock the entry itself, the cache is usable as usual, but callers can serialize
+// expensive regeneration of a key, without a map of mutexes of their own:
+//
+//	unlock := cache.LockKey("report")
+//	defer unlock()
+//	if _, err := cache.Get("report"); err != nil {
+//		cache.Set("report", buildReport(), time.Hour)
+//	}
+func (c *Cache[T]) LockKey(key string) (unlock func()) {
+	l := &c.keyLocks
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*keyLock)
+	}
+	kl, ok := l.locks[key]
+	if !ok {
+		kl = &keyLock{}
+		l.locks[key] = kl
+	}
+	kl.refs++
+	l.mu.Unlock()
+
+	kl.Lock()
+	return func() {
+		kl.Unlock()
+		l.mu.Lock()
+		if kl.refs--; kl.refs == 0 {
+			delete(l.locks, key)
+		}
+		l.mu.Unlock()
+	}
+}
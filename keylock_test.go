@@ -0,0 +1,46 @@
+package mcache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockKey(t *testing.T) {
+	cache := NewCache[int]()
+
+	// callers of the same key are serialized
+	counter := 0
+	wg := sync.WaitGroup{}
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := cache.LockKey("key")
+			defer unlock()
+			counter++
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 100, counter)
+
+	// other keys and the cache itself are not blocked
+	unlock := cache.LockKey("key")
+	done := make(chan struct{})
+	go func() {
+		cache.LockKey("other")()
+		cache.Set("key", 1, 0)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("other key is blocked")
+	}
+	unlock()
+
+	// mutexes are released
+	assert.Empty(t, cache.keyLocks.locks)
+}
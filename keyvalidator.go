@@ -0,0 +1,38 @@
+package mcache
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidKey is returned for keys rejected by the validator set WithKeyValidator
+var ErrInvalidKey = errors.New("invalid key")
+
+// WithKeyValidator is a functional option for validating keys of every Set and its variants,
+// like limits of key length or allowed characters, so keys created from raw user input can't grow
+// the keyspace unbounded. Rejected Set returns false, TrySet returns ErrInvalidKey wrapping the error of fn.
+// Reads are validated too WithReadKeyValidation.
+func WithKeyValidator[T any](fn func(key string) error) func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.keyValidator = fn
+	}
+}
+
+// WithReadKeyValidation is a functional option for validating keys of Get, GetBytes and Has
+// with the validator set WithKeyValidator, invalid keys are not looked up
+func WithReadKeyValidation[T any]() func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.validateReads = true
+	}
+}
+
+// validateKey checks the key with the validator set WithKeyValidator
+func (c *Cache[T]) validateKey(key string) error {
+	if c.keyValidator == nil {
+		return nil
+	}
+	if err := c.keyValidator(key); err != nil {
+		return fmt.Errorf("%w %q: %w", ErrInvalidKey, key, err)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package mcache
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithKeyValidator(t *testing.T) {
+	tooLong := errors.New("too long")
+	validator := func(key string) error {
+		if len(key) > 8 {
+			return tooLong
+		}
+		return nil
+	}
+
+	cache := NewCache(WithKeyValidator[int](validator))
+	require.NoError(t, cache.TrySet("short", 1, 0))
+	err := cache.TrySet("very long key", 1, 0)
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	assert.ErrorIs(t, err, tooLong)
+	assert.False(t, cache.Set("very long key", 1, 0))
+	assert.False(t, cache.SetBytes([]byte("very long key"), 1, 0))
+	assert.False(t, cache.SetNegative("very long key", 0))
+	assert.Equal(t, 1, cache.Stats().Entries)
+	// reads are not validated by default
+	_, err = cache.Get("very long key")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	cache = NewCache(WithKeyValidator[int](validator), WithReadKeyValidation[int]())
+	_, err = cache.Get("very long key")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	_, err = cache.GetBytes([]byte("very long key"))
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	_, err = cache.Has("very long key")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	_, err = cache.Get("short")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.Equal(t, uint64(1), cache.Stats().Misses, "invalid keys are not looked up")
+}
@@ -0,0 +1,90 @@
+package mcache
+
+import (
+	"errors"
+	"time"
+)
+
+// WithMaxListLen is an option for limiting lists of a Cache[[]E] to n elements:
+// PushBack drops the oldest elements from the front of a longer list
+func WithMaxListLen[E any](n int) func(*Cache[[]E]) {
+	return func(c *Cache[[]E]) {
+		c.maxListLen = n
+	}
+}
+
+// PushBack appends elem to the list of the key, atomically. A live list keeps its TTL, a missing,
+// expired or negative key gets a new list expiring in ttl, with errors of TrySet.
+// Lists are appended in place, don't append to slices returned by Get, copy them or use ListRange.
+func PushBack[E any](c *Cache[[]E], key string, elem E, ttl time.Duration) error {
+	c.Lock()
+	defer c.Unlock()
+	item, err := c.lookupLocked(key)
+	if err != nil && !errors.Is(err, ErrKeyNotFound) && !errors.Is(err, ErrExpired) {
+		return err
+	}
+	if err != nil || item.negative {
+		return c.trySetLocked(key, []E{elem}, ttl, true)
+	}
+	list := append(item.value, elem)
+	if c.maxListLen > 0 && len(list) > c.maxListLen {
+		list = list[len(list)-c.maxListLen:]
+	}
+	item.value = list
+	c.setItem(key, item)
+	c.writeBack(key, pendingWrite[[]E]{value: list, ttl: c.remaining(item)})
+	return nil
+}
+
+// PopFront removes the first element of the list of the key and returns it, atomically.
+// The key is deleted with the last element, an empty list is ErrKeyNotFound, other errors are the same as for Update.
+func PopFront[E any](c *Cache[[]E], key string) (E, error) {
+	var elem E
+	c.Lock()
+	defer c.Unlock()
+	item, err := c.lookupLocked(key)
+	if err != nil {
+		return elem, err
+	}
+	if item.negative {
+		return elem, ErrNegativeCached
+	}
+	if len(item.value) == 0 {
+		return elem, c.keyError(ErrKeyNotFound, key)
+	}
+	elem = item.value[0]
+	if len(item.value) == 1 {
+		_ = c.delLocked(key)
+		c.writeBack(key, pendingWrite[[]E]{del: true})
+		return elem, nil
+	}
+	item.value = item.value[1:]
+	c.setItem(key, item)
+	c.writeBack(key, pendingWrite[[]E]{value: item.value, ttl: c.remaining(item)})
+	return elem, nil
+}
+
+// ListRange returns a copy of elements from index from up to, but not including, index to of the list
+// of the key. Negative indexes count from the end of the list, like -1 for the last element,
+// and indexes out of the list are clamped, so ListRange(c, key, 0, math.MaxInt) returns the whole list.
+// Errors are the same as for Get.
+func ListRange[E any](c *Cache[[]E], key string, from, to int) ([]E, error) {
+	list, err := c.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	// elements are only appended past the length of lists already stored, so they are copied without the lock
+	from, to = listIndex(from, len(list)), listIndex(to, len(list))
+	if from >= to {
+		return []E{}, nil
+	}
+	return append([]E(nil), list[from:to]...), nil
+}
+
+// listIndex converts a possibly negative index to a list of length n into one in [0, n]
+func listIndex(i, n int) int {
+	if i < 0 {
+		i += n
+	}
+	return min(max(i, 0), n)
+}
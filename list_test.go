@@ -0,0 +1,94 @@
+package mcache
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache/clocktest"
+)
+
+func TestList(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	cache := NewCache(WithClock[[]string](clock), WithMaxListLen[string](3))
+
+	require.NoError(t, PushBack(cache, "events", "a", time.Minute))
+	clock.Advance(30 * time.Second)
+	for _, e := range []string{"b", "c", "d"} {
+		require.NoError(t, PushBack(cache, "events", e, time.Hour))
+	}
+	list, err := ListRange(cache, "events", 0, math.MaxInt)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b", "c", "d"}, list, "the oldest element is dropped")
+	info, err := cache.EntryInfo("events")
+	require.NoError(t, err)
+	assert.True(t, clock.Now().Add(30*time.Second).Equal(info.Expiration), "TTL is kept")
+
+	list, err = ListRange(cache, "events", -2, -1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c"}, list)
+	list, err = ListRange(cache, "events", 2, 1)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+	_, err = ListRange(cache, "missing", 0, 1)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	for _, want := range []string{"b", "c", "d"} {
+		e, err := PopFront(cache, "events")
+		require.NoError(t, err)
+		assert.Equal(t, want, e)
+	}
+	_, err = PopFront(cache, "events")
+	assert.ErrorIs(t, err, ErrKeyNotFound, "the key is deleted with the last element")
+	require.True(t, cache.Set("empty", []string{}, 0))
+	_, err = PopFront(cache, "empty")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	require.True(t, cache.SetNegative("negative", 0))
+	_, err = PopFront(cache, "negative")
+	assert.ErrorIs(t, err, ErrNegativeCached)
+	require.NoError(t, PushBack(cache, "negative", "a", 0), "negative entry is replaced")
+
+	require.NoError(t, PushBack(cache, "expiring", "a", time.Second))
+	clock.Advance(2 * time.Second)
+	require.NoError(t, PushBack(cache, "expiring", "b", time.Second))
+	list, err = ListRange(cache, "expiring", 0, math.MaxInt)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, list, "expired list is replaced")
+
+	require.NoError(t, cache.Close())
+	assert.ErrorIs(t, PushBack(cache, "events", "a", 0), ErrClosed)
+	_, err = PopFront(cache, "events")
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestListConcurrent(t *testing.T) {
+	cache := NewCache[[]int]()
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, PushBack(cache, "list", i, 0))
+			_, err := ListRange(cache, "list", 0, math.MaxInt)
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+	list, err := ListRange(cache, "list", 0, math.MaxInt)
+	require.NoError(t, err)
+	assert.Len(t, list, 100)
+
+	seen := map[string]bool{}
+	for range list {
+		e, err := PopFront(cache, "list")
+		require.NoError(t, err)
+		seen[strconv.Itoa(e)] = true
+	}
+	assert.Len(t, seen, 100)
+	assert.Equal(t, 0, cache.Stats().Entries)
+}
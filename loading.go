@@ -0,0 +1,166 @@
+package mcache
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"time"
+)
+
+// Loader loads the value of a key missing in the cache, returns the value with its ttl
+type Loader[T any] func(ctx context.Context, key string) (T, time.Duration, error)
+
+// BulkLoader loads values of keys missing in the cache at once, like with a single SQL IN query.
+// Keys missing in the returned map don't exist.
+type BulkLoader[T any] func(ctx context.Context, keys []string) (map[string]T, error)
+
+// LoadingCache is a read-through Cache, its Get loads missing keys with the loader.
+// All methods of Cache but Get are available as is.
+type LoadingCache[T any] struct {
+	*Cache[T]
+	loader Loader[T]
+	loads  flightGroup[T]
+	errs   *Cache[error] // loader errors cached WithErrorTTL
+}
+
+// NewLoadingCache is a constructor for LoadingCache, options are the same as for NewCache
+func NewLoadingCache[T any](loader Loader[T], options ...func(*Cache[T])) *LoadingCache[T] {
+	l := &LoadingCache[T]{Cache: NewCache(options...), loader: loader}
+	if l.errorTTL > 0 {
+		l.errs = NewCache(WithClock[error](l.clock))
+	}
+	return l
+}
+
+// WithErrorTTL is a functional option for caching loader errors of LoadingCache for ttl,
+// so a failing upstream isn't hammered by every request: until the error expires,
+// Get of the key returns it without calling the loader. Errors are kept apart from values,
+// Has, Clear and other methods of Cache don't see them. A single Cache doesn't use it.
+func WithErrorTTL[T any](ttl time.Duration) func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.errorTTL = ttl
+	}
+}
+
+// WithBulkLoader is a functional option for loading missing keys of LoadingCache.GetMany
+// with a single call of the bulk loader, values are cached with ttl.
+// A single Cache doesn't use it.
+func WithBulkLoader[T any](bulk BulkLoader[T], ttl time.Duration) func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.bulkLoader, c.bulkTTL = bulk, ttl
+	}
+}
+
+// Get returns the value of the key, loading and caching it on a miss.
+// Concurrent Gets of the same missing key share a single loader call, made with ctx of the first of them.
+// Loader error is returned as is, and nothing is cached, but ErrKeyNotFound is cached
+// as a negative entry WithNegativeTTL, and other errors are cached WithErrorTTL and logged at warn level.
+// Loader panic is raised again in all Gets waiting for the call.
+func (l *LoadingCache[T]) Get(ctx context.Context, key string) (T, error) {
+	v, err := l.Cache.Get(key)
+	if err == nil || errors.Is(err, ErrClosed) || errors.Is(err, ErrNegativeCached) {
+		return v, err
+	}
+
+	return l.loads.do(key, func() (T, error) {
+		// the key may have been loaded by a call which has just finished
+		if item, err := l.lookup(key); err == nil {
+			if item.negative {
+				return item.value, ErrNegativeCached
+			}
+			return item.value, nil
+		}
+		if l.knownMissing(key) {
+			var none T
+			return none, l.keyError(ErrKeyNotFound, key)
+		}
+		if l.errs != nil {
+			if err, cached := l.errs.Get(key); cached == nil {
+				var none T
+				return none, l.keyError(err, key)
+			}
+		}
+		v, ttl, err := l.loader(ctx, key)
+		if err != nil {
+			l.loaded(key, err)
+			if l.errs != nil && (l.negativeTTL == 0 || !errors.Is(err, ErrKeyNotFound)) {
+				l.errs.Set(key, err, l.errorTTL)
+			}
+			return v, l.keyError(err, key)
+		}
+		l.Set(key, v, ttl)
+		return v, nil
+	})
+}
+
+// GetMany returns values of the keys, loading missing ones with a single call of the loader
+// set WithBulkLoader, or with the loader of every key without it. Keys which don't exist are left out.
+// Keys loaded by concurrent Get and GetMany calls are not loaded again, the calls are waited for.
+// Keys missing in the result of the bulk loader are cached as negative WithNegativeTTL,
+// bulk loader errors are returned with values found so far, and they are not cached WithErrorTTL.
+func (l *LoadingCache[T]) GetMany(ctx context.Context, keys []string) (map[string]T, error) {
+	values := make(map[string]T, len(keys))
+	var missing []string
+	for _, key := range keys {
+		v, err := l.Cache.Get(key)
+		switch {
+		case err == nil:
+			values[key] = v
+		case errors.Is(err, ErrClosed):
+			return nil, err
+		case errors.Is(err, ErrNegativeCached):
+		case !l.knownMissing(key):
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return values, nil
+	}
+
+	if l.bulkLoader == nil {
+		var errs error
+		for _, key := range missing {
+			v, err := l.Get(ctx, key)
+			switch {
+			case err == nil:
+				values[key] = v
+			case errors.Is(err, ErrKeyNotFound), errors.Is(err, ErrNegativeCached):
+			case errs == nil:
+				errs = err
+			}
+		}
+		return values, errs
+	}
+
+	loaded, err := l.loads.doMany(missing, func(keys []string) (map[string]T, error) {
+		// keys may have been loaded by calls which have just finished
+		found := make(map[string]T, len(keys))
+		var load []string
+		for _, key := range keys {
+			if item, err := l.lookup(key); err != nil {
+				load = append(load, key)
+			} else if !item.negative {
+				found[key] = item.value
+			}
+		}
+		if len(load) == 0 {
+			return found, nil
+		}
+		loaded, err := l.bulkLoader(ctx, load)
+		if err != nil {
+			l.logger.Warn("mcache: bulk loader failed", "keys", len(load), "err", err)
+			return nil, err
+		}
+		for _, key := range load {
+			if v, ok := loaded[key]; ok {
+				l.Set(key, v, l.bulkTTL)
+				found[key] = v
+			} else {
+				l.loaded(key, ErrKeyNotFound)
+			}
+		}
+		return found, nil
+	})
+	maps.Copy(values, loaded)
+	return values, err
+}
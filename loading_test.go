@@ -0,0 +1,237 @@
+package mcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache/clocktest"
+)
+
+func TestLoadingCache(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	cache := NewLoadingCache(func(ctx context.Context, key string) (string, time.Duration, error) {
+		calls.Add(1)
+		<-release
+		if key == "bad" {
+			return "", 0, errors.New("failed")
+		}
+		return "value of " + key, time.Hour, nil
+	}, WithEntryStats[string]())
+
+	// concurrent misses share a single load
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := cache.Get(context.Background(), "key")
+			assert.NoError(t, err)
+			assert.Equal(t, "value of key", v)
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), calls.Load())
+
+	// loaded value is cached with its ttl
+	v, err := cache.Get(context.Background(), "key")
+	assert.NoError(t, err)
+	assert.Equal(t, "value of key", v)
+	assert.Equal(t, int32(1), calls.Load())
+	info, err := cache.EntryInfo("key")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), info.Expiration, time.Second)
+
+	// errors are not cached
+	_, err = cache.Get(context.Background(), "bad")
+	assert.EqualError(t, err, "failed")
+	_, err = cache.Get(context.Background(), "bad")
+	assert.Error(t, err)
+	assert.Equal(t, int32(3), calls.Load())
+	has, _ := cache.Has("bad")
+	assert.False(t, has)
+
+	require.NoError(t, cache.Close())
+	_, err = cache.Get(context.Background(), "key")
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestLoadingCacheWithErrorTTL(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	var calls atomic.Int32
+	fail := errors.New("upstream is down")
+	cache := NewLoadingCache(func(ctx context.Context, key string) (string, time.Duration, error) {
+		if calls.Add(1) == 1 {
+			return "", 0, fail
+		}
+		return "value", 0, nil
+	}, WithErrorTTL[string](time.Second), WithClock[string](clock))
+
+	for i := 0; i < 3; i++ {
+		_, err := cache.Get(context.Background(), "key")
+		assert.ErrorIs(t, err, fail)
+	}
+	assert.Equal(t, int32(1), calls.Load())
+	has, _ := cache.Has("key")
+	assert.False(t, has, "error is not a value")
+
+	clock.Advance(2 * time.Second)
+	v, err := cache.Get(context.Background(), "key")
+	require.NoError(t, err)
+	assert.Equal(t, "value", v)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestLoadingCacheGetMany(t *testing.T) {
+	release := make(chan struct{})
+	loader := func(_ context.Context, key string) (string, time.Duration, error) {
+		<-release
+		return "slow value", time.Hour, nil
+	}
+	var bulkCalls [][]string
+	bulk := func(_ context.Context, keys []string) (map[string]string, error) {
+		bulkCalls = append(bulkCalls, keys)
+		values := map[string]string{}
+		for _, key := range keys {
+			switch key {
+			case "bad":
+				return nil, errors.New("failed")
+			case "missing":
+			default:
+				values[key] = "value of " + key
+			}
+		}
+		return values, nil
+	}
+	cache := NewLoadingCache(loader, WithBulkLoader[string](bulk, time.Hour), WithNegativeTTL[string](time.Minute))
+	cache.Set("cached", "cached value", 0)
+
+	// a key being loaded by Get is waited for, not loaded again
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		v, err := cache.Get(context.Background(), "slow")
+		assert.NoError(t, err)
+		assert.Equal(t, "slow value", v)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+	}()
+
+	values, err := cache.GetMany(context.Background(), []string{"cached", "a", "b", "missing", "slow", "a"})
+	require.NoError(t, err)
+	<-done
+	assert.Equal(t, map[string]string{
+		"cached": "cached value",
+		"a":      "value of a",
+		"b":      "value of b",
+		"slow":   "slow value",
+	}, values)
+	require.Len(t, bulkCalls, 1)
+	assert.ElementsMatch(t, []string{"a", "b", "missing"}, bulkCalls[0])
+
+	// loaded keys are cached, missing ones are cached as negative
+	values, err = cache.GetMany(context.Background(), []string{"a", "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "value of a"}, values)
+	assert.Len(t, bulkCalls, 1)
+
+	values, err = cache.GetMany(context.Background(), []string{"b", "bad"})
+	assert.EqualError(t, err, "failed")
+	assert.Equal(t, map[string]string{"b": "value of b"}, values)
+
+	// without a bulk loader keys are loaded one by one
+	plain := NewLoadingCache(func(_ context.Context, key string) (int, time.Duration, error) {
+		if key == "missing" {
+			return 0, 0, ErrKeyNotFound
+		}
+		return len(key), 0, nil
+	})
+	numbers, err := plain.GetMany(context.Background(), []string{"one", "three", "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"one": 3, "three": 5}, numbers)
+
+	require.NoError(t, cache.Close())
+	_, err = cache.GetMany(context.Background(), []string{"a"})
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestLoadingCacheLoaderPanic(t *testing.T) {
+	release := make(chan struct{})
+	cache := NewLoadingCache(func(ctx context.Context, key string) (string, time.Duration, error) {
+		<-release
+		panic("loader bug")
+	})
+
+	// the panic is raised in the caller which ran the loader and in all waiters
+	var panics atomic.Int32
+	wg := sync.WaitGroup{}
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					assert.Contains(t, fmt.Sprint(r), "loader bug")
+					panics.Add(1)
+				}
+			}()
+			_, _ = cache.Get(context.Background(), "key")
+			t.Error("panic expected")
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(5), panics.Load())
+
+	bulk := NewLoadingCache(func(ctx context.Context, key string) (string, time.Duration, error) {
+		return "", 0, nil
+	}, WithBulkLoader[string](func(_ context.Context, keys []string) (map[string]string, error) {
+		panic("bulk loader bug")
+	}, time.Hour))
+	assert.Panics(t, func() { _, _ = bulk.GetMany(context.Background(), []string{"a", "b"}) })
+	_, err := bulk.Cache.Get("a")
+	assert.ErrorIs(t, err, ErrKeyNotFound, "nothing cached")
+}
+
+func TestLoadingCacheLogsLoaderErrors(t *testing.T) {
+	buf := &syncBuffer{}
+	logger := slog.New(slog.NewTextHandler(buf, nil))
+	cache := NewLoadingCache(func(ctx context.Context, key string) (string, time.Duration, error) {
+		if key == "missing" {
+			return "", 0, ErrKeyNotFound
+		}
+		return "", 0, errors.New("upstream is down")
+	}, WithLogger[string](logger), WithBulkLoader[string](func(_ context.Context, keys []string) (map[string]string, error) {
+		return nil, errors.New("bulk upstream is down")
+	}, time.Hour))
+
+	_, err := cache.Get(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.Empty(t, buf.String(), "missing key is not a failure")
+
+	_, err = cache.Get(context.Background(), "key")
+	assert.Error(t, err)
+	assert.Contains(t, buf.String(), "level=WARN")
+	assert.Contains(t, buf.String(), "loader failed")
+	assert.Contains(t, buf.String(), "upstream is down")
+
+	_, err = cache.GetMany(context.Background(), []string{"a", "b"})
+	assert.Error(t, err)
+	assert.Contains(t, buf.String(), "bulk upstream is down")
+}
@@ -0,0 +1,26 @@
+package mcache
+
+import (
+	"context"
+	"log/slog"
+)
+
+// evictWarnRatio is a share of entries removed by a single Cleanup run,
+// above which the run is logged as a warning, as it usually means TTLs are too short.
+const evictWarnRatio = 0.5
+
+// discardHandler is a slog.Handler dropping all records, used when WithLogger is not set
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return d }
+func (d discardHandler) WithGroup(string) slog.Handler           { return d }
+
+// WithLogger is a functional option for setting a logger. Cache logs cleanup runs at debug level,
+// and cleanup runs evicting more than half of the entries at warn level.
+func WithLogger[T any](logger *slog.Logger) func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.logger = logger
+	}
+}
@@ -0,0 +1,30 @@
+package mcache
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithLogger(t *testing.T) {
+	buf := bytes.Buffer{}
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	cache := NewCache(WithLogger[string](logger))
+
+	cache.Set("key", "value", 0)
+	cache.Cleanup()
+	assert.Contains(t, buf.String(), "level=DEBUG msg=\"mcache cleanup\" removed=0 entries=1")
+
+	buf.Reset()
+	cache.Set("expired", "value", time.Millisecond)
+	cache.Set("expired2", "value", time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	cache.Cleanup()
+	assert.Contains(t, buf.String(), "level=WARN msg=\"mcache cleanup\" removed=2 entries=1")
+
+	// default logger discards everything
+	NewCache[string]().Cleanup()
+}
@@ -3,8 +3,11 @@
 package mcache
 
 import (
+	"context"
 	"errors"
+	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -12,19 +15,114 @@ import (
 var (
 	ErrKeyNotFound = errors.New("key not found")
 	ErrExpired     = errors.New("key expired")
+	ErrClosed      = errors.New("cache closed")
+	ErrKeyExists   = errors.New("key exists")
+	ErrCacheFull   = errors.New("cache full")
 )
 
-// CacheItem is a struct for cache item.
+// CacheItem is a struct for cache item. Items are stored in the map by value,
+// so there are no per-entry pointers for the GC to trace, unless optional features need them.
 type CacheItem[T any] struct {
 	value      T
-	expiration time.Time
+	expiration int64       // nanoseconds since the cache epoch, 0 if item doesn't expire
+	meta       *entryMeta  // access metadata, if WithEntryStats is set
+	node       *expiryNode // position in expiration index, if WithExpirationHeap or WithTimingWheel is set
+	negative   bool        // key is cached as missing, see SetNegative
+	version    uint64      // assigned when the item is stored, see GetVersioned
 }
 
+// entryMeta is access metadata of an item, updated under the read lock
+type entryMeta struct {
+	created  time.Time
+	accessed atomic.Int64 // unix nanoseconds
+	hits     atomic.Uint64
+	priority Priority // set under the write lock
+}
+
+// EntryInfo is access metadata of a single cache entry.
+// Created, LastAccess and Hits are only tracked when the cache is created WithEntryStats.
+type EntryInfo struct {
+	Created    time.Time
+	LastAccess time.Time
+	Hits       uint64
+	Expiration time.Time // zero if entry doesn't expire
+}
+
+// cleanupChunk is a number of entries scanned under a single lock by CleanupWithBudget
+const cleanupChunk = 1000
+
 // Cache is a struct for cache.
 type Cache[T any] struct {
-	initialSize int
-	data        map[string]*CacheItem[T]
+	initialSize    int
+	entryStats     bool
+	inPlaceCleanup bool
+	cleanupSamples int
+	expiry         expiryIndex
+	logger         *slog.Logger
+	metrics        MetricsSink
+	codec          Codec[T]
+	compression    Compression
+	persistPath    string
+	restorePath    string // WithRestore, persistPath by default
+	strictRestore  bool   // restore error is returned by WaitWarmup
+	restoreErr     error
+	walPath        string
+	wal            *wal[T]
+	bg             background
+	keyLocks       keyLocks
+	closed         bool
+	hasher         func(key string) uint64
+	clock          Clock
+	epoch          time.Time // expirations are stored as time since epoch
+	coarse         bool
+	coarseNow      atomic.Int64 // time since epoch updated in background WithCoarseTime
+	noExpiration   bool
+	generations    bool
+	invalidation   *invalidation
+	replicas       []*Replication[T]
+	backing        Store[T]
+	loads          flightGroup[T] // loads from the backing store WithReadThrough
+	wb             *writeBehind[T]
+	maxEntries     int
+	fullPolicy     FullPolicy
+	keyValidator   func(key string) error
+	validateReads  bool
+	keyErrors      bool // WithKeyErrors
+	keepExpired    bool // WithLazyDeletion(false)
+	copier         func(T) T
+	indexes        map[string]*valueIndex[T] // WithIndex
+	ordered        *orderedKeys              // WithOrderedKeys
+	insertion      *insertionOrder           // WithInsertionOrder
+	setTTLs        *ttlCounters              // WithTTLHistogram
+	lockStats      bool
+	deepSizing     bool
+	indexing       bool // indexes or ordered keys are updated on writes
+	overflow       Store[T]
+	spilled        map[string]int64 // expirations of entries spilled WithOverflow
+	negative       *bloomFilter     // keys missing in the backing store WithNegativeLookupFilter
+	negativeTTL    time.Duration
+	errorTTL       time.Duration
+	bulkLoader     BulkLoader[T] // WithBulkLoader
+	bulkTTL        time.Duration
+	maxListLen     int    // WithMaxListLen
+	version        uint64 // the last version assigned to a stored item
+	warmup         *warmup[T]
+	deps           depGraph
+	pinned         map[string]bool         // pinned keys, true if they don't expire
+	lowPriority    map[string]struct{}     // keys set WithPriority(PriorityLow), evicted first
+	old            map[string]CacheItem[T] // old generation WithGenerations
+	cow            bool
+	syncMap        bool
+	smap           atomic.Pointer[sync.Map]                // copy of data for lock-free reads WithSyncMapBackend
+	dirty          bool                                    // data is not published yet WithCopyOnWrite
+	view           atomic.Pointer[map[string]CacheItem[T]] // published snapshot WithCopyOnWrite
+	data           map[string]CacheItem[T]
 	sync.RWMutex
+
+	hits, misses, evictions atomic.Uint64
+	cleanups                atomic.Uint64
+	cleanupTime             atomic.Int64
+	lockWait                atomic.Int64
 }
 
 // Cacher is an interface for cache.
@@ -40,143 +138,563 @@ type Cacher[T any] interface {
 // NewCache is a constructor for Cache.
 func NewCache[T any](options ...func(*Cache[T])) *Cache[T] {
 	c := &Cache[T]{
-		data: make(map[string]*CacheItem[T]),
+		data:    make(map[string]CacheItem[T]),
+		clock:   systemClock{},
+		logger:  slog.New(discardHandler{}),
+		metrics: NoopMetrics{},
+		codec:   GobCodec[T]{},
 	}
 
 	for _, option := range options {
 		option(c)
 	}
+	c.epoch = c.clock.Now()
+
+	if c.restorePath == "" {
+		c.restorePath = c.persistPath
+	}
+	if c.restorePath != "" {
+		c.restore()
+	}
+	if c.walPath != "" {
+		c.openWAL()
+	}
+	if c.cow {
+		c.publish()
+	}
+	c.startTasks()
+	if c.wb != nil && c.wb.store == nil {
+		c.wb = nil // only error handler is set
+	}
+	if c.wb != nil {
+		c.startWriteBehind()
+	}
+	if c.invalidation != nil {
+		c.subscribe()
+	}
+	if c.warmup != nil {
+		c.startWarmup()
+	}
 
 	return c
 }
 
-// common method for checking if item is expired
-func (cacheItem CacheItem[T]) expired() bool {
-	if !cacheItem.expiration.IsZero() && cacheItem.expiration.Before(time.Now()) {
+// common method for checking if item is expired at the given time since the cache epoch
+func (cacheItem CacheItem[T]) expiredAt(now int64) bool {
+	if cacheItem.expiration != 0 && cacheItem.expiration < now {
 		return true
 	}
 	return false
 }
 
-// Set is a method for setting key-value pair.
-// If key already exists, and it's not expired, return false.
-// If key already exists, but it's expired, set new value and return true.
-// If key doesn't exist, set new value and return true.
+// newItem creates an item expiring at expiration (zero time for no expiration)
+func (c *Cache[T]) newItem(value T, expiration time.Time) CacheItem[T] {
+	item := CacheItem[T]{value: value}
+	if !expiration.IsZero() && !c.noExpiration {
+		item.expiration = c.deadline(expiration)
+	}
+	if c.entryStats {
+		item.meta = &entryMeta{created: c.clock.Now()}
+	}
+	return item
+}
+
+// Set is a method for setting key-value pair, replacing the value of an existing key.
 // If ttl is 0, set value without expiration.
+// Returns false if the value isn't set: cache is closed, the key is rejected WithKeyValidator,
+// or the cache is full WithFullPolicy(Reject), TrySet returns the reason.
+// Add sets the value only if the key isn't live, Swap returns the replaced value.
 func (c *Cache[T]) Set(key string, value T, ttl time.Duration) bool {
+	return c.TrySet(key, value, ttl) == nil
+}
+
+// TrySet is Set returning the reason the value isn't set: ErrCacheFull when the cache is full
+// WithFullPolicy(Reject), ErrInvalidKey for a key rejected WithKeyValidator, and ErrClosed.
+func (c *Cache[T]) TrySet(key string, value T, ttl time.Duration) error {
+	c.Lock()
+	defer c.Unlock()
+	return c.trySetLocked(key, value, ttl, true)
+}
+
+// Add sets the value only if the key isn't live (SETNX), returns ErrKeyExists for a live key,
+// ErrCacheFull when the cache is full WithFullPolicy(Reject), ErrInvalidKey for a key rejected
+// WithKeyValidator, and ErrClosed. Expired and negative entries are replaced.
+// Only one of concurrent Add calls of a key succeeds, and the key stays taken until it's deleted
+// or expires, so it can be used as a lease: a lock held for ttl at most.
+func (c *Cache[T]) Add(key string, value T, ttl time.Duration) error {
 	c.Lock()
 	defer c.Unlock()
+	return c.trySetLocked(key, value, ttl, false)
+}
+
+// trySetLocked is TrySet under the write lock, a live key is replaced only if replace is set
+func (c *Cache[T]) trySetLocked(key string, value T, ttl time.Duration, replace bool) error {
+	if c.closed {
+		return ErrClosed
+	}
+	if err := c.validateKey(key); err != nil {
+		return err
+	}
 	cached, ok := c.data[key]
+	if !ok {
+		cached, ok = c.old[key]
+	}
 	if ok {
-		if !cached.expired() {
-			return false
+		if !replace && !c.expired(cached) && !cached.negative {
+			return c.keyError(ErrKeyExists, key)
+		}
+	} else if c.spilled != nil && c.spilledLive(key) {
+		if !replace {
+			return c.keyError(ErrKeyExists, key)
 		}
+	} else if c.full() {
+		return ErrCacheFull
 	}
+	c.setLocked(key, value, ttl)
+	c.writeBack(key, pendingWrite[T]{value: value, ttl: ttl})
+	return nil
+}
 
-	var expiration time.Time
+// setLocked stores the new item of the key under the write lock
+func (c *Cache[T]) setLocked(key string, value T, ttl time.Duration) {
+	c.setItem(key, c.ttlItem(value, ttl, false))
+}
 
-	if ttl > time.Duration(0) {
-		expiration = time.Now().Add(ttl)
+// ttlItem creates an item expiring in ttl (0 for no expiration)
+func (c *Cache[T]) ttlItem(value T, ttl time.Duration, negative bool) CacheItem[T] {
+	if c.setTTLs != nil {
+		c.setTTLs.observe(ttl)
 	}
+	item := c.newItem(value, time.Time{})
+	if ttl > time.Duration(0) && !c.noExpiration {
+		item.expiration = max(c.now()+int64(ttl), 1)
+	}
+	item.negative = negative
+	return item
+}
 
-	c.data[key] = &CacheItem[T]{
-		value:      value,
-		expiration: expiration,
+// setItem stores the item of the key under the write lock
+func (c *Cache[T]) setItem(key string, item CacheItem[T]) {
+	if c.pinned[key] {
+		item.expiration = 0
+	}
+	c.changed(key)
+	c.store(key, item)
+	c.logSet(key, item)
+	if c.cleanupSamples > 0 {
+		c.expireSample()
+	}
+}
+
+// expireSample deletes expired entries among cleanupSamples entries checked, must be called under lock.
+// Map iteration starts at a random position, so each call checks a different part of the map.
+func (c *Cache[T]) expireSample() {
+	n, removed, now := 0, 0, c.now()
+	for k, v := range c.data {
+		if n == c.cleanupSamples {
+			break
+		}
+		n++
+		if v.expiredAt(now) {
+			c.remove(k, v)
+			removed++
+		}
+	}
+	if removed > 0 {
+		c.evicted(removed)
 	}
-	return true
 }
 
 // Get is a method for getting value by key.
 // If key doesn't exist, return error.
 // If key exists, but it's expired, delete key, return zero value and error.
 // If key exists and it's not expired, return value.
+// If key is cached as missing with SetNegative, return zero value and ErrNegativeCached.
+// Live keys are read under the read lock, so concurrent Gets don't block each other,
+// write lock is taken only to delete an expired key, unless WithLazyDeletion(false) is set.
+// WithReadThrough missing and expired keys are loaded from the store.
 func (c *Cache[T]) Get(key string) (T, error) {
-	var none T
+	if c.validateReads {
+		if err := c.validateKey(key); err != nil {
+			var none T
+			return none, err
+		}
+	}
+	item, err := c.lookup(key)
+	if err != nil && err != ErrClosed && err != ErrNegativeCached && c.backing != nil {
+		c.miss()
+		return c.readThrough(key)
+	}
+	return c.get(item, err)
+}
+
+// get returns the value of the item found by lookup, counting the hit or miss
+func (c *Cache[T]) get(item CacheItem[T], err error) (T, error) {
+	if err != nil {
+		if err != ErrClosed {
+			c.miss()
+		}
+		var none T
+		return none, err
+	}
+
+	c.hit()
+	c.touch(item)
+	if item.negative {
+		return item.value, ErrNegativeCached
+	}
+	return item.value, nil
+}
+
+// lookup returns the live item of the key, with a single map lookup on the fast path.
+// Expired item is deleted under the write lock, unless the key was set again meanwhile.
+// Items are stored by value and never modified in place, so the copy can be used without the lock.
+func (c *Cache[T]) lookup(key string) (CacheItem[T], error) {
+	if c.cow {
+		return c.lookupView(key)
+	}
+	if c.syncMap {
+		return c.lookupSyncMap(key)
+	}
+	c.RLock()
+	if c.closed {
+		c.RUnlock()
+		return CacheItem[T]{}, ErrClosed
+	}
+	item, ok := c.data[key]
+	old := false
+	if !ok {
+		// promoted from the old generation or reloaded from overflow under the write lock
+		item, old = c.old[key]
+		if !old && c.spilled != nil {
+			_, old = c.spilled[key]
+		}
+	}
+	c.RUnlock()
+
+	if !ok && !old {
+		return CacheItem[T]{}, c.keyError(ErrKeyNotFound, key)
+	}
+	if ok && !c.expired(item) {
+		return item, nil
+	}
+	if c.keepExpired && c.expired(item) {
+		return CacheItem[T]{}, c.keyError(ErrExpired, key)
+	}
 
 	c.Lock()
 	defer c.Unlock()
+	return c.lookupLocked(key)
+}
+
+// lookupLocked is lookup under the write lock, expired item is deleted
+func (c *Cache[T]) lookupLocked(key string) (CacheItem[T], error) {
+	if c.closed {
+		return CacheItem[T]{}, ErrClosed
+	}
+	item, ok := c.data[key]
+	if !ok {
+		if item, ok = c.old[key]; ok {
+			c.promote(key, item)
+		}
+	}
+	if !ok && c.spilled != nil {
+		return c.unspill(key)
+	}
+	if !ok {
+		return CacheItem[T]{}, c.keyError(ErrKeyNotFound, key)
+	}
+	if c.expired(item) {
+		c.remove(key, item)
+		c.evicted(1)
+		return CacheItem[T]{}, c.keyError(ErrExpired, key)
+	}
+	return item, nil
+}
+
+// touch records an access to the item, if WithEntryStats is set, safe under the read lock
+func (c *Cache[T]) touch(item CacheItem[T]) {
+	if item.meta != nil {
+		item.meta.accessed.Store(c.clock.Now().UnixNano())
+		item.meta.hits.Add(1)
+	}
+}
+
+// EntryInfo returns access metadata for the key.
+// Errors are the same as for Has, expired key is not deleted.
+// Only Get counts as an access, Has and EntryInfo don't.
+func (c *Cache[T]) EntryInfo(key string) (EntryInfo, error) {
+	c.RLock()
+	defer c.RUnlock()
 
 	item, ok := c.data[key]
 	if !ok {
-		return none, ErrKeyNotFound
+		item, ok = c.old[key]
+	}
+	if !ok {
+		return EntryInfo{}, c.keyError(ErrKeyNotFound, key)
 	}
 
-	if item.expired() {
-		delete(c.data, key)
-		return none, ErrExpired
+	if c.expired(item) {
+		return EntryInfo{}, c.keyError(ErrExpired, key)
 	}
 
-	return c.data[key].value, nil
+	info := EntryInfo{Expiration: c.expirationTime(item)}
+	if item.meta != nil {
+		info.Created = item.meta.created
+		info.Hits = item.meta.hits.Load()
+		if accessed := item.meta.accessed.Load(); accessed != 0 {
+			info.LastAccess = time.Unix(0, accessed)
+		}
+	}
+	return info, nil
 }
 
 // Has checks if key exists and if it's expired.
 // If key doesn't exist, return false.
 // If key exists, but it's expired, return false and delete key.
 // If key exists and it's not expired, return true.
+// Like Get, it takes the write lock only to delete an expired key, see WithLazyDeletion.
 func (c *Cache[T]) Has(key string) (bool, error) {
-	c.Lock()
-	defer c.Unlock()
-
-	item, ok := c.data[key]
-	if !ok {
-		return false, ErrKeyNotFound
+	if c.validateReads {
+		if err := c.validateKey(key); err != nil {
+			return false, err
+		}
 	}
-
-	if item.expired() {
-		delete(c.data, key)
-		return false, ErrExpired
+	item, err := c.lookup(key)
+	if err != nil {
+		return false, err
+	}
+	if item.negative {
+		return false, ErrNegativeCached
 	}
-
 	return true, nil
 }
 
+// Exists checks if the key is live without side effects, unlike Has: expired key is not deleted,
+// entries of the old generation are not promoted and spilled ones are not reloaded WithOverflow,
+// and nothing is counted as an access. Negative entries don't exist, closed cache has no keys.
+func (c *Cache[T]) Exists(key string) bool {
+	c.RLock()
+	defer c.RUnlock()
+	if c.closed {
+		return false
+	}
+	if c.present(key) {
+		return c.live(key, c.now())
+	}
+	return c.spilled != nil && c.spilledLive(key)
+}
+
 // Del deletes a key-value pair.
+// If key doesn't exist, return ErrKeyNotFound.
+// If key exists, but it's expired, delete key and return ErrExpired.
+// WithInvalidator the deletion is published, unless the cache is closed.
 func (c *Cache[T]) Del(key string) error {
-	_, err := c.Has(key)
+	c.Lock()
+	err := c.delLocked(key)
+	if err != ErrClosed {
+		c.writeBack(key, pendingWrite[T]{del: true})
+	}
+	c.Unlock()
+	if err != ErrClosed {
+		c.broadcast(Invalidation{Key: key})
+	}
+	return err
+}
+
+// delLocked is Del under the write lock
+func (c *Cache[T]) delLocked(key string) error {
+	item, err := c.lookupLocked(key)
+	if err != ErrClosed {
+		c.changed(key)
+	}
 	if err != nil {
 		return err
 	}
-
-	// parallel goroutine can delete key right here
-	// or even perform Clear() operation
-	// but it doen't matter
-
-	c.Lock()
-	delete(c.data, key)
-	c.Unlock()
+	c.remove(key, item)
+	c.logDel(key)
 	return nil
 }
 
 // Clears cache by replacing it with a clean one.
 func (c *Cache[T]) Clear() error {
 	c.Lock()
-	c.data = make(map[string]*CacheItem[T], c.initialSize)
-	c.Unlock()
+	defer c.Unlock()
+	return c.clearLocked()
+}
+
+// clearLocked is Clear under the write lock
+func (c *Cache[T]) clearLocked() error {
+	if c.closed {
+		return ErrClosed
+	}
+	c.replaceData(make(map[string]CacheItem[T], c.initialSize))
+	c.old = nil
+	if c.expiry != nil {
+		c.expiry.clear()
+	}
+	c.deps = depGraph{}
+	c.lowPriority = nil
+	for _, ix := range c.indexes {
+		clear(ix.keys)
+	}
+	if c.ordered != nil {
+		c.ordered.reset()
+	}
+	if c.insertion != nil {
+		c.insertion.reset()
+	}
+	if c.negative != nil {
+		c.negative.reset()
+	}
+	if c.spilled != nil {
+		c.dropSpilledWhere(func(string) bool { return true })
+	}
+	if len(c.replicas) > 0 {
+		c.replicate(replicaEvent[T]{clear: true})
+	}
+	if c.wal != nil {
+		return c.wal.rewrite(c)
+	}
 	return nil
 }
 
-// Cleanup deletes expired keys from cache by copying non-expired keys to a new map.
+// Cleanup deletes expired keys from cache by copying non-expired keys to a new map,
+// or in place, if WithInPlaceCleanup is set. With WithExpirationHeap or WithTimingWheel
+// only expired keys are popped from the index, without scanning the whole map.
 func (c *Cache[T]) Cleanup() {
+	c.CleanupN()
+}
+
+// CleanupN is the same as Cleanup, but returns the number of removed entries and the time it took.
+// Both are also counted in Stats.
+func (c *Cache[T]) CleanupN() (removed int, took time.Duration) {
+	start := time.Now()
 	c.Lock()
 	defer c.Unlock()
-	data := make(map[string]*CacheItem[T], c.initialSize)
+	if c.closed {
+		return 0, 0
+	}
+	if c.spilled != nil {
+		c.dropSpilledWhere(nil)
+	}
+	if c.generations {
+		removed = c.rotate()
+		took = time.Since(start)
+		c.cleaned(removed, took)
+		c.logCleanup(removed, len(c.data)+len(c.old), took)
+		return removed, took
+	}
+	if c.noExpiration {
+		took = time.Since(start)
+		c.cleaned(0, took)
+		return 0, took
+	}
+	if c.expiry != nil || c.inPlaceCleanup {
+		if c.expiry != nil {
+			removed = c.popExpired()
+		} else {
+			now := c.now()
+			for k, v := range c.data {
+				if v.expiredAt(now) {
+					c.remove(k, v)
+					removed++
+				}
+			}
+		}
+		took = time.Since(start)
+		c.cleaned(removed, took)
+		c.logCleanup(removed, len(c.data), took)
+		return removed, took
+	}
+
+	data := make(map[string]CacheItem[T], c.initialSize)
+	now := c.now()
 	for k, v := range c.data {
-		if !v.expired() {
+		if !v.expiredAt(now) {
 			data[k] = v
+			continue
+		}
+		if c.indexing {
+			c.unindex(k, v)
+		}
+		c.forget(k)
+	}
+	removed = len(c.data) - len(data)
+	c.replaceData(data)
+	took = time.Since(start)
+	c.cleaned(removed, took)
+	c.logCleanup(removed, len(data), took)
+	return removed, took
+}
+
+// CleanupWithBudget deletes expired keys in place, in chunks of cleanupChunk entries,
+// releasing the lock between chunks, so readers are never blocked for long.
+// It stops when roughly all entries are scanned or when maxDuration is exceeded.
+// Every chunk starts at a random position of the map, so a run limited by the budget checks
+// a random part of the keyspace, and consecutive runs eventually cover all of it.
+// Returns the number of removed entries and the time it took, both are also counted in Stats.
+func (c *Cache[T]) CleanupWithBudget(maxDuration time.Duration) (removed int, took time.Duration) {
+	start := time.Now()
+	scanned, entries, total := 0, 0, -1
+	for {
+		c.Lock()
+		if c.closed {
+			c.Unlock()
+			return removed, time.Since(start)
+		}
+		if total < 0 {
+			total = len(c.data)
+		}
+		n, now := 0, c.now()
+		for k, v := range c.data {
+			if n == cleanupChunk {
+				break
+			}
+			n++
+			if v.expiredAt(now) {
+				c.remove(k, v)
+				removed++
+			}
+		}
+		scanned += n
+		entries = len(c.data)
+		c.Unlock()
+
+		took = time.Since(start)
+		if scanned >= total || took >= maxDuration {
+			break
 		}
 	}
-	c.data = data
+	c.cleaned(removed, took)
+	c.logCleanup(removed, entries, took)
+	return removed, took
+}
+
+// logCleanup logs cleanup run results, at warn level if too many entries were removed
+func (c *Cache[T]) logCleanup(removed, entries int, took time.Duration) {
+	level := slog.LevelDebug
+	if removed > 0 && float64(removed) > evictWarnRatio*float64(removed+entries) {
+		level = slog.LevelWarn
+	}
+	c.logger.Log(context.Background(), level, "mcache cleanup",
+		"removed", removed, "entries", entries, "took", took)
 }
 
 // WithCleanup is a functional option for setting interval to run Cleanup goroutine.
+// Goroutine is stopped by Close. Interval <= 0 doesn't start it.
 func WithCleanup[T any](ttl time.Duration) func(*Cache[T]) {
 	return func(c *Cache[T]) {
-		go func() {
-			for {
-				c.Cleanup()
-				time.Sleep(ttl)
-			}
-		}()
+		c.addTask(ttl, c.Cleanup)
+	}
+}
+
+// WithCleanupContext is the same as WithCleanup, but the goroutine also exits when ctx is cancelled,
+// so it can be bound to the service lifetime.
+func WithCleanupContext[T any](ctx context.Context, interval time.Duration) func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.addTaskContext(ctx, interval, c.Cleanup)
 	}
 }
 
@@ -184,7 +702,53 @@ func WithCleanup[T any](ttl time.Duration) func(*Cache[T]) {
 // go will allocate appropriate number of buckets.
 func WithSize[T any](size int) func(*Cache[T]) {
 	return func(c *Cache[T]) {
-		c.data = make(map[string]*CacheItem[T], size)
+		c.data = make(map[string]CacheItem[T], size)
 		c.initialSize = size
 	}
 }
+
+// WithEntryStats is a functional option for tracking creation time, last access time
+// and hit count of every entry, available with EntryInfo. Disabled by default to avoid overhead.
+func WithEntryStats[T any]() func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.entryStats = true
+	}
+}
+
+// WithInPlaceCleanup is a functional option for deleting expired keys in place on Cleanup,
+// instead of copying live keys to a new map. It doesn't need memory for the second map,
+// and keeps buckets preallocated WithSize, but the map never shrinks, so it's the better choice
+// when only a small fraction of entries expires between cleanups.
+func WithInPlaceCleanup[T any]() func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.inPlaceCleanup = true
+	}
+}
+
+// WithLazyDeletion is a functional option for deleting expired keys found by Get, GetBytes and Has,
+// enabled by default. Disabled, they return ErrExpired and leave the entry to Cleanup,
+// so recently expired values stay around for inspection, like with Dump, and reads never take
+// the write lock to delete. Del and Set variants remove or replace expired entries regardless.
+func WithLazyDeletion[T any](enabled bool) func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.keepExpired = !enabled
+	}
+}
+
+// WithNoExpiration is a functional option for using the cache as a concurrent map without expiration.
+// TTL passed to Set and expiration times of loaded entries are ignored, so entries never expire,
+// Get and Has never call time.Now, and Cleanup returns immediately without scanning entries.
+func WithNoExpiration[T any]() func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.noExpiration = true
+	}
+}
+
+// WithSampledCleanup is a functional option for checking a few random entries on every Set
+// and deleting the expired ones (like Redis does), so the cache cleans itself up
+// without a background goroutine. Deleted entries are counted as evictions, not as Cleanup runs.
+func WithSampledCleanup[T any](samples int) func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.cleanupSamples = samples
+	}
+}
@@ -1,6 +1,7 @@
 package mcache
 
 import (
+	"bytes"
 	"fmt"
 	"log"
 	"runtime"
@@ -11,6 +12,9 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache/clocktest"
 )
 
 type testItem struct {
@@ -95,7 +99,7 @@ func Test_SimpleTest_Mcache(t *testing.T) {
 	assert.Equal(t, "newvalue", value)
 
 	result = c.Set("key", "not a newer value", 1)
-	assert.False(t, result)
+	assert.True(t, result)
 
 	time.Sleep(200 * time.Millisecond)
 	result = c.Set("key", "even newer value", 100*time.Millisecond)
@@ -272,6 +276,178 @@ func TestValuesAfterCleanup(t *testing.T) {
 	}
 }
 
+// TestEntryInfo tests that access metadata is tracked only with WithEntryStats
+func TestEntryInfo(t *testing.T) {
+	cache := NewCache(WithEntryStats[string]())
+	before := time.Now()
+	cache.Set("key", "value", time.Minute)
+	cache.Set("expired", "value", time.Millisecond)
+
+	info, err := cache.EntryInfo("key")
+	assert.NoError(t, err)
+	assert.False(t, info.Created.Before(before))
+	assert.True(t, info.LastAccess.IsZero())
+	assert.Equal(t, uint64(0), info.Hits)
+	assert.True(t, info.Expiration.After(before.Add(time.Minute-time.Second)))
+
+	for i := 0; i < 3; i++ {
+		_, err = cache.Get("key")
+		assert.NoError(t, err)
+	}
+	info, err = cache.EntryInfo("key")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), info.Hits)
+	assert.False(t, info.LastAccess.Before(info.Created))
+
+	_, err = cache.EntryInfo("noSuchKey")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	time.Sleep(10 * time.Millisecond)
+	_, err = cache.EntryInfo("expired")
+	assert.ErrorIs(t, err, ErrExpired)
+
+	// without WithEntryStats only expiration is available
+	plain := NewCache[string]()
+	plain.Set("key", "value", 0)
+	plain.Get("key")
+	info, err = plain.EntryInfo("key")
+	assert.NoError(t, err)
+	assert.Equal(t, EntryInfo{}, info)
+}
+
+func TestExists(t *testing.T) {
+	cache := NewCache(WithEntryStats[string](), WithGenerations[string]())
+	cache.Set("key", "value", 0)
+	cache.Set("expired", "value", time.Millisecond)
+	cache.SetNegative("missing", time.Minute)
+	cache.Cleanup() // entries move to the old generation
+	time.Sleep(10 * time.Millisecond)
+
+	assert.True(t, cache.Exists("key"))
+	assert.False(t, cache.Exists("expired"))
+	assert.False(t, cache.Exists("missing"))
+	assert.False(t, cache.Exists("noSuchKey"))
+
+	// nothing is deleted, promoted or counted
+	assert.Len(t, cache.old, 3)
+	assert.Empty(t, cache.data)
+	info, err := cache.EntryInfo("key")
+	require.NoError(t, err)
+	assert.Zero(t, info.Hits)
+	assert.True(t, info.LastAccess.IsZero())
+
+	require.NoError(t, cache.Close())
+	assert.False(t, cache.Exists("key"))
+}
+
+func TestWithLazyDeletion(t *testing.T) {
+	for name, opt := range map[string]func(*Cache[string]){
+		"map":         func(*Cache[string]) {},
+		"copyOnWrite": WithCopyOnWrite[string](),
+		"syncMap":     WithSyncMapBackend[string](),
+		"generations": WithGenerations[string](),
+	} {
+		t.Run(name, func(t *testing.T) {
+			clock := clocktest.New(time.Now())
+			cache := NewCache(opt, WithLazyDeletion[string](false), WithClock[string](clock))
+			cache.Set("key", "value", time.Millisecond)
+			cache.Cleanup()
+			clock.Advance(time.Millisecond + 1)
+
+			// expired key is not deleted, so the write lock is not taken
+			cache.RLock()
+			_, err := cache.Get("key")
+			assert.ErrorIs(t, err, ErrExpired)
+			_, err = cache.Has("key")
+			assert.ErrorIs(t, err, ErrExpired)
+			_, err = cache.GetBytes([]byte("key"))
+			assert.ErrorIs(t, err, ErrExpired)
+			cache.RUnlock()
+			assert.Equal(t, 1, cache.Stats().Entries)
+
+			cache.Cleanup()
+			_, err = cache.Get("key")
+			assert.ErrorIs(t, err, ErrKeyNotFound)
+		})
+	}
+
+	cache := NewCache(WithLazyDeletion[string](false))
+	cache.Set("key", "value", time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	assert.ErrorIs(t, cache.Del("key"), ErrExpired)
+	assert.Equal(t, 0, cache.Stats().Entries, "Del removes expired entries regardless")
+}
+
+// TestGetUnderReadLock tests that Get and Has of live keys only need the read lock
+func TestGetUnderReadLock(t *testing.T) {
+	cache := NewCache(WithEntryStats[string]())
+	cache.Set("key", "value", time.Hour)
+	cache.Set("expired", "value", time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+
+	cache.RLock() // another reader holds the lock
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		v, err := cache.Get("key")
+		assert.NoError(t, err)
+		assert.Equal(t, "value", v)
+		has, err := cache.Has("key")
+		assert.NoError(t, err)
+		assert.True(t, has)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Get is blocked by a reader")
+	}
+
+	// expired key needs the write lock to be deleted
+	expired := make(chan error)
+	go func() {
+		_, err := cache.Get("expired")
+		expired <- err
+	}()
+	select {
+	case <-expired:
+		t.Fatal("expired key deleted under the read lock")
+	case <-time.After(50 * time.Millisecond):
+	}
+	cache.RUnlock()
+	assert.ErrorIs(t, <-expired, ErrExpired)
+
+	info, err := cache.EntryInfo("key")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), info.Hits)
+}
+
+func TestWithNoExpiration(t *testing.T) {
+	cache := NewCache(WithNoExpiration[string]())
+	cache.Set("key", "value", time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+
+	v, err := cache.Get("key")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", v)
+	info, err := cache.EntryInfo("key")
+	assert.NoError(t, err)
+	assert.True(t, info.Expiration.IsZero())
+
+	removed, _ := cache.CleanupN()
+	assert.Equal(t, 0, removed)
+	assert.Equal(t, uint64(1), cache.Stats().Cleanups)
+
+	// expiration of loaded entries is ignored too
+	src := NewCache[string]()
+	src.Set("loaded", "value", time.Hour)
+	buf := bytes.Buffer{}
+	require.NoError(t, src.SaveTo(&buf))
+	require.NoError(t, cache.LoadFrom(&buf))
+	info, err = cache.EntryInfo("loaded")
+	assert.NoError(t, err)
+	assert.True(t, info.Expiration.IsZero())
+}
+
 func TestMain(m *testing.M) {
 	// Enable the race detector
 	m.Run()
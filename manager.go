@@ -0,0 +1,117 @@
+package mcache
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrCacheExists is returned by Manager.Register for a name already taken
+var ErrCacheExists = errors.New("cache already registered")
+
+// Managed is a cache of any value type owned by Manager, like Cache or StripedCache
+type Managed interface {
+	Stats() Stats
+	Close() error
+}
+
+// Manager owns named caches of different value types, with lookup by name,
+// aggregate stats and a single Close for all of them
+type Manager struct {
+	mu     sync.RWMutex
+	caches map[string]Managed
+}
+
+// NewManager is a constructor for Manager
+func NewManager() *Manager {
+	return &Manager{caches: make(map[string]Managed)}
+}
+
+// Register adds the cache under the name, ErrCacheExists if the name is taken
+func (m *Manager) Register(name string, c Managed) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.caches[name]; ok {
+		return fmt.Errorf("%w: %s", ErrCacheExists, name)
+	}
+	m.caches[name] = c
+	return nil
+}
+
+// NewManagedCache creates a cache with options and registers it in the manager under the name
+func NewManagedCache[T any](m *Manager, name string, options ...func(*Cache[T])) (*Cache[T], error) {
+	c := NewCache(options...)
+	if err := m.Register(name, c); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Lookup returns the cache registered under the name
+func (m *Manager) Lookup(name string) (Managed, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	c, ok := m.caches[name]
+	return c, ok
+}
+
+// ManagedCacheOf returns the Cache of values of type T registered under the name,
+// false if there is no such cache, or it has another type
+func ManagedCacheOf[T any](m *Manager, name string) (*Cache[T], bool) {
+	c, ok := m.Lookup(name)
+	if !ok {
+		return nil, false
+	}
+	typed, ok := c.(*Cache[T])
+	return typed, ok
+}
+
+// Names returns names of registered caches, sorted
+func (m *Manager) Names() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	names := make([]string, 0, len(m.caches))
+	for name := range m.caches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Stats returns stats of every cache by name
+func (m *Manager) Stats() map[string]Stats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	stats := make(map[string]Stats, len(m.caches))
+	for name, c := range m.caches {
+		stats[name] = c.Stats()
+	}
+	return stats
+}
+
+// TotalStats returns counters summed over all caches
+func (m *Manager) TotalStats() Stats {
+	var total Stats
+	for _, st := range m.Stats() {
+		total = total.add(st)
+	}
+	return total
+}
+
+// Close closes and unregisters all caches, errors are joined, caches closed already are skipped
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	caches := m.caches
+	m.caches = make(map[string]Managed)
+	m.mu.Unlock()
+
+	var errs []error
+	for name, c := range caches {
+		if err := c.Close(); err != nil && !errors.Is(err, ErrClosed) {
+			errs = append(errs, fmt.Errorf("failed to close cache %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
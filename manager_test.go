@@ -0,0 +1,40 @@
+package mcache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager(t *testing.T) {
+	m := NewManager()
+	users, err := NewManagedCache[string](m, "users")
+	require.NoError(t, err)
+	counters, err := NewManagedCache[int](m, "counters")
+	require.NoError(t, err)
+	require.NoError(t, m.Register("striped", NewStripedCache[int](4)))
+	_, err = NewManagedCache[int](m, "users")
+	assert.ErrorIs(t, err, ErrCacheExists)
+
+	assert.Equal(t, []string{"counters", "striped", "users"}, m.Names())
+	c, ok := ManagedCacheOf[string](m, "users")
+	require.True(t, ok)
+	assert.Same(t, users, c)
+	_, ok = ManagedCacheOf[string](m, "counters")
+	assert.False(t, ok, "wrong type")
+	_, ok = ManagedCacheOf[string](m, "missing")
+	assert.False(t, ok)
+
+	users.Set("a", "alice", 0)
+	counters.Set("a", 1, 0)
+	counters.Set("b", 2, 0)
+	users.Get("a") //nolint:errcheck // hit
+	assert.Equal(t, 2, m.Stats()["counters"].Entries)
+	assert.Equal(t, Stats{Hits: 1, Entries: 3}, m.TotalStats())
+
+	require.NoError(t, counters.Close())
+	require.NoError(t, m.Close(), "closed caches are skipped")
+	assert.ErrorIs(t, users.Close(), ErrClosed)
+	assert.Empty(t, m.Names())
+}
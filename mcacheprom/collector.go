@@ -0,0 +1,88 @@
+// Package mcacheprom provides a prometheus.Collector exporting mcache statistics.
+package mcacheprom
+
+import (
+	"github.com/parMaster/mcache"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// StatsProvider is implemented by *mcache.Cache of any value type.
+type StatsProvider interface {
+	Stats() mcache.Stats
+}
+
+// Collector exports statistics of a single cache, labeled with the cache name.
+type Collector struct {
+	cache StatsProvider
+
+	hits, misses, hitRatio *prometheus.Desc
+	entries, evictions     *prometheus.Desc
+	cleanups, cleanupTime  *prometheus.Desc
+	setTTL, ttl            *prometheus.Desc
+	setNoTTL, noTTL        *prometheus.Desc
+}
+
+// NewCollector creates a collector for the cache, all metrics get label cache="name".
+// Register one collector per cache, names must be unique.
+func NewCollector(name string, cache StatsProvider) *Collector {
+	labels := prometheus.Labels{"cache": name}
+	desc := func(metric, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName("mcache", "", metric), help, nil, labels)
+	}
+	return &Collector{
+		cache:       cache,
+		hits:        desc("hits_total", "Number of Get calls that returned a value."),
+		misses:      desc("misses_total", "Number of Get calls for missing or expired keys."),
+		hitRatio:    desc("hit_ratio", "Ratio of hits to all Get calls."),
+		entries:     desc("entries", "Number of entries currently stored."),
+		evictions:   desc("evictions_total", "Number of expired entries removed and entries evicted due to capacity."),
+		cleanups:    desc("cleanups_total", "Number of Cleanup runs."),
+		cleanupTime: desc("cleanup_duration_seconds_total", "Total time spent in Cleanup."),
+		setTTL:      desc("set_ttl_seconds", "TTLs passed to Set, without TTL in +Inf bucket, if the cache tracks them."),
+		ttl:         desc("remaining_ttl_seconds", "Remaining TTLs of entries, without TTL in +Inf bucket, if the cache tracks them."),
+		setNoTTL:    desc("sets_without_ttl_total", "Number of Set calls without TTL, if the cache tracks TTLs."),
+		noTTL:       desc("entries_without_ttl", "Number of entries without TTL, if the cache tracks TTLs."),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.hitRatio
+	ch <- c.entries
+	ch <- c.evictions
+	ch <- c.cleanups
+	ch <- c.cleanupTime
+	ch <- c.setTTL
+	ch <- c.ttl
+	ch <- c.setNoTTL
+	ch <- c.noTTL
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	s := c.cache.Stats()
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(s.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(s.Misses))
+	ch <- prometheus.MustNewConstMetric(c.hitRatio, prometheus.GaugeValue, s.HitRatio())
+	ch <- prometheus.MustNewConstMetric(c.entries, prometheus.GaugeValue, float64(s.Entries))
+	ch <- prometheus.MustNewConstMetric(c.evictions, prometheus.CounterValue, float64(s.Evictions))
+	ch <- prometheus.MustNewConstMetric(c.cleanups, prometheus.CounterValue, float64(s.Cleanups))
+	ch <- prometheus.MustNewConstMetric(c.cleanupTime, prometheus.CounterValue, s.CleanupTime.Seconds())
+	ch <- ttlHistogram(c.setTTL, s.SetTTLs)
+	ch <- ttlHistogram(c.ttl, s.TTLs)
+	ch <- prometheus.MustNewConstMetric(c.setNoTTL, prometheus.CounterValue, float64(s.SetTTLs.NoExpiration))
+	ch <- prometheus.MustNewConstMetric(c.noTTL, prometheus.GaugeValue, float64(s.TTLs.NoExpiration))
+}
+
+// ttlHistogram converts mcache.TTLHistogram to a histogram with cumulative buckets in seconds
+func ttlHistogram(desc *prometheus.Desc, h mcache.TTLHistogram) prometheus.Metric {
+	buckets := make(map[float64]uint64, len(h.Buckets))
+	var n uint64
+	for i, bound := range mcache.TTLBuckets {
+		n += h.Buckets[i]
+		buckets[bound.Seconds()] = n
+	}
+	return prometheus.MustNewConstHistogram(desc, h.Count(), h.Sum.Seconds(), buckets)
+}
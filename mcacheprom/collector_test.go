@@ -0,0 +1,82 @@
+package mcacheprom
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parMaster/mcache"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollector(t *testing.T) {
+	users := mcache.NewCache[string]()
+	users.Set("key", "value", time.Minute)
+	users.Get("key")
+	users.Get("noSuchKey")
+
+	sessions := mcache.NewCache[int]()
+	sessions.Cleanup()
+
+	reg := prometheus.NewPedanticRegistry()
+	require.NoError(t, reg.Register(NewCollector("users", users)))
+	require.NoError(t, reg.Register(NewCollector("sessions", sessions)))
+
+	expected := `
+# HELP mcache_entries Number of entries currently stored.
+# TYPE mcache_entries gauge
+mcache_entries{cache="sessions"} 0
+mcache_entries{cache="users"} 1
+# HELP mcache_hit_ratio Ratio of hits to all Get calls.
+# TYPE mcache_hit_ratio gauge
+mcache_hit_ratio{cache="sessions"} 0
+mcache_hit_ratio{cache="users"} 0.5
+# HELP mcache_hits_total Number of Get calls that returned a value.
+# TYPE mcache_hits_total counter
+mcache_hits_total{cache="sessions"} 0
+mcache_hits_total{cache="users"} 1
+# HELP mcache_cleanups_total Number of Cleanup runs.
+# TYPE mcache_cleanups_total counter
+mcache_cleanups_total{cache="sessions"} 1
+mcache_cleanups_total{cache="users"} 0
+`
+	err := testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"mcache_entries", "mcache_hit_ratio", "mcache_hits_total", "mcache_cleanups_total")
+	assert.NoError(t, err)
+
+	n, err := testutil.GatherAndCount(reg)
+	assert.NoError(t, err)
+	assert.Equal(t, 22, n)
+}
+
+func TestCollectorTTLs(t *testing.T) {
+	cache := mcache.NewCache(mcache.WithTTLHistogram[string]())
+	cache.Set("forever", "value", 0)
+	cache.Set("second", "value", time.Second)
+	cache.Set("day", "value", 20*time.Hour)
+
+	reg := prometheus.NewPedanticRegistry()
+	require.NoError(t, reg.Register(NewCollector("users", cache)))
+	expected := `
+# HELP mcache_set_ttl_seconds TTLs passed to Set, without TTL in +Inf bucket, if the cache tracks them.
+# TYPE mcache_set_ttl_seconds histogram
+mcache_set_ttl_seconds_bucket{cache="users",le="1"} 1
+mcache_set_ttl_seconds_bucket{cache="users",le="10"} 1
+mcache_set_ttl_seconds_bucket{cache="users",le="60"} 1
+mcache_set_ttl_seconds_bucket{cache="users",le="600"} 1
+mcache_set_ttl_seconds_bucket{cache="users",le="3600"} 1
+mcache_set_ttl_seconds_bucket{cache="users",le="86400"} 2
+mcache_set_ttl_seconds_bucket{cache="users",le="+Inf"} 3
+mcache_set_ttl_seconds_sum{cache="users"} 72001
+mcache_set_ttl_seconds_count{cache="users"} 3
+# HELP mcache_sets_without_ttl_total Number of Set calls without TTL, if the cache tracks TTLs.
+# TYPE mcache_sets_without_ttl_total counter
+mcache_sets_without_ttl_total{cache="users"} 1
+`
+	err := testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"mcache_set_ttl_seconds", "mcache_sets_without_ttl_total")
+	assert.NoError(t, err)
+}
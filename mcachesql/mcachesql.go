@@ -0,0 +1,165 @@
+// Package mcachesql caches results of SQL queries in mcache, invalidated per table.
+// Results are cached under a hash of the query and its arguments, and depend on tables
+// read by the query (see mcache.Cache.SetWithDeps), so Invalidate of a table deletes
+// results of all queries reading it.
+package mcachesql
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/parMaster/mcache"
+)
+
+// Queryer runs queries, like *sql.DB, *sql.Tx or *sql.Conn
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// tablePrefix is a prefix of keys results of queries depend on, one per table
+const tablePrefix = "sql-table:"
+
+// Query returns rows of the query scanned into T, cached with ttl. Rows of a struct T are scanned
+// into fields named by the `db` tag or matching column names case-insensitively, ignoring underscores,
+// columns without a field are skipped. Rows of other types are scanned from a single column.
+// The result is cached depending on tables following FROM and JOIN in the query,
+// tables listed with commas after FROM are not detected, use JOIN or Invalidate the first table.
+func Query[T any](ctx context.Context, cache *mcache.Cache[[]T], db Queryer, ttl time.Duration,
+	query string, args ...any) ([]T, error) {
+	key := Key(query, args...)
+	if v, err := cache.Get(key); err == nil {
+		return v, nil
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result, err := scan[T](rows)
+	if err != nil {
+		return nil, err
+	}
+
+	tables := Tables(query)
+	deps := make([]string, len(tables))
+	for i, table := range tables {
+		deps[i] = tablePrefix + table
+	}
+	cache.SetWithDeps(key, result, ttl, deps...)
+	return result, nil
+}
+
+// Invalidate deletes cached results of queries reading the tables, call it after writes to them
+func Invalidate[T any](cache *mcache.Cache[[]T], tables ...string) {
+	for _, table := range tables {
+		_ = cache.Del(tablePrefix + normalize(table)) // dependents are deleted even if the key doesn't exist
+	}
+}
+
+// Key returns the cache key of the query with the arguments
+func Key(query string, args ...any) string {
+	h := sha256.New()
+	h.Write([]byte(query))
+	for _, arg := range args {
+		fmt.Fprintf(h, "\x00%T:%v", arg, arg)
+	}
+	return "sql:" + hex.EncodeToString(h.Sum(nil))
+}
+
+var tablesRe = regexp.MustCompile(`(?i)\b(?:from|join)\s+([\w."` + "`" + `\[\]]+)`)
+
+// Tables returns tables following FROM and JOIN in the query, lower case without quotes
+func Tables(query string) []string {
+	var tables []string
+	seen := map[string]bool{}
+	for _, m := range tablesRe.FindAllStringSubmatch(query, -1) {
+		if table := normalize(m[1]); table != "" && !seen[table] {
+			seen[table] = true
+			tables = append(tables, table)
+		}
+	}
+	return tables
+}
+
+var quotes = strings.NewReplacer(`"`, "", "`", "", "[", "", "]", "")
+
+// normalize returns the table name in lower case without quotes
+func normalize(table string) string {
+	return strings.ToLower(quotes.Replace(table))
+}
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// scan scans all rows into T
+func scan[T any](rows *sql.Rows) ([]T, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	isStruct := t.Kind() == reflect.Struct && t != reflect.TypeOf(time.Time{}) && !reflect.PointerTo(t).Implements(scannerType)
+	var fields [][]int
+	if isStruct {
+		fields = fieldsOf(t, columns)
+	} else if len(columns) != 1 {
+		return nil, fmt.Errorf("can't scan %d columns into %s", len(columns), t)
+	}
+
+	result := []T{}
+	for rows.Next() {
+		var v T
+		dest := []any{&v}
+		if isStruct {
+			rv := reflect.ValueOf(&v).Elem()
+			dest = make([]any, len(columns))
+			for i, field := range fields {
+				if field == nil {
+					dest[i] = new(any)
+					continue
+				}
+				dest[i] = rv.FieldByIndex(field).Addr().Interface()
+			}
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		result = append(result, v)
+	}
+	return result, rows.Err()
+}
+
+// fieldsOf returns indexes of fields of the struct type for the columns, nil for columns without a field
+func fieldsOf(t reflect.Type, columns []string) [][]int {
+	byName := map[string][]int{}
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+		name := f.Tag.Get("db")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		byName[columnName(name)] = f.Index
+	}
+	fields := make([][]int, len(columns))
+	for i, column := range columns {
+		fields[i] = byName[columnName(column)]
+	}
+	return fields
+}
+
+// columnName returns the name in lower case without underscores, so user_id matches UserID
+func columnName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
@@ -0,0 +1,147 @@
+package mcachesql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache"
+)
+
+// fakeDB is a database/sql driver returning fixed results of queries, counting them
+type fakeDB struct {
+	results map[string]fakeResult
+	queries int
+}
+
+type fakeResult struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (d *fakeDB) Connect(context.Context) (driver.Conn, error) { return d, nil }
+func (d *fakeDB) Driver() driver.Driver                        { return nil }
+func (d *fakeDB) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{db: d, query: query}, nil
+}
+func (d *fakeDB) Close() error              { return nil }
+func (d *fakeDB) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+func (s *fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.db.queries++
+	r, ok := s.db.results[s.query]
+	if !ok {
+		return nil, errors.New("unknown query")
+	}
+	if len(args) > 0 && args[0] == int64(0) {
+		r.rows = nil // no rows for id 0
+	}
+	return &fakeRows{result: r}, nil
+}
+
+type fakeRows struct {
+	result fakeResult
+	i      int
+}
+
+func (r *fakeRows) Columns() []string { return r.result.columns }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.i == len(r.result.rows) {
+		return io.EOF
+	}
+	copy(dest, r.result.rows[r.i])
+	r.i++
+	return nil
+}
+
+type user struct {
+	ID    int64
+	Name  string `db:"user_name"`
+	Email string `db:"-"`
+}
+
+const (
+	usersQuery  = "SELECT id, user_name, email FROM users WHERE id > ?"
+	ordersQuery = `SELECT id, user_name FROM "Users" u JOIN public.orders o ON o.user_id = u.id`
+	namesQuery  = "SELECT user_name FROM users"
+)
+
+func TestQuery(t *testing.T) {
+	fake := &fakeDB{results: map[string]fakeResult{
+		usersQuery: {columns: []string{"id", "user_name", "email"}, rows: [][]driver.Value{
+			{int64(1), "alice", "alice@example.com"},
+			{int64(2), "bob", "bob@example.com"},
+		}},
+		ordersQuery: {columns: []string{"id", "user_name"}, rows: [][]driver.Value{{int64(1), "alice"}}},
+		namesQuery:  {columns: []string{"user_name"}, rows: [][]driver.Value{{"alice"}, {"bob"}}},
+	}}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+	ctx := context.Background()
+	cache := mcache.NewCache[[]user]()
+
+	users, err := Query(ctx, cache, db, time.Minute, usersQuery, 0)
+	require.NoError(t, err)
+	assert.Empty(t, users)
+	users, err = Query(ctx, cache, db, time.Minute, usersQuery, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []user{{ID: 1, Name: "alice"}, {ID: 2, Name: "bob"}}, users)
+	users, err = Query(ctx, cache, db, time.Minute, usersQuery, 1)
+	require.NoError(t, err)
+	assert.Len(t, users, 2)
+	assert.Equal(t, 2, fake.queries, "result is cached per arguments")
+
+	orders, err := Query(ctx, cache, db, time.Minute, ordersQuery)
+	require.NoError(t, err)
+	assert.Equal(t, []user{{ID: 1, Name: "alice"}}, orders)
+	assert.Equal(t, 3, fake.queries)
+
+	// invalidation deletes results of queries reading the table only
+	Invalidate(cache, "public.Orders")
+	_, err = Query(ctx, cache, db, time.Minute, ordersQuery)
+	require.NoError(t, err)
+	_, err = Query(ctx, cache, db, time.Minute, usersQuery, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 4, fake.queries)
+	Invalidate(cache, "users")
+	_, err = Query(ctx, cache, db, time.Minute, usersQuery, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 5, fake.queries)
+
+	names, err := Query(ctx, mcache.NewCache[[]string](), db, time.Minute, namesQuery)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice", "bob"}, names)
+
+	_, err = Query(ctx, mcache.NewCache[[]string](), db, time.Minute, usersQuery, 1)
+	assert.EqualError(t, err, "can't scan 3 columns into string")
+	_, err = Query(ctx, cache, db, time.Minute, "SELECT 1")
+	assert.EqualError(t, err, "unknown query")
+}
+
+func TestTables(t *testing.T) {
+	assert.Equal(t, []string{"users", "public.orders", "items"},
+		Tables("SELECT * FROM \"Users\" u JOIN public.orders o ON o.user_id = u.id "+
+			"WHERE u.id IN (SELECT user_id FROM items) AND u.id IN (select id from USERS)"))
+	assert.Empty(t, Tables("SELECT 1"))
+	assert.Equal(t, Key("SELECT ?", 1), Key("SELECT ?", 1))
+	assert.NotEqual(t, Key("SELECT ?", 1), Key("SELECT ?", "1"))
+}
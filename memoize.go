@@ -0,0 +1,40 @@
+package mcache
+
+import (
+	"fmt"
+	"time"
+)
+
+// memoizeCleanupSamples is the number of entries checked for expiration on every result cached by Memoize
+const memoizeCleanupSamples = 5
+
+// Memoize returns a wrapper of fn caching its results for ttl (0 for no expiration) in a Cache.
+// Concurrent calls with the same argument share a single fn call, errors are not cached.
+// Arguments are keyed by their Go-syntax representation (%#v), so pointers are keyed by address.
+// Expired results are deleted WithSampledCleanup, as there is no Close to stop a Cleanup goroutine.
+func Memoize[K comparable, V any](fn func(K) (V, error), ttl time.Duration) func(K) (V, error) {
+	_, memoized := memoize(fn, ttl)
+	return memoized
+}
+
+// memoize is Memoize returning the cache of results as well
+func memoize[K comparable, V any](fn func(K) (V, error), ttl time.Duration) (*Cache[V], func(K) (V, error)) {
+	cache := NewCache(WithSampledCleanup[V](memoizeCleanupSamples))
+	calls := flightGroup[V]{}
+	return cache, func(arg K) (V, error) {
+		key := fmt.Sprintf("%#v", arg)
+		if v, err := cache.Get(key); err == nil {
+			return v, nil
+		}
+		return calls.do(key, func() (V, error) {
+			if item, err := cache.lookup(key); err == nil {
+				return item.value, nil
+			}
+			v, err := fn(arg)
+			if err == nil {
+				cache.Set(key, v, ttl)
+			}
+			return v, err
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package mcache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoize(t *testing.T) {
+	type point struct{ X, Y string }
+	var calls atomic.Int32
+	area := Memoize(func(p point) (int, error) {
+		calls.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		if p.X == "" {
+			return 0, errors.New("empty")
+		}
+		return len(p.X) * len(p.Y), nil
+	}, time.Hour)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := area(point{"ab", "c"})
+			assert.NoError(t, err)
+			assert.Equal(t, 2, v)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), calls.Load())
+
+	// arguments with the same %v are different keys
+	v, err := area(point{"a b", "c"})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, v)
+	v, err = area(point{"a", "b c"})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, v)
+	assert.Equal(t, int32(3), calls.Load())
+
+	_, err = area(point{})
+	assert.Error(t, err)
+	_, err = area(point{})
+	assert.Error(t, err)
+	assert.Equal(t, int32(5), calls.Load())
+}
+
+func TestMemoizeDropsExpiredResults(t *testing.T) {
+	cache, double := memoize(func(n int) (int, error) { return 2 * n, nil }, 5*time.Millisecond)
+	for i := 0; i < 100; i++ {
+		_, err := double(i)
+		assert.NoError(t, err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	for i := 100; i < 200; i++ {
+		_, err := double(i)
+		assert.NoError(t, err)
+	}
+	assert.Less(t, cache.Stats().Entries, 150, "expired results are deleted by new ones")
+}
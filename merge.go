@@ -0,0 +1,78 @@
+package mcache
+
+import "time"
+
+// MergePolicy is a rule resolving keys existing in both caches on Merge
+type MergePolicy int
+
+// Merge policies
+const (
+	KeepExisting MergePolicy = iota // keep the live entry of the cache
+	KeepNewer                       // keep the entry created later
+	Overwrite                       // replace the entry of the cache
+)
+
+// Merge copies live entries of other into the cache, for caches pre-built by workers and folded
+// into the main one. Keys existing in both caches are resolved by policy: KeepNewer compares
+// creation times tracked WithEntryStats, without them the entry expiring later is kept.
+// Entries keep their remaining TTLs, like with Clone. Other cache is read from a snapshot,
+// so merging caches into each other concurrently can't deadlock, and it's not changed.
+// Merged entries are logged and replicated, but not written back WithWriteBehind.
+func (c *Cache[T]) Merge(other *Cache[T], policy MergePolicy) error {
+	if other == c {
+		return nil
+	}
+	s := other.snapshot()
+
+	c.Lock()
+	defer c.Unlock()
+	if c.closed {
+		return ErrClosed
+	}
+	for k, v := range s.data {
+		if v.expiredAt(s.at) {
+			continue
+		}
+		var ttl time.Duration
+		if v.expiration != 0 {
+			ttl = time.Duration(v.expiration - s.at)
+		}
+		item := c.ttlItem(v.value, ttl, v.negative)
+		if item.meta != nil && v.meta != nil {
+			item.meta.created = v.meta.created
+		}
+		if c.keepExisting(k, item, policy) {
+			continue
+		}
+		c.setItem(k, item)
+	}
+	return nil
+}
+
+// keepExisting checks if the live entry of the key wins over the merged item, must be called under lock
+func (c *Cache[T]) keepExisting(key string, item CacheItem[T], policy MergePolicy) bool {
+	if policy == Overwrite {
+		return false
+	}
+	cached, ok := c.data[key]
+	if !ok {
+		cached, ok = c.old[key]
+	}
+	if !ok || c.expired(cached) {
+		return policy == KeepExisting && c.spilled != nil && c.spilledLive(key)
+	}
+	if policy == KeepExisting {
+		return true
+	}
+
+	if cached.meta != nil && item.meta != nil {
+		return !item.meta.created.After(cached.meta.created)
+	}
+	switch {
+	case item.expiration == 0:
+		return false
+	case cached.expiration == 0:
+		return true
+	}
+	return cached.expiration >= item.expiration
+}
@@ -0,0 +1,77 @@
+package mcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache/clocktest"
+)
+
+func TestMerge(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	newCaches := func() (main, worker *Cache[string]) {
+		main = NewCache(WithClock[string](clock))
+		require.True(t, main.Set("both", "main", time.Hour))
+		require.True(t, main.Set("main", "main", 0))
+		worker = NewCache(WithClock[string](clock))
+		require.True(t, worker.Set("both", "worker", 2*time.Hour))
+		require.True(t, worker.Set("worker", "worker", time.Minute))
+		require.True(t, worker.Set("expired", "worker", time.Second))
+		clock.Advance(2 * time.Second)
+		return main, worker
+	}
+
+	tbl := []struct {
+		policy MergePolicy
+		both   string
+	}{
+		{KeepExisting, "main"},
+		{KeepNewer, "worker"}, // expires later
+		{Overwrite, "worker"},
+	}
+	for _, tt := range tbl {
+		main, worker := newCaches()
+		require.NoError(t, main.Merge(worker, tt.policy))
+		v, err := main.Get("both")
+		require.NoError(t, err)
+		assert.Equal(t, tt.both, v, "policy %d", tt.policy)
+		v, err = main.Get("worker")
+		require.NoError(t, err)
+		assert.Equal(t, "worker", v)
+		_, err = main.Get("expired")
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+		assert.Equal(t, 3, main.Stats().Entries)
+		assert.Equal(t, 3, worker.Stats().Entries, "other cache isn't changed")
+	}
+
+	main, worker := newCaches()
+	require.NoError(t, main.Merge(worker, Overwrite))
+	clock.Advance(time.Minute)
+	_, err := main.Get("worker")
+	assert.ErrorIs(t, err, ErrExpired, "remaining TTL is kept")
+
+	require.NoError(t, main.Close())
+	assert.ErrorIs(t, main.Merge(worker, Overwrite), ErrClosed)
+}
+
+func TestMergeKeepNewerEntryStats(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	worker := NewCache(WithClock[string](clock), WithEntryStats[string]())
+	require.True(t, worker.Set("key", "older", time.Minute))
+	clock.Advance(time.Second)
+	main := NewCache(WithClock[string](clock), WithEntryStats[string]())
+	require.True(t, main.Set("key", "newer", time.Second))
+
+	require.NoError(t, main.Merge(worker, KeepNewer))
+	v, err := main.Get("key")
+	require.NoError(t, err)
+	assert.Equal(t, "newer", v)
+
+	require.NoError(t, worker.Merge(main, KeepNewer))
+	v, err = worker.Get("key")
+	require.NoError(t, err)
+	assert.Equal(t, "newer", v)
+}
@@ -0,0 +1,28 @@
+package mcache
+
+import "time"
+
+// MetricsSink receives cache events as they happen, so they can be forwarded
+// to statsd, Prometheus, OpenTelemetry or any other metrics system.
+// Methods are called synchronously, some of them under the cache lock, so they must be fast.
+type MetricsSink interface {
+	IncHit()                                        // Get returned a value
+	IncMiss()                                       // Get for missing or expired key
+	IncEviction(n int)                              // n expired or capacity-evicted entries removed
+	ObserveCleanup(removed int, took time.Duration) // Cleanup run finished
+}
+
+// NoopMetrics is a MetricsSink doing nothing, used by default.
+type NoopMetrics struct{}
+
+func (NoopMetrics) IncHit()                           {}
+func (NoopMetrics) IncMiss()                          {}
+func (NoopMetrics) IncEviction(int)                   {}
+func (NoopMetrics) ObserveCleanup(int, time.Duration) {}
+
+// WithMetrics is a functional option for setting a MetricsSink.
+func WithMetrics[T any](m MetricsSink) func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.metrics = m
+	}
+}
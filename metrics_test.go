@@ -0,0 +1,43 @@
+package mcache
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testSink struct {
+	hits, misses, evictions, cleanups atomic.Int64
+}
+
+func (s *testSink) IncHit()                           { s.hits.Add(1) }
+func (s *testSink) IncMiss()                          { s.misses.Add(1) }
+func (s *testSink) IncEviction(n int)                 { s.evictions.Add(int64(n)) }
+func (s *testSink) ObserveCleanup(int, time.Duration) { s.cleanups.Add(1) }
+
+func TestWithMetrics(t *testing.T) {
+	sink := &testSink{}
+	cache := NewCache(WithMetrics[string](sink))
+
+	cache.Set("key", "value", 0)
+	cache.Set("expired", "value", time.Millisecond)
+	cache.Set("expired2", "value", time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+
+	cache.Get("key")
+	cache.Get("noSuchKey")
+	cache.Get("expired")
+	cache.Cleanup()
+
+	assert.Equal(t, int64(1), sink.hits.Load())
+	assert.Equal(t, int64(2), sink.misses.Load())
+	assert.Equal(t, int64(2), sink.evictions.Load())
+	assert.Equal(t, int64(1), sink.cleanups.Load())
+
+	// counters are kept in Stats regardless of the sink
+	s := cache.Stats()
+	assert.Equal(t, uint64(1), s.Hits)
+	assert.Equal(t, uint64(2), s.Evictions)
+}
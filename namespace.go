@@ -0,0 +1,152 @@
+package mcache
+
+import (
+	"strings"
+	"time"
+)
+
+// Keys returns live keys of the cache, in no particular order.
+// Keys of entries spilled WithOverflow are included, negative entries are not.
+func (c *Cache[T]) Keys() []string {
+	return c.keysWithPrefix("")
+}
+
+// keysWithPrefix returns live keys starting with the prefix
+func (c *Cache[T]) keysWithPrefix(prefix string) []string {
+	c.RLock()
+	defer c.RUnlock()
+	keys := []string{}
+	now := c.now()
+	for _, data := range c.dataMaps() {
+		for k, v := range data {
+			if strings.HasPrefix(k, prefix) && !v.expiredAt(now) && !v.negative {
+				keys = append(keys, k)
+			}
+		}
+	}
+	for k := range c.spilled {
+		if strings.HasPrefix(k, prefix) && c.spilledLive(k) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// PrefixStats is a number and approximate size of live entries with a key prefix, returned by PrefixStats
+type PrefixStats struct {
+	Entries int // live entries in memory
+	Spilled int // live entries spilled WithOverflow
+	Bytes   int // approximate size of keys and values in memory, see Dump and WithDeepSizing
+}
+
+// CountPrefix returns the number of live keys starting with the prefix, including spilled ones,
+// the number of keys DelPrefix would delete
+func (c *Cache[T]) CountPrefix(prefix string) int {
+	s := c.prefixStats(prefix, false)
+	return s.Entries + s.Spilled
+}
+
+// PrefixStats returns the number and approximate size of live entries with keys starting with the prefix,
+// for capacity planning of namespaced keys. Sizes are approximated like in Dump, with reflection,
+// so it's more expensive than CountPrefix. Values are sized WithDeepSizing, if it's set.
+func (c *Cache[T]) PrefixStats(prefix string) PrefixStats {
+	return c.prefixStats(prefix, true)
+}
+
+// prefixStats counts live entries with the prefix, sizing them if size is set
+func (c *Cache[T]) prefixStats(prefix string, size bool) PrefixStats {
+	c.RLock()
+	defer c.RUnlock()
+	var s PrefixStats
+	now := c.now()
+	for _, data := range c.dataMaps() {
+		for k, v := range data {
+			if !strings.HasPrefix(k, prefix) || v.expiredAt(now) || v.negative {
+				continue
+			}
+			s.Entries++
+			if size {
+				s.Bytes += len(k) + c.sizeOf(v.value)
+			}
+		}
+	}
+	for k := range c.spilled {
+		if strings.HasPrefix(k, prefix) && c.spilledLive(k) {
+			s.Spilled++
+		}
+	}
+	return s
+}
+
+// Namespace is a view of the cache scoped to keys with a prefix, created with Cache.Namespace.
+// It implements Cacher, so teams sharing a cache can't collide on key names.
+type Namespace[T any] struct {
+	cache  *Cache[T]
+	prefix string
+}
+
+// Namespace returns a view of the cache transparently prefixing keys with the prefix,
+// its Clear, Keys and DelPrefix are scoped to the namespace.
+func (c *Cache[T]) Namespace(prefix string) *Namespace[T] {
+	return &Namespace[T]{cache: c, prefix: prefix}
+}
+
+// Namespace returns a nested namespace, prefixed with both prefixes
+func (n *Namespace[T]) Namespace(prefix string) *Namespace[T] {
+	return &Namespace[T]{cache: n.cache, prefix: n.prefix + prefix}
+}
+
+// Set is Cache.Set of the prefixed key
+func (n *Namespace[T]) Set(key string, value T, ttl time.Duration) bool {
+	return n.cache.Set(n.prefix+key, value, ttl)
+}
+
+// Get is Cache.Get of the prefixed key
+func (n *Namespace[T]) Get(key string) (T, error) {
+	return n.cache.Get(n.prefix + key)
+}
+
+// Has is Cache.Has of the prefixed key
+func (n *Namespace[T]) Has(key string) (bool, error) {
+	return n.cache.Has(n.prefix + key)
+}
+
+// Exists is Cache.Exists of the prefixed key
+func (n *Namespace[T]) Exists(key string) bool {
+	return n.cache.Exists(n.prefix + key)
+}
+
+// Del is Cache.Del of the prefixed key
+func (n *Namespace[T]) Del(key string) error {
+	return n.cache.Del(n.prefix + key)
+}
+
+// Cleanup is Cache.Cleanup, it deletes expired keys of the whole cache
+func (n *Namespace[T]) Cleanup() {
+	n.cache.Cleanup()
+}
+
+// Clear deletes all keys of the namespace
+func (n *Namespace[T]) Clear() error {
+	_, err := n.cache.DelPrefix(n.prefix)
+	return err
+}
+
+// DelPrefix deletes keys of the namespace starting with the prefix, see Cache.DelPrefix
+func (n *Namespace[T]) DelPrefix(prefix string) (int, error) {
+	return n.cache.DelPrefix(n.prefix + prefix)
+}
+
+// Keys returns live keys of the namespace, without the namespace prefix
+func (n *Namespace[T]) Keys() []string {
+	keys := n.cache.keysWithPrefix(n.prefix)
+	for i, k := range keys {
+		keys[i] = k[len(n.prefix):]
+	}
+	return keys
+}
+
+// CountPrefix returns the number of live keys of the namespace starting with the prefix, see Cache.CountPrefix
+func (n *Namespace[T]) CountPrefix(prefix string) int {
+	return n.cache.CountPrefix(n.prefix + prefix)
+}
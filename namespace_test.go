@@ -0,0 +1,80 @@
+package mcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeys(t *testing.T) {
+	cache := NewCache[int]()
+	cache.Set("a", 1, 0)
+	cache.Set("b", 2, time.Hour)
+	cache.Set("expired", 3, time.Nanosecond)
+	cache.SetNegative("negative", 0)
+	time.Sleep(time.Millisecond)
+	assert.ElementsMatch(t, []string{"a", "b"}, cache.Keys())
+}
+
+func TestNamespace(t *testing.T) {
+	cache := NewCache[string]()
+	var _ Cacher[string] = cache.Namespace("")
+	users := cache.Namespace("users:")
+	orders := cache.Namespace("orders:")
+
+	assert.True(t, users.Set("1", "alice", 0))
+	assert.True(t, orders.Set("1", "order", 0))
+	assert.True(t, users.Set("1", "bob", 0))
+	v, err := users.Get("1")
+	require.NoError(t, err)
+	assert.Equal(t, "bob", v)
+	v, err = cache.Get("orders:1")
+	require.NoError(t, err)
+	assert.Equal(t, "order", v)
+	has, err := orders.Has("1")
+	require.NoError(t, err)
+	assert.True(t, has)
+
+	admins := users.Namespace("admin:")
+	admins.Set("1", "root", 0)
+	admins.Set("2", "admin", 0)
+	assert.ElementsMatch(t, []string{"1", "admin:1", "admin:2"}, users.Keys())
+	assert.ElementsMatch(t, []string{"1", "2"}, admins.Keys())
+
+	n, err := users.DelPrefix("admin:")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	require.NoError(t, users.Del("1"))
+	assert.Empty(t, users.Keys())
+
+	users.Set("2", "carol", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	users.Cleanup()
+	assert.Equal(t, 1, cache.Stats().Entries)
+
+	users.Set("3", "dave", 0)
+	require.NoError(t, users.Clear())
+	assert.Equal(t, []string{"orders:1"}, cache.Keys())
+}
+
+func TestCountPrefix(t *testing.T) {
+	cache := NewCache[string]()
+	require.True(t, cache.Set("user:1", "alice", 0))
+	require.True(t, cache.Set("user:2", "bob", 0))
+	require.True(t, cache.Set("order:1", "book", 0))
+	require.True(t, cache.Set("user:3", "carol", time.Nanosecond))
+	require.True(t, cache.SetNegative("user:4", 0))
+	time.Sleep(time.Millisecond)
+
+	assert.Equal(t, 2, cache.CountPrefix("user:"))
+	assert.Equal(t, 1, cache.Namespace("order:").CountPrefix(""))
+	assert.Equal(t, 3, cache.CountPrefix(""))
+
+	stats := cache.PrefixStats("user:")
+	assert.Equal(t, 2, stats.Entries)
+	assert.Equal(t, 0, stats.Spilled)
+	assert.Equal(t, len("user:1alice")+len("user:2bob")+2*valueSize(""), stats.Bytes)
+	assert.Equal(t, PrefixStats{}, cache.PrefixStats("session:"))
+}
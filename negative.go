@@ -0,0 +1,61 @@
+package mcache
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNegativeCached is returned by Get and Has for keys cached as missing with SetNegative
+var ErrNegativeCached = errors.New("key cached as missing")
+
+// WithNegativeTTL is a functional option for the TTL of negative entries: it's used by SetNegative
+// called with zero ttl, and keys reported missing by the backing store WithReadThrough, or by the loader
+// of LoadingCache, with ErrKeyNotFound, are cached as negative entries for ttl.
+func WithNegativeTTL[T any](ttl time.Duration) func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.negativeTTL = ttl
+	}
+}
+
+// SetNegative caches the key as missing, so Get and Has return ErrNegativeCached until it expires,
+// instead of going to the database again. Zero ttl is the one set WithNegativeTTL, if any.
+// Rules are the same as for Set, but negative entries are replaced by Set and SetBytes,
+// and they are not persisted, logged to WAL, replicated or written back.
+func (c *Cache[T]) SetNegative(key string, ttl time.Duration) bool {
+	if ttl == 0 {
+		ttl = c.negativeTTL
+	}
+	c.Lock()
+	defer c.Unlock()
+	if c.closed || c.validateKey(key) != nil {
+		return false
+	}
+	cached, ok := c.data[key]
+	if !ok {
+		cached, ok = c.old[key]
+	}
+	if ok && !c.expired(cached) {
+		return false
+	}
+	if !ok && c.full() {
+		return false
+	}
+	var none T
+	c.setItem(key, c.ttlItem(none, ttl, true))
+	return true
+}
+
+// loaded records the key reported missing by the backing store or the loader,
+// WithNegativeLookupFilter and WithNegativeTTL, other loader errors are logged
+func (c *Cache[T]) loaded(key string, err error) {
+	if !errors.Is(err, ErrKeyNotFound) {
+		c.logger.Warn("mcache: loader failed", "key", key, "err", err)
+		return
+	}
+	if c.negative != nil {
+		c.negative.add(key)
+	}
+	if c.negativeTTL > 0 {
+		c.SetNegative(key, c.negativeTTL)
+	}
+}
@@ -0,0 +1,72 @@
+package mcache
+
+import (
+	"bytes"
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetNegative(t *testing.T) {
+	cache := NewCache(WithNegativeTTL[string](time.Millisecond))
+	assert.True(t, cache.SetNegative("missing", 0))
+	assert.False(t, cache.SetNegative("missing", time.Hour))
+
+	_, err := cache.Get("missing")
+	assert.ErrorIs(t, err, ErrNegativeCached)
+	_, err = cache.GetBytes([]byte("missing"))
+	assert.ErrorIs(t, err, ErrNegativeCached)
+	has, err := cache.Has("missing")
+	assert.ErrorIs(t, err, ErrNegativeCached)
+	assert.False(t, has)
+
+	// negative entry is not persisted
+	buf := bytes.Buffer{}
+	require.NoError(t, cache.SaveTo(&buf))
+	restored := NewCache[string]()
+	require.NoError(t, restored.LoadFrom(&buf))
+	assert.Equal(t, 0, restored.Stats().Entries)
+
+	// negative entry expires with the negative TTL
+	time.Sleep(2 * time.Millisecond)
+	_, err = cache.Get("missing")
+	assert.ErrorIs(t, err, ErrExpired)
+
+	// Set replaces negative entry
+	assert.True(t, cache.SetNegative("key", time.Hour))
+	assert.True(t, cache.Set("key", "value", 0))
+	v, err := cache.Get("key")
+	require.NoError(t, err)
+	assert.Equal(t, "value", v)
+	assert.False(t, cache.SetNegative("key", 0), "live value is not replaced")
+	assert.True(t, cache.SetNegative("bytes", 0))
+	assert.True(t, cache.SetBytes([]byte("bytes"), "value", 0))
+
+	require.NoError(t, cache.Close())
+	assert.False(t, cache.SetNegative("other", 0))
+}
+
+func TestWithNegativeTTL(t *testing.T) {
+	store := newMapStore[string]()
+	cache := NewCache(WithReadThrough[string](store), WithNegativeTTL[string](time.Hour))
+	_, err := cache.Get("missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	_, err = cache.Get("missing")
+	assert.ErrorIs(t, err, ErrNegativeCached)
+	assert.Equal(t, int32(1), store.loads.Load())
+
+	var loads atomic.Int32
+	loading := NewLoadingCache(func(context.Context, string) (int, time.Duration, error) {
+		loads.Add(1)
+		return 0, 0, ErrKeyNotFound
+	}, WithNegativeTTL[int](time.Hour))
+	_, err = loading.Get(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	_, err = loading.Get(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrNegativeCached)
+	assert.Equal(t, int32(1), loads.Load())
+}
@@ -0,0 +1,123 @@
+package mcache
+
+import (
+	"errors"
+	"slices"
+	"sort"
+)
+
+// ErrNotOrdered is returned by range queries of a cache created without WithOrderedKeys
+var ErrNotOrdered = errors.New("ordered keys are not enabled")
+
+// orderedKeys is a sorted slice of keys. Deleted keys are not removed from the slice one by one,
+// they are counted as stale and dropped all at once when they make up a half of it.
+type orderedKeys struct {
+	keys  []string
+	stale int
+}
+
+// reset drops all keys
+func (o *orderedKeys) reset() {
+	o.keys, o.stale = nil, 0
+}
+
+// WithOrderedKeys is a functional option for keeping keys sorted, for RangeKeys, MinKey and MaxKey
+// queries, like all entries of time-bucketed keys for the last hour. Keys are kept in a sorted slice,
+// inserting a key is O(log n) when keys are set in order, and O(n) at worst.
+// Entries spilled WithOverflow are not included.
+func WithOrderedKeys[T any]() func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.ordered = &orderedKeys{}
+		c.indexing = true
+	}
+}
+
+// insertOrdered adds the key to ordered keys, compacting stale keys first. Must be called under lock.
+func (c *Cache[T]) insertOrdered(key string) {
+	o := c.ordered
+	if o.stale > len(o.keys)/2 {
+		o.keys = slices.DeleteFunc(o.keys, func(k string) bool { return !c.present(k) })
+		o.stale = 0
+	}
+	if n := len(o.keys); n == 0 || o.keys[n-1] < key {
+		o.keys = append(o.keys, key)
+		return
+	}
+	if i, found := slices.BinarySearch(o.keys, key); !found {
+		o.keys = slices.Insert(o.keys, i, key)
+	}
+}
+
+// present checks if the key is stored in either generation, expired or not. Must be called under lock.
+func (c *Cache[T]) present(key string) bool {
+	if _, ok := c.data[key]; ok {
+		return true
+	}
+	_, ok := c.old[key]
+	return ok
+}
+
+// live checks if the key has a live item in either generation, negative items are not live.
+// Must be called under lock.
+func (c *Cache[T]) live(key string, now int64) bool {
+	item, ok := c.data[key]
+	if !ok {
+		item, ok = c.old[key]
+	}
+	return ok && !item.expiredAt(now) && !item.negative
+}
+
+// RangeKeys returns live keys from from (inclusive) to to (exclusive) in ascending order,
+// empty to is no upper bound. Returns ErrNotOrdered for a cache created without WithOrderedKeys.
+func (c *Cache[T]) RangeKeys(from, to string) ([]string, error) {
+	c.RLock()
+	defer c.RUnlock()
+	if c.ordered == nil {
+		return nil, ErrNotOrdered
+	}
+	keys := []string{}
+	now := c.now()
+	for _, k := range c.ordered.keys[sort.SearchStrings(c.ordered.keys, from):] {
+		if to != "" && k >= to {
+			break
+		}
+		if c.live(k, now) {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
+// MinKey returns the smallest live key, ErrKeyNotFound if there are no keys.
+// Returns ErrNotOrdered for a cache created without WithOrderedKeys.
+func (c *Cache[T]) MinKey() (string, error) {
+	c.RLock()
+	defer c.RUnlock()
+	if c.ordered == nil {
+		return "", ErrNotOrdered
+	}
+	now := c.now()
+	for _, k := range c.ordered.keys {
+		if c.live(k, now) {
+			return k, nil
+		}
+	}
+	return "", ErrKeyNotFound
+}
+
+// MaxKey returns the largest live key, ErrKeyNotFound if there are no keys.
+// Returns ErrNotOrdered for a cache created without WithOrderedKeys.
+func (c *Cache[T]) MaxKey() (string, error) {
+	c.RLock()
+	defer c.RUnlock()
+	if c.ordered == nil {
+		return "", ErrNotOrdered
+	}
+	now := c.now()
+	for i := len(c.ordered.keys) - 1; i >= 0; i-- {
+		if k := c.ordered.keys[i]; c.live(k, now) {
+			return k, nil
+		}
+	}
+	return "", ErrKeyNotFound
+}
@@ -0,0 +1,59 @@
+package mcache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache/clocktest"
+)
+
+func TestWithOrderedKeys(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	cache := NewCache(WithClock[int](clock), WithOrderedKeys[int]())
+	for _, minute := range []int{5, 1, 3, 2, 4} {
+		require.True(t, cache.Set(fmt.Sprintf("12:%02d", minute), minute, time.Duration(minute)*time.Minute))
+	}
+	require.True(t, cache.SetNegative("12:00", 0))
+
+	keys, err := cache.RangeKeys("12:02", "12:05")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"12:02", "12:03", "12:04"}, keys)
+	keys, err = cache.RangeKeys("", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"12:01", "12:02", "12:03", "12:04", "12:05"}, keys, "negative entries are skipped")
+	minKey, err := cache.MinKey()
+	require.NoError(t, err)
+	assert.Equal(t, "12:01", minKey)
+
+	clock.Advance(90 * time.Second)
+	minKey, err = cache.MinKey()
+	require.NoError(t, err)
+	assert.Equal(t, "12:02", minKey, "expired keys are skipped")
+	require.NoError(t, cache.Del("12:05"))
+	maxKey, err := cache.MaxKey()
+	require.NoError(t, err)
+	assert.Equal(t, "12:04", maxKey)
+
+	cache.Cleanup()
+	require.True(t, cache.Set("12:06", 6, 0))
+	require.True(t, cache.Set("12:05", 5, 0))
+	keys, err = cache.RangeKeys("12:03", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"12:03", "12:04", "12:05", "12:06"}, keys)
+
+	require.NoError(t, cache.Del("12:03"))
+	require.NoError(t, cache.Del("12:04"))
+	require.True(t, cache.Set("12:07", 7, 0))
+	assert.Equal(t, []string{"12:00", "12:02", "12:05", "12:06", "12:07"}, cache.ordered.keys, "stale keys are compacted")
+
+	require.NoError(t, cache.Clear())
+	_, err = cache.MaxKey()
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	_, err = NewCache[int]().RangeKeys("a", "b")
+	assert.ErrorIs(t, err, ErrNotOrdered)
+}
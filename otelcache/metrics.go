@@ -0,0 +1,125 @@
+// Package otelcache provides OpenTelemetry instrumentation for mcache.
+package otelcache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/parMaster/mcache"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// attribute keys of recorded measurements
+const (
+	OperationKey = attribute.Key("mcache.operation")
+	ResultKey    = attribute.Key("mcache.result")
+)
+
+// results of operations
+const (
+	resultHit      = "hit"
+	resultMiss     = "miss"
+	resultExpired  = "expired"
+	resultOK       = "ok"
+	resultRejected = "rejected"
+	resultError    = "error"
+)
+
+// instrumented is a Cacher decorator recording metrics of every call.
+type instrumented[T any] struct {
+	mcache.Cacher[T]
+	operations metric.Int64Counter
+	duration   metric.Float64Histogram
+}
+
+// Wrap returns a Cacher recording every call to c with the meter:
+// "mcache.operations" counter and "mcache.operation.duration" histogram (seconds),
+// both with mcache.operation attribute, the counter also has mcache.result attribute.
+func Wrap[T any](c mcache.Cacher[T], meter metric.Meter) (mcache.Cacher[T], error) {
+	operations, err := meter.Int64Counter("mcache.operations",
+		metric.WithDescription("Number of cache operations."))
+	if err != nil {
+		return nil, err
+	}
+	duration, err := meter.Float64Histogram("mcache.operation.duration",
+		metric.WithDescription("Duration of cache operations."), metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	return &instrumented[T]{Cacher: c, operations: operations, duration: duration}, nil
+}
+
+func (i *instrumented[T]) record(op string, start time.Time, result string) {
+	ctx := context.Background()
+	opAttr := OperationKey.String(op)
+	i.duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(opAttr))
+	i.operations.Add(ctx, 1, metric.WithAttributes(opAttr, ResultKey.String(result)))
+}
+
+// errResult maps cache errors to result attribute values
+func errResult(err error) string {
+	switch {
+	case err == nil:
+		return resultHit
+	case errors.Is(err, mcache.ErrKeyNotFound):
+		return resultMiss
+	case errors.Is(err, mcache.ErrExpired):
+		return resultExpired
+	}
+	return resultError
+}
+
+func (i *instrumented[T]) Set(key string, value T, ttl time.Duration) bool {
+	start := time.Now()
+	ok := i.Cacher.Set(key, value, ttl)
+	result := resultOK
+	if !ok {
+		result = resultRejected
+	}
+	i.record("set", start, result)
+	return ok
+}
+
+func (i *instrumented[T]) Get(key string) (T, error) {
+	start := time.Now()
+	v, err := i.Cacher.Get(key)
+	i.record("get", start, errResult(err))
+	return v, err
+}
+
+func (i *instrumented[T]) Has(key string) (bool, error) {
+	start := time.Now()
+	ok, err := i.Cacher.Has(key)
+	i.record("has", start, errResult(err))
+	return ok, err
+}
+
+func (i *instrumented[T]) Del(key string) error {
+	start := time.Now()
+	err := i.Cacher.Del(key)
+	result := errResult(err)
+	if err == nil {
+		result = resultOK
+	}
+	i.record("del", start, result)
+	return err
+}
+
+func (i *instrumented[T]) Cleanup() {
+	start := time.Now()
+	i.Cacher.Cleanup()
+	i.record("cleanup", start, resultOK)
+}
+
+func (i *instrumented[T]) Clear() error {
+	start := time.Now()
+	err := i.Cacher.Clear()
+	result := resultOK
+	if err != nil {
+		result = resultError
+	}
+	i.record("clear", start, result)
+	return err
+}
@@ -0,0 +1,80 @@
+package otelcache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/parMaster/mcache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestWrap(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	cache := mcache.NewCache(mcache.WithMaxEntries[string](1), mcache.WithFullPolicy[string](mcache.Reject))
+	c, err := Wrap[string](cache, provider.Meter("test"))
+	require.NoError(t, err)
+
+	assert.True(t, c.Set("key", "value", time.Minute))
+	assert.False(t, c.Set("other", "value", time.Minute))
+	v, err := c.Get("key")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", v)
+	_, err = c.Get("noSuchKey")
+	assert.ErrorIs(t, err, mcache.ErrKeyNotFound)
+	has, err := c.Has("key")
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.NoError(t, c.Del("key"))
+	assert.Error(t, c.Del("key"))
+	c.Cleanup()
+	assert.NoError(t, c.Clear())
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+
+	counts := map[[2]string]int64{}
+	var histCount uint64
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		switch data := m.Data.(type) {
+		case metricdata.Sum[int64]:
+			assert.Equal(t, "mcache.operations", m.Name)
+			for _, dp := range data.DataPoints {
+				op, _ := dp.Attributes.Value(OperationKey)
+				res, _ := dp.Attributes.Value(ResultKey)
+				counts[[2]string{op.AsString(), res.AsString()}] = dp.Value
+			}
+		case metricdata.Histogram[float64]:
+			assert.Equal(t, "mcache.operation.duration", m.Name)
+			for _, dp := range data.DataPoints {
+				_, ok := dp.Attributes.Value(ResultKey)
+				assert.False(t, ok)
+				histCount += dp.Count
+			}
+		}
+	}
+
+	assert.Equal(t, map[[2]string]int64{
+		{"set", "ok"}:       1,
+		{"set", "rejected"}: 1,
+		{"get", "hit"}:      1,
+		{"get", "miss"}:     1,
+		{"has", "hit"}:      1,
+		{"del", "ok"}:       1,
+		{"del", "miss"}:     1,
+		{"cleanup", "ok"}:   1,
+		{"clear", "ok"}:     1,
+	}, counts)
+	assert.Equal(t, uint64(9), histCount)
+}
+
+func TestErrResult(t *testing.T) {
+	assert.Equal(t, "expired", errResult(mcache.ErrExpired))
+	assert.Equal(t, "error", errResult(assert.AnError))
+}
@@ -0,0 +1,125 @@
+package otelcache
+
+import (
+	"context"
+	"time"
+
+	"github.com/parMaster/mcache"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// KeyKey is the attribute key of the cache key in spans and span events.
+const KeyKey = attribute.Key("mcache.key")
+
+// Tracer is a Cacher decorator with context-aware methods, creating a span
+// (or adding a span event, see WithSpanEvents) for every call.
+type Tracer[T any] struct {
+	cache  mcache.Cacher[T]
+	tracer trace.Tracer
+	events bool
+}
+
+// TracerOption configures Tracer.
+type TracerOption func(*tracerOptions)
+
+type tracerOptions struct {
+	events bool
+}
+
+// WithSpanEvents makes Tracer annotate the span found in the context with an event
+// instead of starting a new span for every call. Loader calls always get a span.
+func WithSpanEvents() TracerOption {
+	return func(o *tracerOptions) {
+		o.events = true
+	}
+}
+
+// NewTracer creates a Tracer for c.
+func NewTracer[T any](c mcache.Cacher[T], tracer trace.Tracer, opts ...TracerOption) *Tracer[T] {
+	o := tracerOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Tracer[T]{cache: c, tracer: tracer, events: o.events}
+}
+
+// trace starts a span or adds an event, returned func ends it with the result
+func (t *Tracer[T]) trace(ctx context.Context, op, key string) func(result string) {
+	attrs := []attribute.KeyValue{OperationKey.String(op), KeyKey.String(key)}
+	if t.events {
+		span := trace.SpanFromContext(ctx)
+		return func(result string) {
+			span.AddEvent("mcache."+op, trace.WithAttributes(append(attrs, ResultKey.String(result))...))
+		}
+	}
+	_, span := t.tracer.Start(ctx, "mcache."+op, trace.WithAttributes(attrs...))
+	return func(result string) {
+		span.SetAttributes(ResultKey.String(result))
+		span.End()
+	}
+}
+
+// Get calls Get of the underlying cache.
+func (t *Tracer[T]) Get(ctx context.Context, key string) (T, error) {
+	end := t.trace(ctx, "get", key)
+	v, err := t.cache.Get(key)
+	end(errResult(err))
+	return v, err
+}
+
+// Set calls Set of the underlying cache.
+func (t *Tracer[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) bool {
+	end := t.trace(ctx, "set", key)
+	ok := t.cache.Set(key, value, ttl)
+	if ok {
+		end(resultOK)
+	} else {
+		end(resultRejected)
+	}
+	return ok
+}
+
+// Has calls Has of the underlying cache.
+func (t *Tracer[T]) Has(ctx context.Context, key string) (bool, error) {
+	end := t.trace(ctx, "has", key)
+	ok, err := t.cache.Has(key)
+	end(errResult(err))
+	return ok, err
+}
+
+// Del calls Del of the underlying cache.
+func (t *Tracer[T]) Del(ctx context.Context, key string) error {
+	end := t.trace(ctx, "del", key)
+	err := t.cache.Del(key)
+	if err == nil {
+		end(resultOK)
+	} else {
+		end(errResult(err))
+	}
+	return err
+}
+
+// GetOrLoad returns cached value of the key, on miss it calls loader in a "mcache.load" span
+// and caches the loaded value with ttl. Loader error is recorded in the span and returned as is.
+func (t *Tracer[T]) GetOrLoad(ctx context.Context, key string, ttl time.Duration,
+	loader func(ctx context.Context) (T, error)) (T, error) {
+	v, err := t.Get(ctx, key)
+	if err == nil {
+		return v, nil
+	}
+
+	ctx, span := t.tracer.Start(ctx, "mcache.load", trace.WithAttributes(KeyKey.String(key)))
+	v, err = loader(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.End()
+		return v, err
+	}
+	span.End()
+
+	t.Set(ctx, key, v, ttl)
+	return v, nil
+}
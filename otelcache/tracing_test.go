@@ -0,0 +1,100 @@
+package otelcache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/parMaster/mcache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracer(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	tr := NewTracer[string](mcache.NewCache[string](), provider.Tracer("test"))
+	ctx := context.Background()
+
+	assert.True(t, tr.Set(ctx, "key", "value", time.Minute))
+	v, err := tr.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", v)
+	has, err := tr.Has(ctx, "key")
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.NoError(t, tr.Del(ctx, "key"))
+
+	calls := 0
+	loader := func(ctx context.Context) (string, error) {
+		calls++
+		return "loaded", nil
+	}
+	v, err = tr.GetOrLoad(ctx, "key", time.Minute, loader)
+	assert.NoError(t, err)
+	assert.Equal(t, "loaded", v)
+	v, err = tr.GetOrLoad(ctx, "key", time.Minute, loader)
+	assert.NoError(t, err)
+	assert.Equal(t, "loaded", v)
+	assert.Equal(t, 1, calls)
+
+	_, err = tr.GetOrLoad(ctx, "failing", time.Minute, func(ctx context.Context) (string, error) {
+		return "", errors.New("upstream is down")
+	})
+	assert.EqualError(t, err, "upstream is down")
+
+	var names []string
+	var failed sdktrace.ReadOnlySpan
+	for _, s := range rec.Ended() {
+		names = append(names, s.Name())
+		if s.Status().Code == codes.Error {
+			failed = s
+		}
+	}
+	assert.Equal(t, []string{"mcache.set", "mcache.get", "mcache.has", "mcache.del",
+		"mcache.get", "mcache.load", "mcache.set", "mcache.get",
+		"mcache.get", "mcache.load"}, names)
+	require.NotNil(t, failed)
+	assert.Equal(t, "mcache.load", failed.Name())
+
+	res, ok := attrValue(rec.Ended()[4], ResultKey)
+	assert.True(t, ok)
+	assert.Equal(t, "miss", res)
+}
+
+func TestTracerSpanEvents(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	tr := NewTracer[string](mcache.NewCache[string](), provider.Tracer("test"), WithSpanEvents())
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "request")
+	tr.Set(ctx, "key", "value", 0)
+	tr.Get(ctx, "key")
+	tr.Get(ctx, "noSuchKey")
+	span.End()
+
+	require.Len(t, rec.Ended(), 1)
+	events := rec.Ended()[0].Events()
+	require.Len(t, events, 3)
+	assert.Equal(t, "mcache.set", events[0].Name)
+	assert.Equal(t, "mcache.get", events[2].Name)
+	for _, a := range events[2].Attributes {
+		if a.Key == ResultKey {
+			assert.Equal(t, "miss", a.Value.AsString())
+		}
+	}
+}
+
+func attrValue(s sdktrace.ReadOnlySpan, key attribute.Key) (string, bool) {
+	for _, a := range s.Attributes() {
+		if a.Key == key {
+			return a.Value.AsString(), true
+		}
+	}
+	return "", false
+}
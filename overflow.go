@@ -0,0 +1,175 @@
+package mcache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1" //nolint:gosec // file names, not security
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WithOverflow is a functional option for spilling entries evicted WithMaxEntries to the store,
+// like DiskStore, instead of dropping them. Spilled entries are reloaded transparently when accessed
+// with Get, Has or Del, and keep their expiration times. Keys of spilled entries are kept in memory.
+// Spilling and reloading are done under the write lock, so the store must be fast, like a local SSD.
+// Store errors are logged, see WithLogger, and the entry is lost.
+// It can't be combined with WithCopyOnWrite and WithSyncMapBackend.
+func WithOverflow[T any](store Store[T]) func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.overflow = store
+		c.spilled = make(map[string]int64)
+	}
+}
+
+// spill saves the evicted item to the overflow store, must be called under lock
+func (c *Cache[T]) spill(key string, item CacheItem[T]) {
+	if c.overflow == nil {
+		return
+	}
+	var ttl time.Duration
+	if item.expiration != 0 {
+		ttl = time.Duration(max(item.expiration-c.now(), 1))
+	}
+	if err := c.overflow.Save(context.Background(), key, item.value, ttl); err != nil {
+		c.logger.Warn("mcache overflow: failed to spill", "key", key, "err", err)
+		return
+	}
+	c.spilled[key] = item.expiration
+}
+
+// unspill moves the spilled item of the key back to memory, must be called under lock
+func (c *Cache[T]) unspill(key string) (CacheItem[T], error) {
+	expiration, ok := c.spilled[key]
+	if !ok {
+		return CacheItem[T]{}, c.keyError(ErrKeyNotFound, key)
+	}
+	if expiration != 0 && expiration < c.now() {
+		c.dropSpilled(key)
+		c.evicted(1)
+		return CacheItem[T]{}, c.keyError(ErrExpired, key)
+	}
+
+	v, _, err := c.overflow.Load(context.Background(), key)
+	c.dropSpilled(key)
+	if err != nil {
+		c.logger.Warn("mcache overflow: failed to reload", "key", key, "err", err)
+		return CacheItem[T]{}, c.keyError(ErrKeyNotFound, key)
+	}
+	item := c.newItem(v, time.Time{})
+	item.expiration = expiration
+	c.store(key, item)
+	return item, nil
+}
+
+// dropSpilled deletes the spilled entry of the key from the store, must be called under lock
+func (c *Cache[T]) dropSpilled(key string) {
+	if _, ok := c.spilled[key]; !ok {
+		return
+	}
+	delete(c.spilled, key)
+	if err := c.overflow.Delete(context.Background(), key); err != nil {
+		c.logger.Warn("mcache overflow: failed to delete", "key", key, "err", err)
+	}
+}
+
+// spilledLive checks if the key has a live spilled entry, must be called under lock
+func (c *Cache[T]) spilledLive(key string) bool {
+	expiration, ok := c.spilled[key]
+	return ok && (expiration == 0 || expiration >= c.now())
+}
+
+// dropSpilledWhere deletes spilled entries of keys matching fn, or expired ones, must be called under lock
+func (c *Cache[T]) dropSpilledWhere(fn func(key string) bool) {
+	now := c.now()
+	for key, expiration := range c.spilled {
+		if (fn != nil && fn(key)) || (expiration != 0 && expiration < now) {
+			c.dropSpilled(key)
+		}
+	}
+}
+
+// DiskStore is a Store keeping every entry in a file of the directory, written with the codec.
+// It's meant for WithOverflow, a single cache should use a directory.
+type DiskStore[T any] struct {
+	dir   string
+	codec Codec[T]
+}
+
+// NewDiskStore is a constructor for DiskStore in the directory, created if it doesn't exist.
+// Files are written with GobCodec, if codec is nil.
+func NewDiskStore[T any](dir string, codec Codec[T]) (*DiskStore[T], error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create store directory: %w", err)
+	}
+	if codec == nil {
+		codec = GobCodec[T]{}
+	}
+	return &DiskStore[T]{dir: dir, codec: codec}, nil
+}
+
+// Load implements Store, expired entries are not found
+func (s *DiskStore[T]) Load(_ context.Context, key string) (value T, ttl time.Duration, err error) {
+	data, err := os.ReadFile(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return value, 0, ErrKeyNotFound
+	}
+	if err != nil {
+		return value, 0, fmt.Errorf("failed to read %q: %w", key, err)
+	}
+	var e Entry[T]
+	if err = s.codec.NewDecoder(bytes.NewReader(data)).Decode(&e); err != nil {
+		return value, 0, fmt.Errorf("failed to decode %q: %w", key, err)
+	}
+	if e.Key != key {
+		return value, 0, ErrKeyNotFound
+	}
+	if !e.Expiration.IsZero() {
+		if ttl = time.Until(e.Expiration); ttl <= 0 {
+			return value, 0, ErrKeyNotFound
+		}
+	}
+	return e.Value, ttl, nil
+}
+
+// Save implements Store, file is written to a temporary file first and renamed
+func (s *DiskStore[T]) Save(_ context.Context, key string, value T, ttl time.Duration) error {
+	e := Entry[T]{Key: key, Value: value}
+	if ttl > 0 {
+		e.Expiration = time.Now().Add(ttl)
+	}
+	var buf bytes.Buffer
+	if err := s.codec.NewEncoder(&buf).Encode(&e); err != nil {
+		return fmt.Errorf("failed to encode %q: %w", key, err)
+	}
+	f, err := os.CreateTemp(s.dir, ".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to write %q: %w", key, err)
+	}
+	defer os.Remove(f.Name()) // no-op after successful rename
+	if _, err = f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %q: %w", key, err)
+	}
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("failed to write %q: %w", key, err)
+	}
+	return os.Rename(f.Name(), s.path(key))
+}
+
+// Delete implements Store, missing key is not an error
+func (s *DiskStore[T]) Delete(_ context.Context, key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete %q: %w", key, err)
+	}
+	return nil
+}
+
+// path returns the file of the key, named by the key hash, as keys may contain any characters
+func (s *DiskStore[T]) path(key string) string {
+	h := sha1.Sum([]byte(key)) //nolint:gosec // file names, not security
+	return filepath.Join(s.dir, hex.EncodeToString(h[:]))
+}
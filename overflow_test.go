@@ -0,0 +1,126 @@
+package mcache
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache/clocktest"
+)
+
+func TestWithOverflow(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	store, err := NewDiskStore[int](t.TempDir(), nil)
+	require.NoError(t, err)
+	cache := NewCache(WithMaxEntries[int](5), WithOverflow[int](store), WithClock[int](clock))
+
+	for i := 0; i < 20; i++ {
+		ttl := time.Duration(0)
+		if i%2 == 0 {
+			ttl = time.Hour
+		}
+		require.True(t, cache.Set(strconv.Itoa(i), i, ttl))
+		clock.Advance(time.Millisecond)
+	}
+	assert.Equal(t, 5, cache.Stats().Entries)
+	assert.Len(t, cache.spilled, 15)
+
+	// spilled entries are reloaded, with their expiration
+	for i := 0; i < 20; i++ {
+		v, err := cache.Get(strconv.Itoa(i))
+		require.NoError(t, err, i)
+		assert.Equal(t, i, v)
+	}
+	info, err := cache.EntryInfo("19")
+	require.NoError(t, err)
+	assert.True(t, info.Expiration.IsZero())
+
+	// spilled live key is not replaced by Add, it's replaced by Set
+	assert.ErrorIs(t, cache.Add("0", 100, 0), ErrKeyExists)
+	assert.True(t, cache.SetBytes([]byte("0"), 100, 0))
+	assert.NotContains(t, cache.spilled, "0")
+	v, err := cache.Get("0")
+	require.NoError(t, err)
+	assert.Equal(t, 100, v)
+
+	require.NoError(t, cache.Del("2"))
+	_, err = cache.Get("2")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	n, err := cache.DelPrefix("1")
+	require.NoError(t, err)
+	assert.Equal(t, 11, n)
+
+	// expired spilled entries are removed by Cleanup
+	clock.Advance(2 * time.Hour)
+	cache.Cleanup()
+	for k, exp := range cache.spilled {
+		assert.Zero(t, exp, k)
+	}
+	_, err = cache.GetBytes([]byte("4"))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	require.NoError(t, cache.Clear())
+	assert.Empty(t, cache.spilled)
+	files, err := os.ReadDir(store.dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestWithOverflowExpired(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	cache := NewCache(WithMaxEntries[int](1), WithOverflow[int](newMapStore[int]()), WithClock[int](clock))
+	cache.Set("a", 1, time.Second)
+	cache.Set("b", 2, 0)
+	clock.Advance(2 * time.Second)
+
+	assert.True(t, cache.Set("a", 3, 0), "expired spilled key is replaced")
+	_, err := cache.Get("b")
+	require.NoError(t, err)
+	cache.Set("c", 4, time.Second) // spills b
+	cache.Get("b")                 //nolint:errcheck // spills c
+	clock.Advance(2 * time.Second)
+	_, err = cache.Get("c")
+	assert.ErrorIs(t, err, ErrExpired)
+
+	// entry is lost if the store fails
+	store := newMapStore[int]()
+	cache = NewCache(WithMaxEntries[int](1), WithOverflow[int](store))
+	cache.Set("a", 1, 0)
+	store.err = errors.New("store failed")
+	cache.Set("b", 2, 0)
+	_, err = cache.Get("a")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestDiskStore(t *testing.T) {
+	store, err := NewDiskStore[string](t.TempDir(), JSONCodec[string]{})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, store.Save(ctx, "key/with:chars", "value", time.Hour))
+	v, ttl, err := store.Load(ctx, "key/with:chars")
+	require.NoError(t, err)
+	assert.Equal(t, "value", v)
+	assert.InDelta(t, time.Hour, ttl, float64(time.Second))
+
+	require.NoError(t, store.Save(ctx, "expired", "value", time.Nanosecond))
+	time.Sleep(time.Millisecond)
+	_, _, err = store.Load(ctx, "expired")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	require.NoError(t, store.Delete(ctx, "key/with:chars"))
+	require.NoError(t, store.Delete(ctx, "key/with:chars"))
+	_, _, err = store.Load(ctx, "key/with:chars")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	require.NoError(t, os.WriteFile(store.path("broken"), []byte("{"), 0o600))
+	_, _, err = store.Load(ctx, "broken")
+	assert.Error(t, err)
+}
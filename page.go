@@ -0,0 +1,88 @@
+package mcache
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// KeysPage returns up to limit live keys in ascending order starting at the cursor, and the cursor
+// of the next page, empty if it's the last one. Empty cursor starts at the beginning.
+// Pages are stable across changes of the cache: keys set after a page was returned are listed
+// on later pages, if they sort after it. Keys are not materialized all at once: WithOrderedKeys
+// the page is read from the sorted keys, otherwise all keys are scanned keeping only limit smallest ones.
+// Keys of entries spilled WithOverflow are included, negative entries are not.
+func (c *Cache[T]) KeysPage(cursor string, limit int) (keys []string, next string) {
+	if limit <= 0 {
+		return []string{}, cursor
+	}
+	c.RLock()
+	defer c.RUnlock()
+	now := c.now()
+
+	if c.ordered != nil && c.spilled == nil {
+		keys = make([]string, 0, limit)
+		for _, k := range c.ordered.keys[sort.SearchStrings(c.ordered.keys, cursor):] {
+			if !c.live(k, now) {
+				continue
+			}
+			if len(keys) == limit {
+				return keys, nextCursor(keys)
+			}
+			keys = append(keys, k)
+		}
+		return keys, ""
+	}
+
+	page := &keyHeap{}
+	more := false
+	add := func(k string) {
+		switch {
+		case k < cursor:
+		case page.Len() < limit:
+			heap.Push(page, k)
+		case k < (*page)[0]:
+			(*page)[0] = k
+			heap.Fix(page, 0)
+			more = true
+		default:
+			more = true
+		}
+	}
+	for _, data := range c.dataMaps() {
+		for k, v := range data {
+			if !v.expiredAt(now) && !v.negative {
+				add(k)
+			}
+		}
+	}
+	for k := range c.spilled {
+		if c.spilledLive(k) {
+			add(k)
+		}
+	}
+	keys = []string(*page)
+	sort.Strings(keys)
+	if more {
+		return keys, nextCursor(keys)
+	}
+	return keys, ""
+}
+
+// nextCursor returns the cursor of the page after keys, the smallest string greater than the last key
+func nextCursor(keys []string) string {
+	return keys[len(keys)-1] + "\x00"
+}
+
+// keyHeap is a max-heap of keys, keeping the smallest keys of a page
+type keyHeap []string
+
+func (h keyHeap) Len() int           { return len(h) }
+func (h keyHeap) Less(i, j int) bool { return h[i] > h[j] }
+func (h keyHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *keyHeap) Push(x any)        { *h = append(*h, x.(string)) }
+func (h *keyHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
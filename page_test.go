@@ -0,0 +1,54 @@
+package mcache
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeysPage(t *testing.T) {
+	for name, opt := range map[string]func(*Cache[int]){
+		"scan":    func(*Cache[int]) {},
+		"ordered": WithOrderedKeys[int](),
+	} {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache(opt)
+			for i := 0; i < 25; i++ {
+				require.True(t, cache.Set(fmt.Sprintf("key%02d", i), i, 0))
+			}
+			require.True(t, cache.SetNegative("key00a", 0))
+
+			var all []string
+			cursor, pages := "", 0
+			for {
+				keys, next := cache.KeysPage(cursor, 10)
+				all = append(all, keys...)
+				pages++
+				if next == "" {
+					break
+				}
+				if pages == 1 {
+					require.NoError(t, cache.Del("key05"))     // on the returned page
+					require.True(t, cache.Set("key15a", 0, 0)) // after the returned page
+				}
+				cursor = next
+			}
+			assert.Equal(t, 3, pages)
+			assert.Len(t, all, 26)
+			assert.Equal(t, "key00", all[0])
+			assert.Equal(t, "key15a", all[16])
+			assert.IsIncreasing(t, all)
+
+			keys, next := cache.KeysPage("", 25)
+			assert.Len(t, keys, 25)
+			assert.Empty(t, next, "exactly the last page")
+			keys, next = cache.KeysPage("key24", 10)
+			assert.Equal(t, []string{"key24"}, keys)
+			assert.Empty(t, next)
+			keys, _ = cache.KeysPage("", 0)
+			assert.Empty(t, keys)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+// Package peercache provides a groupcache-style cache shared by a set of HTTP peers.
+// Every key is owned by a single peer, picked by consistent hashing. A miss is fetched
+// from the owner, which loads the key with the loader and caches it, so each key is loaded
+// once per group instead of once per process. If the owner can't be reached, the key is
+// loaded locally, but keys the owner reports missing are not loaded again.
+package peercache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/parMaster/mcache"
+	"github.com/parMaster/mcache/ring"
+)
+
+// BasePath is a path the Group handler serves keys at, {peer}{BasePath}{key}
+const BasePath = "/_mcache/"
+
+// replicas is a number of virtual nodes of every peer in the ring
+const replicas = 50
+
+// Group is a mcache.LoadingCache, which fetches missing keys from their owner peer
+type Group[T any] struct {
+	*mcache.LoadingCache[T]
+	self   string
+	peers  atomic.Pointer[ring.Ring] // replaced as a whole, so keys never go to a partial ring
+	loader mcache.Loader[T]
+	client *http.Client
+}
+
+// response is a JSON body of a peer response, ttl in nanoseconds, 0 if the key doesn't expire
+type response[T any] struct {
+	Value T             `json:"value"`
+	TTL   time.Duration `json:"ttl"`
+}
+
+// ownerKey marks the context of a load requested by a peer, such loads are never forwarded
+type ownerKey struct{}
+
+// New is a constructor for Group, self is a base URL of this peer, like "http://10.0.0.1:8080",
+// as other peers know it. Options are the same as for mcache.NewCache.
+func New[T any](self string, loader mcache.Loader[T], options ...func(*mcache.Cache[T])) *Group[T] {
+	g := &Group[T]{
+		self:   strings.TrimSuffix(self, "/"),
+		loader: loader,
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+	g.peers.Store(ring.New(replicas))
+	g.LoadingCache = mcache.NewLoadingCache(g.load, options...)
+	return g
+}
+
+// SetPeers sets base URLs of all peers of the group, including self.
+// The new ring replaces the old one atomically, concurrent loads see either of them.
+func (g *Group[T]) SetPeers(peers ...string) {
+	r := ring.New(replicas)
+	for _, p := range peers {
+		r.Add(strings.TrimSuffix(p, "/"))
+	}
+	g.peers.Store(r)
+}
+
+// SetClient sets the HTTP client for peer requests, the default one has 5 seconds timeout
+func (g *Group[T]) SetClient(client *http.Client) {
+	g.client = client
+}
+
+// ServeHTTP serves keys owned by this peer to other peers, mount it at BasePath
+func (g *Group[T]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, BasePath)
+	if key == "" || key == r.URL.Path {
+		http.Error(w, "no key", http.StatusBadRequest)
+		return
+	}
+
+	v, err := g.Get(context.WithValue(r.Context(), ownerKey{}, true), key)
+	if err != nil {
+		status := http.StatusBadGateway
+		switch {
+		case errors.Is(err, mcache.ErrKeyNotFound), errors.Is(err, mcache.ErrNegativeCached):
+			status = http.StatusNotFound // the key doesn't exist, unlike a failed load
+		case errors.Is(err, mcache.ErrClosed):
+			status = http.StatusServiceUnavailable
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	var ttl time.Duration
+	if info, err := g.EntryInfo(key); err == nil && !info.Expiration.IsZero() {
+		ttl = max(time.Until(info.Expiration), 1)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response[T]{Value: v, TTL: ttl}) //nolint:errcheck // client is gone
+}
+
+// load is the loader of the group cache: keys owned by other peers are fetched from them,
+// falling back to the local loader if the owner fails, but not if it reports the key missing
+func (g *Group[T]) load(ctx context.Context, key string) (T, time.Duration, error) {
+	if owner := g.peers.Load().Get(key); owner != "" && owner != g.self && ctx.Value(ownerKey{}) == nil {
+		v, ttl, err := g.fetch(ctx, owner, key)
+		if err == nil || errors.Is(err, mcache.ErrKeyNotFound) {
+			return v, ttl, err
+		}
+	}
+	return g.loader(ctx, key)
+}
+
+// fetch requests the key from the peer
+func (g *Group[T]) fetch(ctx context.Context, peer, key string) (v T, ttl time.Duration, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, peer+BasePath+url.PathEscape(key), http.NoBody)
+	if err != nil {
+		return v, 0, err
+	}
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return v, 0, fmt.Errorf("failed to fetch %q from %s: %w", key, peer, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return v, 0, fmt.Errorf("%w: %q on %s", mcache.ErrKeyNotFound, key, peer)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return v, 0, fmt.Errorf("failed to fetch %q from %s: %s", key, peer, resp.Status)
+	}
+	var r response[T]
+	if err = json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return v, 0, fmt.Errorf("failed to decode %q from %s: %w", key, peer, err)
+	}
+	return r.Value, r.TTL, nil
+}
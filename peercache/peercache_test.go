@@ -0,0 +1,151 @@
+package peercache
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache"
+)
+
+// cluster starts n peers sharing a loader, returns the groups and the number of loader calls
+func cluster(t *testing.T, n int) ([]*Group[string], *atomic.Int32) {
+	loads := &atomic.Int32{}
+	loader := func(_ context.Context, key string) (string, time.Duration, error) {
+		loads.Add(1)
+		switch {
+		case key == "fail":
+			return "", 0, errors.New("load failed")
+		case strings.HasPrefix(key, "missing"):
+			return "", 0, mcache.ErrKeyNotFound
+		}
+		return "value of " + key, time.Minute, nil
+	}
+
+	groups := make([]*Group[string], n)
+	urls := make([]string, n)
+	for i := range groups {
+		mux := http.NewServeMux()
+		srv := httptest.NewServer(mux)
+		t.Cleanup(srv.Close)
+		groups[i] = New(srv.URL, loader)
+		mux.Handle(BasePath, groups[i])
+		urls[i] = srv.URL
+	}
+	for _, g := range groups {
+		g.SetPeers(urls...)
+	}
+	return groups, loads
+}
+
+func TestGroup(t *testing.T) {
+	groups, loads := cluster(t, 3)
+	ctx := context.Background()
+
+	// every key is loaded once, by its owner, no matter which peer is asked
+	for i := 0; i < 30; i++ {
+		key := "key" + strconv.Itoa(i)
+		for _, g := range groups {
+			v, err := g.Get(ctx, key)
+			require.NoError(t, err)
+			assert.Equal(t, "value of "+key, v)
+		}
+	}
+	assert.Equal(t, int32(30), loads.Load())
+
+	// ttl of the owner is kept
+	info, err := groups[0].EntryInfo("key1")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), info.Expiration, 5*time.Second)
+
+	// loader errors are returned
+	_, err = groups[1].Get(ctx, "fail")
+	assert.Error(t, err)
+
+	// keys missing on their owner are not loaded again locally, one load per Get
+	loads.Store(0)
+	for i := 0; i < 30; i++ {
+		key := "missing" + strconv.Itoa(i)
+		for _, g := range groups {
+			_, err := g.Get(ctx, key)
+			require.ErrorIs(t, err, mcache.ErrKeyNotFound)
+		}
+	}
+	assert.Equal(t, int32(90), loads.Load())
+}
+
+func TestGroupSetPeersConcurrent(t *testing.T) {
+	groups, loads := cluster(t, 3)
+	urls := make([]string, len(groups))
+	for i, g := range groups {
+		urls[i] = g.self
+	}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				groups[0].SetPeers(urls...)
+			}
+		}
+	}()
+
+	// keys are always sent to their owners, never loaded by a peer seeing a partial ring
+	for i := 0; i < 30; i++ {
+		key := "key" + strconv.Itoa(i)
+		v, err := groups[0].Get(context.Background(), key)
+		require.NoError(t, err)
+		assert.Equal(t, "value of "+key, v)
+		_, err = groups[1].Get(context.Background(), key)
+		require.NoError(t, err)
+	}
+	close(stop)
+	<-done
+	assert.Equal(t, int32(30), loads.Load())
+}
+
+func TestGroupOwnerDown(t *testing.T) {
+	groups, loads := cluster(t, 2)
+	groups[0].SetPeers(groups[0].self, "http://127.0.0.1:1")
+
+	// keys owned by the unreachable peer are loaded locally
+	for i := 0; i < 20; i++ {
+		key := "key" + strconv.Itoa(i)
+		v, err := groups[0].Get(context.Background(), key)
+		require.NoError(t, err)
+		assert.Equal(t, "value of "+key, v)
+	}
+	assert.Equal(t, int32(20), loads.Load())
+}
+
+func TestGroupHandler(t *testing.T) {
+	groups, _ := cluster(t, 1)
+	for _, tc := range []struct {
+		method, path string
+		status       int
+	}{
+		{http.MethodGet, BasePath + "key", http.StatusOK},
+		{http.MethodPost, BasePath + "key", http.StatusMethodNotAllowed},
+		{http.MethodGet, BasePath, http.StatusBadRequest},
+		{http.MethodGet, "/other", http.StatusBadRequest},
+		{http.MethodGet, BasePath + "fail", http.StatusBadGateway},
+		{http.MethodGet, BasePath + "missing", http.StatusNotFound},
+	} {
+		rec := httptest.NewRecorder()
+		groups[0].ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, http.NoBody))
+		assert.Equal(t, tc.status, rec.Code, tc.method+" "+tc.path)
+	}
+}
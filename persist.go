@@ -0,0 +1,201 @@
+package mcache
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// saveBatch is a number of entries copied under a single read lock by SaveTo
+const saveBatch = 1000
+
+// SaveTo streams all non-expired entries with their expiration times to w using the codec set WithCodec,
+// compressed if WithSnapshotCompression is set.
+// Entries are copied in small batches under the read lock, so the cache isn't blocked
+// while w is written, and the snapshot is never materialized in memory as a whole.
+// Entries set after SaveTo started may be missed.
+func (c *Cache[T]) SaveTo(w io.Writer) (err error) {
+	if c.compression != nil {
+		cw, werr := c.compression.NewWriter(w)
+		if werr != nil {
+			return fmt.Errorf("failed to start compression: %w", werr)
+		}
+		defer func() {
+			if cerr := cw.Close(); err == nil && cerr != nil {
+				err = fmt.Errorf("failed to finish compression: %w", cerr)
+			}
+		}()
+		w = cw
+	}
+
+	c.RLock()
+	keys := make([]string, 0, len(c.data)+len(c.old))
+	for _, data := range c.dataMaps() {
+		for k := range data {
+			keys = append(keys, k)
+		}
+	}
+	c.RUnlock()
+
+	enc := c.codec.NewEncoder(w)
+	batch := make([]Entry[T], 0, saveBatch)
+	for len(keys) > 0 {
+		n := min(saveBatch, len(keys))
+		batch = batch[:0]
+		c.RLock()
+		now := c.now()
+		for _, k := range keys[:n] {
+			v, ok := c.data[k]
+			if !ok {
+				v, ok = c.old[k]
+			}
+			if ok && !v.expiredAt(now) && !v.negative {
+				batch = append(batch, Entry[T]{Key: k, Value: v.value, Expiration: c.expirationTime(v)})
+			}
+		}
+		c.RUnlock()
+		keys = keys[n:]
+
+		for i := range batch {
+			if err := enc.Encode(&batch[i]); err != nil {
+				return fmt.Errorf("failed to encode key %q: %w", batch[i].Key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// LoadFrom reads entries written by SaveTo from r into the cache, replacing existing keys.
+// Entries expired since the snapshot was taken are skipped.
+// Entries decoded before an error are kept in the cache.
+func (c *Cache[T]) LoadFrom(r io.Reader) error {
+	if c.compression != nil {
+		cr, err := c.compression.NewReader(r)
+		if err != nil {
+			return fmt.Errorf("failed to start decompression: %w", err)
+		}
+		defer cr.Close()
+		r = cr
+	}
+
+	dec := c.codec.NewDecoder(r)
+	for {
+		var e Entry[T]
+		if err := dec.Decode(&e); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to decode snapshot: %w", err)
+		}
+		item := c.newItem(e.Value, e.Expiration)
+		if c.expired(item) {
+			continue
+		}
+		c.Lock()
+		if c.closed {
+			c.Unlock()
+			return ErrClosed
+		}
+		c.store(e.Key, item)
+		c.logSet(e.Key, item)
+		c.Unlock()
+	}
+}
+
+// Save writes all non-expired entries with their expiration times to the file, see SaveTo.
+// File is written to a temporary file first and renamed, so it's never left half-written.
+func (c *Cache[T]) Save(path string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	defer os.Remove(f.Name()) // no-op after successful rename
+
+	w := bufio.NewWriter(f)
+	if err = c.SaveTo(w); err != nil {
+		f.Close()
+		return err
+	}
+	if err = w.Flush(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// NewCacheFromFile creates a cache with options and loads entries saved by Save from the file.
+// Entries expired since the snapshot was taken are skipped.
+func NewCacheFromFile[T any](path string, options ...func(*Cache[T])) (*Cache[T], error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot file: %w", err)
+	}
+	defer f.Close()
+
+	c := NewCache(options...)
+	if err = c.LoadFrom(bufio.NewReader(f)); err != nil {
+		_ = c.Close() // stops background goroutines started by options
+		return nil, err
+	}
+	return c, nil
+}
+
+// WithPersistence is a functional option for saving the cache to the file every interval,
+// and restoring it from the file on construction, if the file exists.
+// Interval <= 0 disables periodic saves, the cache is saved only by Close.
+// Restore and save errors are logged, see WithLogger.
+func WithPersistence[T any](path string, interval time.Duration) func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.persistPath = path
+		c.addTask(interval, func() {
+			if err := c.Save(path); err != nil {
+				c.logger.Warn("mcache persistence: failed to save snapshot", "path", path, "err", err)
+			}
+		})
+	}
+}
+
+// WithRestore is a functional option for loading the snapshot saved to the file by Save or WithPersistence
+// on construction, skipping entries expired since it was saved, so a restarted process starts warm.
+// WithPersistence restores from its own file, WithRestore sets another one, like a snapshot shipped
+// with a deploy. A missing file is not an error, other errors are logged, see WithLogger, entries
+// loaded before the error are kept. Unless ignoreErrors is set, the error is returned by WaitWarmup as well.
+func WithRestore[T any](path string, ignoreErrors bool) func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.restorePath = path
+		c.strictRestore = !ignoreErrors
+	}
+}
+
+// restore loads the snapshot WithRestore or WithPersistence, missing file is not an error
+func (c *Cache[T]) restore() {
+	f, err := os.Open(c.restorePath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			c.logger.Warn("mcache persistence: failed to open snapshot", "path", c.restorePath, "err", err)
+			c.restoreFailed(fmt.Errorf("failed to open snapshot file: %w", err))
+		}
+		return
+	}
+	defer f.Close()
+	if err = c.LoadFrom(bufio.NewReader(f)); err != nil {
+		c.logger.Warn("mcache persistence: failed to restore snapshot", "path", c.restorePath, "err", err)
+		c.restoreFailed(err)
+		return
+	}
+	c.logger.Debug("mcache persistence: snapshot restored", "path", c.restorePath, "entries", len(c.data))
+}
+
+// restoreFailed keeps the restore error for WaitWarmup, unless errors are ignored WithRestore
+func (c *Cache[T]) restoreFailed(err error) {
+	if c.strictRestore {
+		c.restoreErr = err
+	}
+}
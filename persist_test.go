@@ -0,0 +1,211 @@
+package mcache
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testValue struct {
+	Name string
+	Tags []string
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.gob")
+
+	cache := NewCache[testValue]()
+	cache.Set("forever", testValue{Name: "forever", Tags: []string{"a", "b"}}, 0)
+	cache.Set("hour", testValue{Name: "hour"}, time.Hour)
+	cache.Set("short", testValue{Name: "short"}, 50*time.Millisecond)
+	cache.Set("expired", testValue{Name: "expired"}, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+
+	require.NoError(t, cache.Save(path))
+	hourInfo, err := cache.EntryInfo("hour")
+	require.NoError(t, err)
+
+	loaded, err := NewCacheFromFile[testValue](path, WithSize[testValue](10))
+	require.NoError(t, err)
+	assert.Equal(t, 3, loaded.Stats().Entries)
+
+	v, err := loaded.Get("forever")
+	assert.NoError(t, err)
+	assert.Equal(t, testValue{Name: "forever", Tags: []string{"a", "b"}}, v)
+
+	info, err := loaded.EntryInfo("hour")
+	assert.NoError(t, err)
+	assert.True(t, hourInfo.Expiration.Equal(info.Expiration))
+
+	_, err = loaded.Get("expired")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	// entries expired after Save are skipped on load
+	time.Sleep(50 * time.Millisecond)
+	loaded, err = NewCacheFromFile[testValue](path)
+	require.NoError(t, err)
+	_, err = loaded.Get("short")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	// no temporary files left
+	files, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestSaveAndLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := NewCacheFromFile[string](filepath.Join(dir, "noSuchFile"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	err = NewCache[string]().Save(filepath.Join(dir, "noSuchDir", "cache.gob"))
+	assert.Error(t, err)
+
+	garbage := filepath.Join(dir, "garbage")
+	require.NoError(t, os.WriteFile(garbage, []byte("not a gob stream"), 0o600))
+	_, err = NewCacheFromFile[string](garbage)
+	assert.Error(t, err)
+
+	// background goroutines of the cache failed to load are stopped
+	saved := filepath.Join(dir, "saved.gob")
+	_, err = NewCacheFromFile[string](garbage, WithPersistence[string](saved, time.Millisecond))
+	assert.Error(t, err)
+	require.NoError(t, os.Remove(saved), "final snapshot is saved by Close")
+	time.Sleep(20 * time.Millisecond)
+	assert.NoFileExists(t, saved, "no periodic saves after the error")
+
+	// interface values of unregistered types can't be encoded
+	anys := NewCache[any]()
+	anys.Set("key", testValue{}, 0)
+	assert.Error(t, anys.Save(filepath.Join(dir, "anys.gob")))
+}
+
+func TestSaveToLoadFrom(t *testing.T) {
+	cache := NewCache[int]()
+	for i := 0; i < 2500; i++ {
+		cache.Set(strconv.Itoa(i), i, time.Hour)
+	}
+	cache.Set("expired", -1, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+
+	buf := bytes.Buffer{}
+	require.NoError(t, cache.SaveTo(&buf))
+
+	loaded := NewCache[int]()
+	loaded.Set("0", -1, 0) // replaced by loaded value
+	loaded.Set("existing", 42, 0)
+	require.NoError(t, loaded.LoadFrom(&buf))
+	assert.Equal(t, 2501, loaded.Stats().Entries)
+	for i := 0; i < 2500; i++ {
+		v, err := loaded.Get(strconv.Itoa(i))
+		require.NoError(t, err)
+		require.Equal(t, i, v)
+	}
+	v, err := loaded.Get("existing")
+	assert.NoError(t, err)
+	assert.Equal(t, 42, v)
+
+	// truncated stream keeps entries decoded so far
+	require.NoError(t, cache.SaveTo(&buf))
+	truncated := NewCache[int]()
+	assert.Error(t, truncated.LoadFrom(bytes.NewReader(buf.Bytes()[:buf.Len()-1])))
+	assert.Greater(t, truncated.Stats().Entries, 0)
+}
+
+func TestWithPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.gob")
+
+	cache := NewCache(WithPersistence[string](path, 20*time.Millisecond))
+	cache.Set("key", "value", time.Hour)
+	cache.Set("forever", "value", 0)
+	time.Sleep(50 * time.Millisecond)
+
+	restored := NewCache(WithPersistence[string](path, time.Hour))
+	v, err := restored.Get("key")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", v)
+	assert.Equal(t, 2, restored.Stats().Entries)
+
+	// broken snapshot is logged and ignored
+	require.NoError(t, os.WriteFile(path, []byte("garbage"), 0o600))
+	buf := bytes.Buffer{}
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	restored = NewCache(WithPersistence[string](path, time.Hour), WithLogger[string](logger))
+	assert.Equal(t, 0, restored.Stats().Entries)
+	assert.Contains(t, buf.String(), "failed to restore snapshot")
+
+	// save errors are logged
+	sbuf := &syncBuffer{}
+	logger = slog.New(slog.NewTextHandler(sbuf, nil))
+	NewCache(WithPersistence[string](filepath.Join(path, "noSuchDir", "cache.gob"), 10*time.Millisecond),
+		WithLogger[string](logger))
+	assert.Eventually(t, func() bool {
+		return strings.Contains(sbuf.String(), "failed to save snapshot")
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestWithRestore(t *testing.T) {
+	dir := t.TempDir()
+	seed := filepath.Join(dir, "seed.gob")
+	cache := NewCache[string]()
+	cache.Set("key", "value", time.Hour)
+	cache.Set("expiring", "value", 10*time.Millisecond)
+	require.NoError(t, cache.Save(seed))
+	time.Sleep(20 * time.Millisecond)
+
+	restored := NewCache(WithRestore[string](seed, false))
+	require.NoError(t, restored.WaitWarmup(context.Background()))
+	v, err := restored.Get("key")
+	require.NoError(t, err)
+	assert.Equal(t, "value", v)
+	assert.Equal(t, 1, restored.Stats().Entries, "expired entry is skipped")
+
+	// restored from the seed, saved to the own file
+	path := filepath.Join(dir, "cache.gob")
+	restored = NewCache(WithPersistence[string](path, time.Hour), WithRestore[string](seed, false))
+	assert.Equal(t, 1, restored.Stats().Entries)
+	require.NoError(t, restored.Close())
+	assert.FileExists(t, path)
+
+	missing := NewCache(WithRestore[string](filepath.Join(dir, "missing.gob"), false))
+	assert.NoError(t, missing.WaitWarmup(context.Background()), "missing file is not an error")
+
+	require.NoError(t, os.WriteFile(seed, []byte("garbage"), 0o600))
+	broken := NewCache(WithRestore[string](seed, false))
+	assert.ErrorContains(t, broken.WaitWarmup(context.Background()), "failed to decode snapshot")
+	broken = NewCache(WithRestore[string](seed, true))
+	assert.NoError(t, broken.WaitWarmup(context.Background()), "errors are ignored")
+	broken = NewCache(WithRestore[string](seed, false), WithWarmup([]string{"a"}, func(key string) (string, time.Duration, error) {
+		return key, 0, nil
+	}, 1))
+	assert.ErrorContains(t, broken.WaitWarmup(context.Background()), "failed to decode snapshot", "joined with warmup errors")
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,20 @@
+package mcache
+
+// Filter returns live entries matching fn, evaluated in a single pass under the read lock,
+// so the result is consistent and it doesn't race with expiration like a loop of Keys and Get.
+// fn must not call the cache. Negative entries and entries spilled WithOverflow are not checked.
+// Filter doesn't count hits or touch entries.
+func (c *Cache[T]) Filter(fn func(key string, value T) bool) map[string]T {
+	c.RLock()
+	defer c.RUnlock()
+	result := map[string]T{}
+	now := c.now()
+	for _, data := range c.dataMaps() {
+		for k, v := range data {
+			if !v.expiredAt(now) && !v.negative && fn(k, v.value) {
+				result[k] = v.value
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,28 @@
+package mcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache/clocktest"
+)
+
+func TestFilter(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	cache := NewCache(WithClock[int](clock))
+	for i, k := range []string{"a", "b", "c", "d"} {
+		require.True(t, cache.Set(k, i, time.Minute))
+	}
+	require.True(t, cache.Set("expired", 10, time.Second))
+	require.True(t, cache.SetNegative("missing", time.Hour))
+	clock.Advance(2 * time.Second)
+
+	even := cache.Filter(func(_ string, v int) bool { return v%2 == 0 })
+	assert.Equal(t, map[string]int{"a": 0, "c": 2}, even)
+	assert.Equal(t, map[string]int{"d": 3}, cache.Filter(func(k string, _ int) bool { return k > "c" }))
+	assert.Empty(t, cache.Filter(func(string, int) bool { return false }))
+	assert.Len(t, cache.Filter(func(string, int) bool { return true }), 4)
+}
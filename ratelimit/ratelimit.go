@@ -0,0 +1,108 @@
+// Package ratelimit provides a per-key rate limiter built on mcache: counters of keys are cache entries
+// expiring with their windows, so idle keys take no memory and no goroutine is needed.
+package ratelimit
+
+import (
+	"errors"
+	"time"
+
+	"github.com/parMaster/mcache"
+)
+
+// counter is the state of a key: requests of the current window and, for sliding windows,
+// of the previous one
+type counter struct {
+	start int64 // start of the current sliding window, unix nanoseconds
+	count int
+	prev  int
+}
+
+// Limiter limits the rate of requests per key, it's safe for concurrent use
+type Limiter struct {
+	cache *mcache.Cache[counter]
+	clock mcache.Clock
+}
+
+// New is a constructor for Limiter
+func New(options ...func(*Limiter)) *Limiter {
+	l := &Limiter{clock: mcache.ClockFunc(time.Now)}
+	for _, option := range options {
+		option(l)
+	}
+	// expired counters are removed by sampling on writes, like in Redis
+	l.cache = mcache.NewCache(mcache.WithClock[counter](l.clock), mcache.WithSampledCleanup[counter](5))
+	return l
+}
+
+// WithClock is an option for setting the source of the current time, to control time in tests
+func WithClock(clock mcache.Clock) func(*Limiter) {
+	return func(l *Limiter) {
+		l.clock = clock
+	}
+}
+
+// Allow reports if a request of the key is allowed, at most limit requests per fixed window
+// starting with the first request of the key. Allowed requests are counted, denied ones are not.
+// Bursts of up to 2*limit are possible at window boundaries, AllowSliding smooths them.
+// Requests are denied if limit < 1 or window <= 0.
+func (l *Limiter) Allow(key string, limit int, window time.Duration) bool {
+	if limit < 1 || window <= 0 {
+		return false
+	}
+	for {
+		allowed := false
+		err := l.cache.Update(key, func(c counter) counter {
+			if c.count < limit {
+				c.count++
+				allowed = true
+			}
+			return c
+		})
+		if err == nil {
+			return allowed
+		}
+		if errors.Is(err, mcache.ErrClosed) {
+			return false
+		}
+		// the first request of the window, unless a concurrent one was first
+		if err := l.cache.Add(key, counter{count: 1}, window); !errors.Is(err, mcache.ErrKeyExists) {
+			return err == nil
+		}
+	}
+}
+
+// AllowSliding is Allow with a sliding window: requests of the previous window are weighted
+// by its part still inside the sliding window, like in Cloudflare and Nginx limiters,
+// so there are no bursts at window boundaries. Windows are aligned to multiples of window.
+// Requests are denied if limit < 1 or window <= 0.
+func (l *Limiter) AllowSliding(key string, limit int, window time.Duration) bool {
+	if limit < 1 || window <= 0 {
+		return false
+	}
+	unlock := l.cache.LockKey(key)
+	defer unlock()
+
+	now, w := l.clock.Now().UnixNano(), int64(window)
+	start := now - now%w
+	c, _ := l.cache.Get(key)
+	if c.start != start {
+		if c.start == start-w {
+			c.prev = c.count
+		} else {
+			c.prev = 0
+		}
+		c.start, c.count = start, 0
+	}
+	weight := float64(start+w-now) / float64(w) // part of the previous window inside the sliding one
+	if float64(c.prev)*weight+float64(c.count) >= float64(limit) {
+		return false
+	}
+	c.count++
+	// counter is needed until the end of the next window, as its previous one
+	return l.cache.Set(key, c, time.Duration(start+2*w-now))
+}
+
+// Reset forgets requests of the key
+func (l *Limiter) Reset(key string) {
+	_ = l.cache.Del(key)
+}
@@ -0,0 +1,89 @@
+package ratelimit
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/parMaster/mcache/clocktest"
+)
+
+func TestAllow(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	l := New(WithClock(clock))
+	for i := 0; i < 3; i++ {
+		assert.True(t, l.Allow("user", 3, time.Minute), i)
+	}
+	assert.False(t, l.Allow("user", 3, time.Minute))
+	assert.True(t, l.Allow("other", 3, time.Minute), "keys are limited separately")
+	assert.False(t, l.Allow("user", 0, time.Minute))
+
+	clock.Advance(time.Minute + 1)
+	assert.True(t, l.Allow("user", 3, time.Minute), "new window")
+
+	l.Allow("user", 3, time.Minute)
+	l.Allow("user", 3, time.Minute)
+	assert.False(t, l.Allow("user", 3, time.Minute))
+	l.Reset("user")
+	assert.True(t, l.Allow("user", 3, time.Minute))
+}
+
+func TestAllowConcurrent(t *testing.T) {
+	l := New()
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if l.Allow("key", 10, time.Minute) {
+				allowed.Add(1)
+			}
+			if l.AllowSliding("sliding", 10, time.Minute) {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(20), allowed.Load())
+}
+
+func TestAllowSliding(t *testing.T) {
+	start := time.Now().Truncate(time.Minute)
+	clock := clocktest.New(start)
+	l := New(WithClock(clock))
+
+	clock.Advance(30 * time.Second)
+	for i := 0; i < 10; i++ {
+		assert.True(t, l.AllowSliding("user", 10, time.Minute), i)
+	}
+	assert.False(t, l.AllowSliding("user", 10, time.Minute))
+
+	// a quarter into the next window 3/4 of previous requests still count
+	clock.Advance(45 * time.Second)
+	for i := 0; i < 3; i++ {
+		assert.True(t, l.AllowSliding("user", 10, time.Minute), i)
+	}
+	assert.False(t, l.AllowSliding("user", 10, time.Minute), "7.5 + 3 requests, not a fresh window")
+
+	// the previous window is out of the sliding one after two windows
+	clock.Advance(90 * time.Second)
+	for i := 0; i < 10; i++ {
+		assert.True(t, l.AllowSliding("user", 10, time.Minute), i)
+	}
+	assert.False(t, l.AllowSliding("user", 0, time.Minute))
+}
+
+func TestNonPositiveWindow(t *testing.T) {
+	l := New()
+	for _, window := range []time.Duration{0, -time.Second} {
+		assert.False(t, l.Allow("user", 3, window), window)
+		assert.NotPanics(t, func() {
+			assert.False(t, l.AllowSliding("user", 3, window), window)
+		})
+	}
+	assert.True(t, l.Allow("user", 3, time.Minute), "nothing counted")
+}
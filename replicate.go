@@ -0,0 +1,148 @@
+package mcache
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// replicaEvent is a change replicated to the target cache: entry set, deleted
+// (with tombstone expiration, like in WAL) or all entries cleared
+type replicaEvent[T any] struct {
+	entry Entry[T]
+	clear bool
+}
+
+// Replication is a stream of changes of a cache to a target cache, started by ReplicateTo
+type Replication[T any] struct {
+	source     *Cache[T]
+	target     *Cache[T]
+	queue      chan replicaEvent[T]
+	replicated atomic.Uint64
+	dropped    atomic.Uint64
+	stop       sync.Once
+	done       chan struct{}
+}
+
+// ReplicationStats is a snapshot of replication counters
+type ReplicationStats struct {
+	Replicated uint64 // changes applied to the target
+	Dropped    uint64 // changes dropped because the queue was full
+	Lag        int    // changes queued, not applied yet
+}
+
+// ReplicateTo starts mirroring Set, Del, DelPrefix, Clear, LoadFrom and RecoverFromWAL changes
+// of the cache to the target, keeping it as a warm standby. Entries keep their expiration times,
+// expired entries are removed by each cache on its own. Existing entries are not copied,
+// use SaveTo and LoadFrom for the initial copy.
+// Changes are applied asynchronously, in order, from a queue of queueSize changes. Writers never wait
+// for the target: when the queue is full, changes are dropped and counted in ReplicationStats.
+// Replication stops with Stop or when the cache is closed, changes for a closed target are dropped.
+// Target must not replicate back to the cache, or changes will circulate forever.
+func (c *Cache[T]) ReplicateTo(target *Cache[T], queueSize int) *Replication[T] {
+	r := &Replication[T]{
+		source: c,
+		target: target,
+		queue:  make(chan replicaEvent[T], queueSize),
+		done:   make(chan struct{}),
+	}
+	go r.run()
+
+	c.Lock()
+	defer c.Unlock()
+	if c.closed {
+		r.stopLocked()
+		return r
+	}
+	c.replicas = append(c.replicas, r)
+	return r
+}
+
+// Stop stops the replication, waiting for queued changes to be applied
+func (r *Replication[T]) Stop() {
+	r.source.Lock()
+	r.stopLocked()
+	r.source.Unlock()
+	<-r.done
+}
+
+// Stats returns replication counters
+func (r *Replication[T]) Stats() ReplicationStats {
+	return ReplicationStats{
+		Replicated: r.replicated.Load(),
+		Dropped:    r.dropped.Load(),
+		Lag:        len(r.queue),
+	}
+}
+
+// stopLocked removes the replication from the source and closes its queue, must be called under the source lock
+func (r *Replication[T]) stopLocked() {
+	r.stop.Do(func() {
+		for i, replica := range r.source.replicas {
+			if replica == r {
+				r.source.replicas = append(r.source.replicas[:i], r.source.replicas[i+1:]...)
+				break
+			}
+		}
+		close(r.queue)
+	})
+}
+
+// run applies queued changes to the target until the queue is closed
+func (r *Replication[T]) run() {
+	defer close(r.done)
+	for ev := range r.queue {
+		var err error
+		if ev.clear {
+			err = r.target.Clear()
+		} else {
+			err = r.target.replay(ev.entry)
+		}
+		if err != nil {
+			r.dropped.Add(1)
+			continue
+		}
+		r.replicated.Add(1)
+	}
+}
+
+// send queues the change without blocking, must be called under the source lock
+func (r *Replication[T]) send(ev replicaEvent[T]) {
+	select {
+	case r.queue <- ev:
+	default:
+		r.dropped.Add(1)
+	}
+}
+
+// replicate queues the change to all replications, must be called under lock
+func (c *Cache[T]) replicate(ev replicaEvent[T]) {
+	for _, r := range c.replicas {
+		r.send(ev)
+	}
+}
+
+// logSet records the item set for the key to WAL and replications, negative items are not recorded
+func (c *Cache[T]) logSet(key string, item CacheItem[T]) {
+	if item.negative {
+		return
+	}
+	c.walSet(key, item)
+	if len(c.replicas) > 0 {
+		c.replicate(replicaEvent[T]{entry: Entry[T]{Key: key, Value: item.value, Expiration: c.expirationTime(item)}})
+	}
+}
+
+// logDel records deletion of the key to WAL and replications
+func (c *Cache[T]) logDel(key string) {
+	c.walDel(key)
+	if len(c.replicas) > 0 {
+		c.replicate(replicaEvent[T]{entry: Entry[T]{Key: key, Expiration: tombstone}})
+	}
+}
+
+// stopReplications stops all replications of the closed cache, must be called under lock
+func (c *Cache[T]) stopReplications() {
+	for len(c.replicas) > 0 {
+		c.replicas[0].stopLocked()
+	}
+}
@@ -0,0 +1,106 @@
+package mcache
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplicateTo(t *testing.T) {
+	source := NewCache[string]()
+	target := NewCache[string]()
+	source.Set("existing", "value", 0)
+	r := source.ReplicateTo(target, 100)
+
+	source.Set("key", "value", 0)
+	source.Set("ttl", "value", time.Hour)
+	source.Set("del", "value", 0)
+	require.NoError(t, source.Del("del"))
+	require.NoError(t, source.LoadFrom(bytes.NewReader(nil)))
+	r.Stop()
+	r.Stop() // second Stop is a no-op
+
+	assert.Equal(t, ReplicationStats{Replicated: 4}, r.Stats())
+	v, err := target.Get("key")
+	require.NoError(t, err)
+	assert.Equal(t, "value", v)
+	info, err := target.EntryInfo("ttl")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), info.Expiration, time.Second)
+	_, err = target.Get("del")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	_, err = target.Get("existing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	// stopped replication doesn't mirror changes
+	source.Set("after", "value", 0)
+	time.Sleep(10 * time.Millisecond)
+	_, err = target.Get("after")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestReplicateToNoExpiration(t *testing.T) {
+	source := NewCache[string]()
+	target := NewCache(WithNoExpiration[string]())
+	r := source.ReplicateTo(target, 100)
+
+	source.Set("del", "value", 0)
+	require.NoError(t, source.Del("del"))
+	r.Stop()
+
+	assert.Equal(t, ReplicationStats{Replicated: 2}, r.Stats())
+	_, err := target.Get("del")
+	assert.ErrorIs(t, err, ErrKeyNotFound, "deletion is replicated")
+}
+
+func TestReplicateToClear(t *testing.T) {
+	source := NewCache[int]()
+	target := NewCache[int]()
+	target.Set("target only", 1, 0)
+	r := source.ReplicateTo(target, 10)
+	source.Set("key", 1, 0)
+	require.NoError(t, source.Clear())
+	source.Set("other", 2, 0)
+
+	// Close stops replications after queued changes
+	require.NoError(t, source.Close())
+	r.Stop()
+	assert.Equal(t, uint64(3), r.Stats().Replicated)
+	has, _ := target.Has("target only")
+	assert.False(t, has)
+	has, _ = target.Has("other")
+	assert.True(t, has)
+
+	// replication of a closed cache is stopped right away
+	r = source.ReplicateTo(target, 10)
+	r.Stop()
+}
+
+func TestReplicateToDropped(t *testing.T) {
+	source := NewCache[int]()
+	target := NewCache[int]()
+	r := source.ReplicateTo(target, 1)
+
+	// target is locked, so the queue is not drained
+	target.Lock()
+	for i := 0; i < 10; i++ {
+		source.Set(string(rune('a'+i)), i, 0)
+	}
+	stats := r.Stats()
+	target.Unlock()
+	r.Stop()
+
+	assert.Equal(t, 1, stats.Lag)
+	assert.GreaterOrEqual(t, stats.Dropped, uint64(8))
+	assert.Equal(t, uint64(10), r.Stats().Dropped+r.Stats().Replicated)
+
+	// changes for a closed target are dropped
+	require.NoError(t, target.Close())
+	r = source.ReplicateTo(target, 1)
+	source.Set("z", 1, 0)
+	r.Stop()
+	assert.Equal(t, ReplicationStats{Dropped: 1}, r.Stats())
+}
@@ -0,0 +1,96 @@
+// Package ring provides a consistent hash ring with virtual nodes, so adding or removing a node
+// moves only the keys of that node, about 1/n of all keys.
+package ring
+
+import (
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Ring maps keys to nodes, safe for concurrent use
+type Ring struct {
+	replicas int
+	hashes   []uint32          // sorted hashes of virtual nodes
+	nodes    map[uint32]string // virtual node hash to node
+	mu       sync.RWMutex
+}
+
+// New is a constructor for Ring placing every node at replicas points of the ring,
+// more points spread keys more evenly
+func New(replicas int) *Ring {
+	return &Ring{replicas: max(replicas, 1), nodes: make(map[uint32]string)}
+}
+
+// Add adds nodes to the ring
+func (r *Ring) Add(nodes ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, node := range nodes {
+		for i := 0; i < r.replicas; i++ {
+			h := hash(strconv.Itoa(i) + node)
+			if _, ok := r.nodes[h]; !ok {
+				r.hashes = append(r.hashes, h)
+			}
+			r.nodes[h] = node
+		}
+	}
+	sort.Slice(r.hashes, func(i, j int) bool { return r.hashes[i] < r.hashes[j] })
+}
+
+// Remove removes nodes from the ring
+func (r *Ring) Remove(nodes ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, node := range nodes {
+		for i := 0; i < r.replicas; i++ {
+			h := hash(strconv.Itoa(i) + node)
+			if r.nodes[h] == node {
+				delete(r.nodes, h)
+			}
+		}
+	}
+	hashes := r.hashes[:0]
+	for _, h := range r.hashes {
+		if _, ok := r.nodes[h]; ok {
+			hashes = append(hashes, h)
+		}
+	}
+	r.hashes = hashes
+}
+
+// Get returns the node owning the key, empty string if the ring is empty
+func (r *Ring) Get(key string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(r.hashes) == 0 {
+		return ""
+	}
+	h := hash(key)
+	i := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= h })
+	if i == len(r.hashes) {
+		i = 0
+	}
+	return r.nodes[r.hashes[i]]
+}
+
+// Nodes returns the nodes of the ring, sorted
+func (r *Ring) Nodes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	seen := make(map[string]bool)
+	nodes := []string{}
+	for _, node := range r.nodes {
+		if !seen[node] {
+			seen[node] = true
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+func hash(s string) uint32 {
+	return crc32.ChecksumIEEE([]byte(s))
+}
@@ -0,0 +1,49 @@
+package ring
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRing(t *testing.T) {
+	r := New(100)
+	assert.Equal(t, "", r.Get("key"))
+
+	r.Add("a", "b", "c")
+	assert.Equal(t, []string{"a", "b", "c"}, r.Nodes())
+	owners := map[string]string{}
+	counts := map[string]int{}
+	for i := 0; i < 3000; i++ {
+		key := strconv.Itoa(i)
+		owners[key] = r.Get(key)
+		counts[owners[key]]++
+	}
+	// keys are spread evenly enough
+	for _, node := range []string{"a", "b", "c"} {
+		assert.InDelta(t, 1000, counts[node], 300, node)
+	}
+
+	// adding a node moves only keys to the new node
+	r.Add("d")
+	moved := 0
+	for key, owner := range owners {
+		if now := r.Get(key); now != owner {
+			assert.Equal(t, "d", now)
+			moved++
+		}
+	}
+	assert.InDelta(t, 750, moved, 300)
+
+	// removing it moves them back
+	r.Remove("d")
+	for key, owner := range owners {
+		assert.Equal(t, owner, r.Get(key))
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, r.Nodes())
+
+	r.Remove("a", "b", "c")
+	assert.Equal(t, "", r.Get("key"))
+	assert.Empty(t, r.Nodes())
+}
@@ -0,0 +1,83 @@
+package mcache
+
+import (
+	"errors"
+	"maps"
+	"time"
+)
+
+// SAdd adds member to the set of the key, atomically, and reports if it wasn't a member already.
+// A live set keeps its TTL, a missing, expired or negative key gets a new set expiring in ttl,
+// with errors of TrySet. Sets are copied on writes, so sets returned by Get are safe to read.
+func SAdd[E comparable](c *Cache[map[E]struct{}], key string, member E, ttl time.Duration) (bool, error) {
+	c.Lock()
+	defer c.Unlock()
+	item, err := c.lookupLocked(key)
+	if err != nil && !errors.Is(err, ErrKeyNotFound) && !errors.Is(err, ErrExpired) {
+		return false, err
+	}
+	if err != nil || item.negative {
+		return true, c.trySetLocked(key, map[E]struct{}{member: {}}, ttl, true)
+	}
+	if _, ok := item.value[member]; ok {
+		return false, nil
+	}
+	set := make(map[E]struct{}, len(item.value)+1)
+	maps.Copy(set, item.value)
+	set[member] = struct{}{}
+	item.value = set
+	c.setItem(key, item)
+	c.writeBack(key, pendingWrite[map[E]struct{}]{value: set, ttl: c.remaining(item)})
+	return true, nil
+}
+
+// SRem removes member from the set of the key, atomically, and reports if it was a member.
+// The key is deleted with the last member, errors are the same as for Update.
+func SRem[E comparable](c *Cache[map[E]struct{}], key string, member E) (bool, error) {
+	c.Lock()
+	defer c.Unlock()
+	item, err := c.lookupLocked(key)
+	if err != nil {
+		return false, err
+	}
+	if item.negative {
+		return false, ErrNegativeCached
+	}
+	if _, ok := item.value[member]; !ok {
+		return false, nil
+	}
+	if len(item.value) == 1 {
+		_ = c.delLocked(key)
+		c.writeBack(key, pendingWrite[map[E]struct{}]{del: true})
+		return true, nil
+	}
+	set := maps.Clone(item.value)
+	delete(set, member)
+	item.value = set
+	c.setItem(key, item)
+	c.writeBack(key, pendingWrite[map[E]struct{}]{value: set, ttl: c.remaining(item)})
+	return true, nil
+}
+
+// SIsMember reports if member is in the set of the key, errors are the same as for Get
+func SIsMember[E comparable](c *Cache[map[E]struct{}], key string, member E) (bool, error) {
+	set, err := c.Get(key)
+	if err != nil {
+		return false, err
+	}
+	_, ok := set[member]
+	return ok, nil
+}
+
+// SMembers returns members of the set of the key in no particular order, errors are the same as for Get
+func SMembers[E comparable](c *Cache[map[E]struct{}], key string) ([]E, error) {
+	set, err := c.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	members := make([]E, 0, len(set))
+	for member := range set {
+		members = append(members, member)
+	}
+	return members, nil
+}
@@ -0,0 +1,107 @@
+package mcache
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache/clocktest"
+)
+
+func TestSet(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	cache := NewCache(WithClock[map[string]struct{}](clock))
+
+	added, err := SAdd(cache, "seen", "a", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, added)
+	before, err := cache.Get("seen")
+	require.NoError(t, err)
+	clock.Advance(30 * time.Second)
+	added, err = SAdd(cache, "seen", "b", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, added)
+	added, err = SAdd(cache, "seen", "a", time.Hour)
+	require.NoError(t, err)
+	assert.False(t, added, "already a member")
+	assert.Len(t, before, 1, "sets are copied on writes")
+
+	members, err := SMembers(cache, "seen")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a", "b"}, members)
+	info, err := cache.EntryInfo("seen")
+	require.NoError(t, err)
+	assert.True(t, clock.Now().Add(30*time.Second).Equal(info.Expiration), "TTL is kept")
+
+	ok, err := SIsMember(cache, "seen", "a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = SIsMember(cache, "seen", "c")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	_, err = SIsMember(cache, "missing", "a")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	_, err = SMembers(cache, "missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	removed, err := SRem(cache, "seen", "c")
+	require.NoError(t, err)
+	assert.False(t, removed)
+	removed, err = SRem(cache, "seen", "a")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	removed, err = SRem(cache, "seen", "b")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	assert.False(t, cache.Exists("seen"), "the key is deleted with the last member")
+	_, err = SRem(cache, "seen", "b")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	require.True(t, cache.SetNegative("negative", 0))
+	_, err = SRem(cache, "negative", "a")
+	assert.ErrorIs(t, err, ErrNegativeCached)
+	added, err = SAdd(cache, "negative", "a", 0)
+	require.NoError(t, err)
+	assert.True(t, added, "negative entry is replaced")
+
+	_, err = SAdd(cache, "expiring", "a", time.Second)
+	require.NoError(t, err)
+	clock.Advance(2 * time.Second)
+	added, err = SAdd(cache, "expiring", "a", time.Second)
+	require.NoError(t, err)
+	assert.True(t, added, "expired set is replaced")
+
+	require.NoError(t, cache.Close())
+	_, err = SAdd(cache, "seen", "a", 0)
+	assert.ErrorIs(t, err, ErrClosed)
+	_, err = SRem(cache, "seen", "a")
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestSetConcurrent(t *testing.T) {
+	cache := NewCache[map[int]struct{}]()
+	var wg sync.WaitGroup
+	var added atomic.Int32
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ok, err := SAdd(cache, "ids", i%100, 0)
+			assert.NoError(t, err)
+			if ok {
+				added.Add(1)
+			}
+			_, err = SMembers(cache, "ids")
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+	members, err := SMembers(cache, "ids")
+	require.NoError(t, err)
+	assert.Len(t, members, 100)
+	assert.Equal(t, int32(100), added.Load(), "each member is added once")
+}
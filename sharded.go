@@ -0,0 +1,132 @@
+package mcache
+
+import (
+	"errors"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/parMaster/mcache/ring"
+)
+
+// ErrNoNodes is returned by ShardedClient without nodes
+var ErrNoNodes = errors.New("no cache nodes")
+
+// ShardedClient spreads keys over named Cacher nodes, local caches or clients of remote ones,
+// by consistent hashing with virtual nodes. Adding or removing a node moves only about 1/n of keys,
+// moved keys are missed once and have to be set again on their new node.
+type ShardedClient[T any] struct {
+	ring  *ring.Ring
+	nodes map[string]Cacher[T]
+	mu    sync.RWMutex
+}
+
+// NewShardedClient is a constructor for ShardedClient placing every node at replicas points of the hash ring,
+// more points spread keys more evenly, 100 is a good default
+func NewShardedClient[T any](replicas int) *ShardedClient[T] {
+	return &ShardedClient[T]{ring: ring.New(replicas), nodes: make(map[string]Cacher[T])}
+}
+
+// AddNode adds the node, replacing the node with the same name
+func (s *ShardedClient[T]) AddNode(name string, node Cacher[T]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.nodes[name]; !ok {
+		s.ring.Add(name)
+	}
+	s.nodes[name] = node
+}
+
+// RemoveNode removes the node, its keys are not deleted from it
+func (s *ShardedClient[T]) RemoveNode(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ring.Remove(name)
+	delete(s.nodes, name)
+}
+
+// Node returns the name and the node owning the key, false if there are no nodes
+func (s *ShardedClient[T]) Node(key string) (string, Cacher[T], bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	name := s.ring.Get(key)
+	node, ok := s.nodes[name]
+	return name, node, ok
+}
+
+// Set is Set on the node of the key, false if there are no nodes
+func (s *ShardedClient[T]) Set(key string, value T, ttl time.Duration) bool {
+	_, node, ok := s.Node(key)
+	return ok && node.Set(key, value, ttl)
+}
+
+// Get is Get on the node of the key, ErrNoNodes if there are no nodes
+func (s *ShardedClient[T]) Get(key string) (T, error) {
+	_, node, ok := s.Node(key)
+	if !ok {
+		var none T
+		return none, ErrNoNodes
+	}
+	return node.Get(key)
+}
+
+// Has is Has on the node of the key, ErrNoNodes if there are no nodes
+func (s *ShardedClient[T]) Has(key string) (bool, error) {
+	_, node, ok := s.Node(key)
+	if !ok {
+		return false, ErrNoNodes
+	}
+	return node.Has(key)
+}
+
+// Del is Del on the node of the key, ErrNoNodes if there are no nodes
+func (s *ShardedClient[T]) Del(key string) error {
+	_, node, ok := s.Node(key)
+	if !ok {
+		return ErrNoNodes
+	}
+	return node.Del(key)
+}
+
+// Cleanup runs Cleanup on every node
+func (s *ShardedClient[T]) Cleanup() {
+	for _, node := range s.snapshot() {
+		node.Cleanup()
+	}
+}
+
+// Clear runs Clear on every node, errors of all nodes are joined
+func (s *ShardedClient[T]) Clear() error {
+	var errs []error
+	for _, node := range s.snapshot() {
+		errs = append(errs, node.Clear())
+	}
+	return errors.Join(errs...)
+}
+
+// NodeStats returns counters of nodes providing Stats, like local Cache and StripedCache nodes,
+// to diagnose skewed key distribution and hot nodes
+func (s *ShardedClient[T]) NodeStats() map[string]Stats {
+	s.mu.RLock()
+	nodes := maps.Clone(s.nodes)
+	s.mu.RUnlock()
+
+	stats := make(map[string]Stats, len(nodes))
+	for name, node := range nodes {
+		if n, ok := node.(interface{ Stats() Stats }); ok {
+			stats[name] = n.Stats()
+		}
+	}
+	return stats
+}
+
+// snapshot returns the current nodes, so they are called without the lock
+func (s *ShardedClient[T]) snapshot() []Cacher[T] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	nodes := make([]Cacher[T], 0, len(s.nodes))
+	for _, node := range s.nodes {
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
@@ -0,0 +1,71 @@
+package mcache
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardedClient(t *testing.T) {
+	s := NewShardedClient[int](100)
+	assert.False(t, s.Set("key", 1, 0))
+	_, err := s.Get("key")
+	assert.ErrorIs(t, err, ErrNoNodes)
+	_, err = s.Has("key")
+	assert.ErrorIs(t, err, ErrNoNodes)
+	assert.ErrorIs(t, s.Del("key"), ErrNoNodes)
+
+	nodes := map[string]*Cache[int]{"a": NewCache[int](), "b": NewCache[int](), "c": NewCache[int]()}
+	for name, c := range nodes {
+		s.AddNode(name, c)
+	}
+	for i := 0; i < 3000; i++ {
+		require.True(t, s.Set(strconv.Itoa(i), i, 0))
+	}
+	for name, c := range nodes {
+		assert.InDelta(t, 1000, c.Stats().Entries, 300, name)
+	}
+	stats := s.NodeStats()
+	require.Len(t, stats, 3)
+	for name, c := range nodes {
+		assert.Equal(t, c.Stats().Entries, stats[name].Entries, name)
+	}
+	v, err := s.Get("42")
+	require.NoError(t, err)
+	assert.Equal(t, 42, v)
+	has, err := s.Has("42")
+	require.NoError(t, err)
+	assert.True(t, has)
+	require.NoError(t, s.Del("42"))
+	_, err = s.Get("42")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	// adding a node moves about 1/4 of keys, all of them to the new node
+	s.AddNode("d", NewCache[int]())
+	moved := 0
+	for i := 0; i < 3000; i++ {
+		if _, err := s.Get(strconv.Itoa(i)); err != nil {
+			name, _, _ := s.Node(strconv.Itoa(i))
+			assert.Equal(t, "d", name)
+			moved++
+		}
+	}
+	assert.InDelta(t, 750, moved, 300)
+
+	// removing it moves them back
+	s.RemoveNode("d")
+	for i := 0; i < 3000; i++ {
+		if i != 42 {
+			_, err := s.Get(strconv.Itoa(i))
+			assert.NoError(t, err)
+		}
+	}
+
+	s.Cleanup()
+	require.NoError(t, s.Clear())
+	for _, c := range nodes {
+		assert.Equal(t, 0, c.Stats().Entries)
+	}
+}
@@ -0,0 +1,9 @@
+// Package shm is an experimental cache of byte slice values in a memory-mapped file, shared by
+// all processes opening the same file, like workers forked from the same binary, without a network hop.
+//
+// The file is a fixed-size hash table of fixed-size slots: an entry (key, value and a header)
+// must fit in a slot, and a key is stored in one of a few slots following its hash,
+// so Set fails when they are all taken by live entries. Writers are serialized by an flock
+// of the file across processes, and by a mutex within a process, readers share the lock.
+// It's available on Linux, macOS and FreeBSD.
+package shm
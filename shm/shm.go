@@ -0,0 +1,365 @@
+//go:build linux || darwin || freebsd
+
+package shm
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/parMaster/mcache"
+)
+
+// ErrLayout is returned by Open when the file was created with another number or size of slots
+var ErrLayout = errors.New("shm: file layout mismatch")
+
+const (
+	magic      = 0x6d63616368650001 // "mcache" and layout version
+	fileHeader = 16                 // magic, number of slots, slot size
+	slotHeader = 24                 // state, key length, value length, expiration, key hash
+	maxProbes  = 8                  // slots checked for a key, starting from the slot of its hash
+)
+
+// slot states
+const (
+	empty   = 0
+	used    = 1
+	deleted = 2 // tombstone, probing goes on past it
+)
+
+// Cache is a cache in a memory-mapped file, implementing mcache.Cacher[[]byte]
+type Cache struct {
+	file     *os.File
+	data     []byte
+	slots    int
+	slotSize int
+	mu       sync.RWMutex
+	// flock belongs to the open file, not to a goroutine: the shared one is taken by the first
+	// reader and released by the last, so other readers are never left unprotected
+	flockMu sync.Mutex
+	readers int
+}
+
+// Open opens or creates the cache file at path with the number of slots of slotSize bytes each.
+// All processes must open the file with the same layout, otherwise ErrLayout is returned.
+func Open(path string, slots, slotSize int) (*Cache, error) {
+	if slots <= 0 || slotSize <= slotHeader {
+		return nil, fmt.Errorf("shm: invalid layout: %d slots of %d bytes", slots, slotSize)
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("shm: failed to open %s: %w", path, err)
+	}
+	c := &Cache{file: f, slots: slots, slotSize: slotSize}
+	if err = c.init(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// init sizes and maps the file, writing the header of a new file under the exclusive lock
+func (c *Cache) init() error {
+	if err := syscall.Flock(int(c.file.Fd()), syscall.LOCK_EX); err != nil {
+		return fmt.Errorf("shm: failed to lock: %w", err)
+	}
+	defer syscall.Flock(int(c.file.Fd()), syscall.LOCK_UN) //nolint:errcheck // closing the file unlocks too
+
+	size := fileHeader + c.slots*c.slotSize
+	fi, err := c.file.Stat()
+	if err != nil {
+		return fmt.Errorf("shm: failed to stat: %w", err)
+	}
+	created := fi.Size() == 0
+	if created {
+		if err = c.file.Truncate(int64(size)); err != nil {
+			return fmt.Errorf("shm: failed to size: %w", err)
+		}
+	} else if fi.Size() != int64(size) {
+		return ErrLayout
+	}
+
+	c.data, err = syscall.Mmap(int(c.file.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return fmt.Errorf("shm: failed to map: %w", err)
+	}
+	if created {
+		binary.LittleEndian.PutUint64(c.data, magic)
+		binary.LittleEndian.PutUint32(c.data[8:], uint32(c.slots))
+		binary.LittleEndian.PutUint32(c.data[12:], uint32(c.slotSize))
+	}
+	if binary.LittleEndian.Uint64(c.data) != magic ||
+		binary.LittleEndian.Uint32(c.data[8:]) != uint32(c.slots) ||
+		binary.LittleEndian.Uint32(c.data[12:]) != uint32(c.slotSize) {
+		syscall.Munmap(c.data) //nolint:errcheck // mapping is dropped anyway
+		c.data = nil
+		return ErrLayout
+	}
+	return nil
+}
+
+// Close unmaps and closes the file, the cache is unusable after it
+func (c *Cache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.data == nil {
+		return mcache.ErrClosed
+	}
+	err := syscall.Munmap(c.data)
+	c.data = nil
+	return errors.Join(err, c.file.Close())
+}
+
+// Set stores a copy of the value, with the same rules as mcache.Cache.Set.
+// Returns false if the entry doesn't fit in a slot, or all slots of the key are taken by live entries.
+func (c *Cache) Set(key string, value []byte, ttl time.Duration) bool {
+	if slotHeader+len(key)+len(value) > c.slotSize || len(key) > 0xffff {
+		return false
+	}
+	var expiration int64
+	if ttl > 0 {
+		expiration = time.Now().Add(ttl).UnixNano()
+	}
+
+	h := hash(key)
+	ok := false
+	err := c.write(func() {
+		free, stale := -1, -1
+		for _, s := range c.probe(h) {
+			state, k, _, exp := c.entry(s)
+			if state == used && string(k) == key {
+				stale = s
+			}
+			// slots of expired entries are free, like empty and deleted ones
+			if (state != used || expired(exp)) && free < 0 {
+				free = s
+			}
+			if state == empty {
+				break
+			}
+		}
+		if free < 0 {
+			free = stale
+		}
+		if free < 0 {
+			return
+		}
+		if stale >= 0 && stale != free {
+			c.data[c.offset(stale)] = deleted
+		}
+		c.put(free, h, key, value, expiration)
+		ok = true
+	})
+	return err == nil && ok
+}
+
+// Get returns a copy of the value, with the same rules as mcache.Cache.Get
+func (c *Cache) Get(key string) ([]byte, error) {
+	var value []byte
+	err := c.lookup(key, func(v []byte) { value = append([]byte(nil), v...) })
+	return value, err
+}
+
+// Has checks if the key exists, with the same rules as mcache.Cache.Has
+func (c *Cache) Has(key string) (bool, error) {
+	if err := c.lookup(key, func([]byte) {}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Del deletes the key, with the same rules as mcache.Cache.Del
+func (c *Cache) Del(key string) error {
+	h := hash(key)
+	result := mcache.ErrKeyNotFound
+	err := c.write(func() {
+		if s, exp, ok := c.find(h, key); ok {
+			c.data[c.offset(s)] = deleted
+			result = nil
+			if expired(exp) {
+				result = mcache.ErrExpired
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return result
+}
+
+// Cleanup deletes expired entries
+func (c *Cache) Cleanup() {
+	c.write(func() { //nolint:errcheck // closed cache has nothing to clean
+		for s := 0; s < c.slots; s++ {
+			if state, _, _, exp := c.entry(s); state == used && expired(exp) {
+				c.data[c.offset(s)] = deleted
+			}
+		}
+	})
+}
+
+// Clear deletes all entries
+func (c *Cache) Clear() error {
+	return c.write(func() {
+		for s := 0; s < c.slots; s++ {
+			c.data[c.offset(s)] = empty
+		}
+	})
+}
+
+// lookup calls fn with the value of the live key under the shared lock,
+// expired entry is deleted under the exclusive lock
+func (c *Cache) lookup(key string, fn func(value []byte)) error {
+	h := hash(key)
+	var result error
+	exp := int64(0)
+	err := c.read(func() {
+		s, e, ok := c.find(h, key)
+		switch {
+		case !ok:
+			result = mcache.ErrKeyNotFound
+		case expired(e):
+			result, exp = mcache.ErrExpired, e
+		default:
+			_, _, v, _ := c.entry(s)
+			fn(v)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if result != mcache.ErrExpired {
+		return result
+	}
+
+	c.write(func() { //nolint:errcheck // closed meanwhile, nothing to delete
+		// the key may have been set again meanwhile
+		if s, e, ok := c.find(h, key); ok && e == exp {
+			c.data[c.offset(s)] = deleted
+		}
+	})
+	return mcache.ErrExpired
+}
+
+// read runs fn under the shared locks
+func (c *Cache) read(fn func()) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.data == nil {
+		return mcache.ErrClosed
+	}
+	if err := c.share(); err != nil {
+		return err
+	}
+	defer c.unshare()
+	fn()
+	return nil
+}
+
+// share takes the shared file lock for a reader, unless other readers hold it already.
+// Must be called under the read lock of the mutex.
+func (c *Cache) share() error {
+	c.flockMu.Lock()
+	defer c.flockMu.Unlock()
+	if c.readers == 0 {
+		if err := syscall.Flock(int(c.file.Fd()), syscall.LOCK_SH); err != nil {
+			return fmt.Errorf("shm: failed to lock: %w", err)
+		}
+	}
+	c.readers++
+	return nil
+}
+
+// unshare releases the shared file lock of a reader, when it's the last one
+func (c *Cache) unshare() {
+	c.flockMu.Lock()
+	defer c.flockMu.Unlock()
+	c.readers--
+	if c.readers == 0 {
+		syscall.Flock(int(c.file.Fd()), syscall.LOCK_UN) //nolint:errcheck // unlocked on close anyway
+	}
+}
+
+// write runs fn under the exclusive locks
+func (c *Cache) write(fn func()) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.data == nil {
+		return mcache.ErrClosed
+	}
+	fd := int(c.file.Fd())
+	if err := syscall.Flock(fd, syscall.LOCK_EX); err != nil {
+		return fmt.Errorf("shm: failed to lock: %w", err)
+	}
+	defer syscall.Flock(fd, syscall.LOCK_UN) //nolint:errcheck // unlocked on close anyway
+	fn()
+	return nil
+}
+
+// probe returns slots a key with the hash can be stored in
+func (c *Cache) probe(h uint64) []int {
+	n := min(maxProbes, c.slots)
+	slots := make([]int, n)
+	for i := range slots {
+		slots[i] = int((h + uint64(i)) % uint64(c.slots))
+	}
+	return slots
+}
+
+// find returns the slot and expiration of the key, must be called under lock
+func (c *Cache) find(h uint64, key string) (slot int, expiration int64, ok bool) {
+	for _, s := range c.probe(h) {
+		state, k, _, exp := c.entry(s)
+		if state == empty {
+			break
+		}
+		if state == used && string(k) == key {
+			return s, exp, true
+		}
+	}
+	return 0, 0, false
+}
+
+// entry reads the slot, must be called under lock
+func (c *Cache) entry(slot int) (state byte, key, value []byte, expiration int64) {
+	b := c.data[c.offset(slot):]
+	keyLen := int(binary.LittleEndian.Uint16(b[2:]))
+	valueLen := int(binary.LittleEndian.Uint32(b[4:]))
+	if b[0] != used || slotHeader+keyLen+valueLen > c.slotSize {
+		return b[0], nil, nil, 0
+	}
+	expiration = int64(binary.LittleEndian.Uint64(b[8:]))
+	return b[0], b[slotHeader : slotHeader+keyLen], b[slotHeader+keyLen : slotHeader+keyLen+valueLen], expiration
+}
+
+// put writes the entry to the slot, must be called under the exclusive lock
+func (c *Cache) put(slot int, h uint64, key string, value []byte, expiration int64) {
+	b := c.data[c.offset(slot):]
+	b[0] = used
+	binary.LittleEndian.PutUint16(b[2:], uint16(len(key)))
+	binary.LittleEndian.PutUint32(b[4:], uint32(len(value)))
+	binary.LittleEndian.PutUint64(b[8:], uint64(expiration))
+	binary.LittleEndian.PutUint64(b[16:], h)
+	copy(b[slotHeader:], key)
+	copy(b[slotHeader+len(key):], value)
+}
+
+// offset returns the offset of the slot in the file
+func (c *Cache) offset(slot int) int {
+	return fileHeader + slot*c.slotSize
+}
+
+func hash(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return h.Sum64()
+}
+
+func expired(expiration int64) bool {
+	return expiration != 0 && expiration < time.Now().UnixNano()
+}
@@ -0,0 +1,191 @@
+//go:build linux || darwin || freebsd
+
+package shm
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache"
+)
+
+func TestCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	c, err := Open(path, 64, 128)
+	require.NoError(t, err)
+	var _ mcache.Cacher[[]byte] = c
+
+	assert.True(t, c.Set("key", []byte("value"), 0))
+	assert.True(t, c.Set("key", []byte("other"), 0))
+	assert.True(t, c.Set("key", []byte("value"), 0))
+	v, err := c.Get("key")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), v)
+	has, err := c.Has("key")
+	require.NoError(t, err)
+	assert.True(t, has)
+	_, err = c.Get("missing")
+	assert.ErrorIs(t, err, mcache.ErrKeyNotFound)
+
+	assert.False(t, c.Set("big", make([]byte, 128), 0), "entry doesn't fit in a slot")
+
+	// expired key is deleted by Get, and can be set again
+	assert.True(t, c.Set("expired", []byte("value"), time.Millisecond))
+	time.Sleep(2 * time.Millisecond)
+	_, err = c.Get("expired")
+	assert.ErrorIs(t, err, mcache.ErrExpired)
+	_, err = c.Get("expired")
+	assert.ErrorIs(t, err, mcache.ErrKeyNotFound)
+	assert.True(t, c.Set("expired", []byte("value"), time.Millisecond))
+	time.Sleep(2 * time.Millisecond)
+	assert.True(t, c.Set("expired", []byte("again"), 0))
+	v, err = c.Get("expired")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("again"), v)
+
+	require.NoError(t, c.Del("key"))
+	assert.ErrorIs(t, c.Del("key"), mcache.ErrKeyNotFound)
+	c.Set("ttl", []byte("value"), time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	assert.ErrorIs(t, c.Del("ttl"), mcache.ErrExpired)
+	c.Set("ttl", []byte("value"), time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	c.Cleanup()
+	_, err = c.Get("ttl")
+	assert.ErrorIs(t, err, mcache.ErrKeyNotFound)
+
+	require.NoError(t, c.Clear())
+	_, err = c.Get("expired")
+	assert.ErrorIs(t, err, mcache.ErrKeyNotFound)
+
+	require.NoError(t, c.Close())
+	assert.ErrorIs(t, c.Close(), mcache.ErrClosed)
+	_, err = c.Get("key")
+	assert.ErrorIs(t, err, mcache.ErrClosed)
+	assert.False(t, c.Set("key", nil, 0))
+}
+
+func TestCacheShared(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	a, err := Open(path, 1024, 64)
+	require.NoError(t, err)
+	defer a.Close()
+	// the second mapping of the file stands for another process
+	b, err := Open(path, 1024, 64)
+	require.NoError(t, err)
+	defer b.Close()
+
+	wg := sync.WaitGroup{}
+	for name, c := range map[string]*Cache{"a": a, "b": b} {
+		wg.Add(1)
+		go func(c *Cache, name string) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				assert.True(t, c.Set(name+strconv.Itoa(i), []byte(strconv.Itoa(i)), 0))
+			}
+		}(c, name)
+	}
+	wg.Wait()
+
+	for _, name := range []string{"a", "b"} {
+		for i := 0; i < 100; i++ {
+			v, err := b.Get(name + strconv.Itoa(i))
+			require.NoError(t, err)
+			assert.Equal(t, strconv.Itoa(i), string(v))
+		}
+	}
+	require.NoError(t, a.Del("a0"))
+	_, err = b.Get("a0")
+	assert.ErrorIs(t, err, mcache.ErrKeyNotFound)
+
+	_, err = Open(path, 512, 64)
+	assert.ErrorIs(t, err, ErrLayout)
+	_, err = Open(path, 0, 64)
+	assert.Error(t, err)
+	_, err = Open(filepath.Join(path, "missing", "dir"), 16, 64)
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(path+"2", make([]byte, fileHeader+16*64), 0o600))
+	_, err = Open(path+"2", 16, 64)
+	assert.ErrorIs(t, err, ErrLayout, "file of the right size without the header")
+}
+
+func TestCacheSharedConcurrentReaders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	readers, err := Open(path, 64, 64)
+	require.NoError(t, err)
+	defer readers.Close()
+	// the second mapping of the file stands for another process
+	writer, err := Open(path, 64, 64)
+	require.NoError(t, err)
+	defer writer.Close()
+	require.True(t, writer.Set("key", []byte("value"), 0))
+
+	entered, release := make(chan struct{}), make(chan struct{})
+	go readers.read(func() { //nolint:errcheck // not closed
+		close(entered)
+		<-release
+	})
+	<-entered
+	// a reader finished while another one is still reading doesn't release the file lock
+	v, err := readers.Get("key")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), v)
+
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		assert.True(t, writer.Set("key", []byte("other"), 0))
+	}()
+	select {
+	case <-written:
+		close(release)
+		t.Fatal("written during a read")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	<-written
+	v, err = readers.Get("key")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("other"), v)
+}
+
+func TestCacheFull(t *testing.T) {
+	c, err := Open(filepath.Join(t.TempDir(), "cache"), maxProbes, 64)
+	require.NoError(t, err)
+	defer c.Close()
+	for i := 0; i < maxProbes; i++ {
+		require.True(t, c.Set(strconv.Itoa(i), nil, 0))
+	}
+	assert.False(t, c.Set("more", nil, 0))
+}
+
+func TestCacheFullOfExpired(t *testing.T) {
+	c, err := Open(filepath.Join(t.TempDir(), "cache"), maxProbes, 64)
+	require.NoError(t, err)
+	defer c.Close()
+	for i := 0; i < maxProbes; i++ {
+		require.True(t, c.Set(strconv.Itoa(i), nil, time.Millisecond))
+	}
+	time.Sleep(2 * time.Millisecond)
+
+	// slots of expired entries are reused without Cleanup
+	for i := 0; i < maxProbes; i++ {
+		require.True(t, c.Set("new"+strconv.Itoa(i), []byte("value"), 0), i)
+	}
+	assert.False(t, c.Set("more", nil, 0))
+	for i := 0; i < maxProbes; i++ {
+		v, err := c.Get("new" + strconv.Itoa(i))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("value"), v)
+		_, err = c.Get(strconv.Itoa(i))
+		assert.ErrorIs(t, err, mcache.ErrKeyNotFound)
+	}
+}
@@ -0,0 +1,159 @@
+package mcache
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// flight is an in-progress or completed call of flightGroup
+type flight[T any] struct {
+	wg    sync.WaitGroup
+	val   T
+	err   error
+	panic *panicError // fn panicked, the panic is raised again in all callers
+}
+
+// panicError is a panic of a flightGroup function with the stack trace of the panicking goroutine
+type panicError struct {
+	value any
+	stack []byte
+}
+
+func (p *panicError) Error() string {
+	return fmt.Sprintf("%v\n\n%s", p.value, p.stack)
+}
+
+// Unwrap returns the panic value, if it's an error
+func (p *panicError) Unwrap() error {
+	err, _ := p.value.(error)
+	return err
+}
+
+// wait waits for the flight and raises its panic, if fn panicked
+func (f *flight[T]) wait() {
+	f.wg.Wait()
+	if f.panic != nil {
+		panic(f.panic)
+	}
+}
+
+// flightGroup deduplicates concurrent calls with the same key, like golang.org/x/sync/singleflight
+type flightGroup[T any] struct {
+	mu      sync.Mutex
+	flights map[string]*flight[T]
+}
+
+// do calls fn once for all concurrent callers with the key, they all get its result.
+// If fn panics, the panic is raised in all callers, so a failed call never looks like a successful one.
+func (g *flightGroup[T]) do(key string, fn func() (T, error)) (T, error) {
+	g.mu.Lock()
+	if g.flights == nil {
+		g.flights = make(map[string]*flight[T])
+	}
+	if f, ok := g.flights[key]; ok {
+		g.mu.Unlock()
+		f.wait()
+		return f.val, f.err
+	}
+	f := &flight[T]{}
+	f.wg.Add(1)
+	g.flights[key] = f
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.flights, key)
+		g.mu.Unlock()
+		f.wg.Done()
+		if f.panic != nil {
+			panic(f.panic)
+		}
+	}()
+	f.val, f.err = call(fn, &f.panic)
+	return f.val, f.err
+}
+
+// call calls fn, recovering its panic into p
+func call[R any](fn func() (R, error), p **panicError) (v R, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			*p = &panicError{value: r, stack: debug.Stack()}
+		}
+	}()
+	return fn()
+}
+
+// doMany is do for many keys at once: fn is called once with the keys having no call in progress,
+// and the other keys wait for calls in progress. Values of all keys are returned, keys failed
+// with ErrKeyNotFound or ErrNegativeCached are left out, the first other error is returned.
+// Keys missing in the map returned by fn fail with ErrKeyNotFound.
+func (g *flightGroup[T]) doMany(keys []string, fn func(keys []string) (map[string]T, error)) (map[string]T, error) {
+	g.mu.Lock()
+	if g.flights == nil {
+		g.flights = make(map[string]*flight[T])
+	}
+	flights := make(map[string]*flight[T], len(keys))
+	owned := make(map[string]*flight[T])
+	var own []string
+	for _, key := range keys {
+		if _, ok := flights[key]; ok {
+			continue
+		}
+		if f, ok := g.flights[key]; ok {
+			flights[key] = f
+			continue
+		}
+		f := &flight[T]{}
+		f.wg.Add(1)
+		g.flights[key], flights[key], owned[key] = f, f, f
+		own = append(own, key)
+	}
+	g.mu.Unlock()
+
+	if len(own) > 0 {
+		// own keys are completed before waiting for others, so overlapping calls don't deadlock
+		func() {
+			defer func() {
+				g.mu.Lock()
+				for key := range owned {
+					delete(g.flights, key)
+				}
+				g.mu.Unlock()
+				for _, f := range owned {
+					f.wg.Done()
+				}
+			}()
+			var p *panicError
+			values, err := call(func() (map[string]T, error) { return fn(own) }, &p)
+			for key, f := range owned {
+				v, ok := values[key]
+				switch {
+				case p != nil:
+					f.panic = p
+				case err != nil:
+					f.err = err
+				case !ok:
+					f.err = ErrKeyNotFound
+				default:
+					f.val = v
+				}
+			}
+		}()
+	}
+
+	values := make(map[string]T, len(flights))
+	var err error
+	for key, f := range flights {
+		f.wait()
+		switch {
+		case f.err == nil:
+			values[key] = f.val
+		case errors.Is(f.err, ErrKeyNotFound), errors.Is(f.err, ErrNegativeCached):
+		case err == nil:
+			err = f.err
+		}
+	}
+	return values, err
+}
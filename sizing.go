@@ -0,0 +1,92 @@
+package mcache
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// WithDeepSizing is a functional option for sizing values by following their strings, slices, maps
+// and pointers with reflection, instead of counting only the value itself and the contents of
+// a top-level string or slice. Sizes of values like decoded JSON documents get close to the memory
+// they hold, but sizing costs a walk over every value. Sizes are reported by EstimatedBytes,
+// PrefixStats and Dump. Memory shared by several values is counted for each of them.
+func WithDeepSizing[T any]() func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.deepSizing = true
+	}
+}
+
+// EstimatedBytes returns the approximate memory held by keys and values of live entries,
+// values are sized WithDeepSizing, or approximated like in Dump otherwise
+func (c *Cache[T]) EstimatedBytes() int {
+	return c.PrefixStats("").Bytes
+}
+
+// sizeOf returns the approximate size of the value, deep WithDeepSizing
+func (c *Cache[T]) sizeOf(v T) int {
+	if c.deepSizing {
+		return deepSize(reflect.ValueOf(&v).Elem(), map[uintptr]bool{})
+	}
+	return valueSize(v)
+}
+
+// deepSize returns the size of the value and the memory it references, pointers seen
+// in visited are not followed again, so cycles and values shared within v are counted once
+func deepSize(v reflect.Value, visited map[uintptr]bool) int {
+	return int(v.Type().Size()) + referencedSize(v, visited)
+}
+
+// referencedSize returns the size of the memory referenced by the value, not counting the value itself
+func referencedSize(v reflect.Value, visited map[uintptr]bool) int {
+	switch v.Kind() {
+	case reflect.String:
+		return v.Len()
+	case reflect.Pointer:
+		if v.IsNil() || visited[v.Pointer()] {
+			return 0
+		}
+		visited[v.Pointer()] = true
+		return deepSize(v.Elem(), visited)
+	case reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		return deepSize(v.Elem(), visited)
+	case reflect.Slice:
+		if v.IsNil() || visited[v.Pointer()] {
+			return 0
+		}
+		visited[v.Pointer()] = true
+		size := v.Cap() * int(v.Type().Elem().Size())
+		for i := 0; i < v.Len(); i++ {
+			size += referencedSize(v.Index(i), visited)
+		}
+		return size
+	case reflect.Array:
+		size := 0
+		for i := 0; i < v.Len(); i++ {
+			size += referencedSize(v.Index(i), visited)
+		}
+		return size
+	case reflect.Struct:
+		size := 0
+		for i := 0; i < v.NumField(); i++ {
+			size += referencedSize(v.Field(i), visited)
+		}
+		return size
+	case reflect.Map:
+		if v.IsNil() || visited[v.Pointer()] {
+			return 0
+		}
+		visited[v.Pointer()] = true
+		// buckets aren't visible with reflection, count keys and values with a pointer per entry of overhead
+		entry := int(v.Type().Key().Size()+v.Type().Elem().Size()) + int(unsafe.Sizeof(uintptr(0)))
+		size := v.Len() * entry
+		iter := v.MapRange()
+		for iter.Next() {
+			size += referencedSize(iter.Key(), visited) + referencedSize(iter.Value(), visited)
+		}
+		return size
+	}
+	return 0
+}
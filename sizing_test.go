@@ -0,0 +1,41 @@
+package mcache
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDeepSizing(t *testing.T) {
+	type doc struct {
+		Title string
+		Tags  []string
+		Attrs map[string]any
+	}
+	d := doc{
+		Title: "title",
+		Tags:  []string{"a", "bb"},
+		Attrs: map[string]any{"count": 1, "name": "value"},
+	}
+
+	flat := NewCache[doc]()
+	deep := NewCache(WithDeepSizing[doc]())
+	require.True(t, flat.Set("doc", d, 0))
+	require.True(t, deep.Set("doc", d, 0))
+
+	assert.Equal(t, len("doc")+valueSize(d), flat.EstimatedBytes())
+	size := deep.EstimatedBytes() - len("doc")
+	assert.Greater(t, size, valueSize(d))
+	strs := len("title") + len("a") + len("bb") + len("count") + len("name") + len("value")
+	assert.GreaterOrEqual(t, size, valueSize(d)+2*16+strs, "strings and slice contents are counted")
+
+	type node struct {
+		next *node
+		name string
+	}
+	n := &node{name: "loop"}
+	n.next = n
+	assert.Equal(t, 8+(8+16+len("loop")), deepSize(reflect.ValueOf(n), map[uintptr]bool{}), "cycles are counted once")
+}
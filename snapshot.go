@@ -0,0 +1,108 @@
+package mcache
+
+import "maps"
+
+// ReadOnlyCache is a read-only view of a cache, returned by Snapshot
+type ReadOnlyCache[T any] interface {
+	Get(key string) (T, error)
+	Has(key string) (bool, error)
+	Keys() []string
+	Len() int
+}
+
+// snapshot is a frozen copy of the cache map, entries are checked for expiration at the snapshot time
+type snapshot[T any] struct {
+	data      map[string]CacheItem[T]
+	at        int64
+	keyErrors bool
+}
+
+// Snapshot returns an immutable point-in-time view of the cache, for reports and other readers
+// needing a consistent view while the cache keeps changing. Snapshot reads don't lock the cache,
+// and they don't count hits or touch entries. Entries live at the moment of the snapshot stay
+// visible until it's dropped, entries spilled WithOverflow are not included.
+// The map is cloned, WithCopyOnWrite the published map is shared without copying.
+func (c *Cache[T]) Snapshot() ReadOnlyCache[T] {
+	return c.snapshot()
+}
+
+// snapshot takes the snapshot returned by Snapshot
+func (c *Cache[T]) snapshot() *snapshot[T] {
+	c.RLock()
+	defer c.RUnlock()
+	return c.snapshotLocked()
+}
+
+// snapshotLocked is snapshot under the read lock
+func (c *Cache[T]) snapshotLocked() *snapshot[T] {
+	s := &snapshot[T]{at: c.now(), keyErrors: c.keyErrors}
+	if c.cow && len(c.old) == 0 {
+		s.data = c.data // writers copy the map before changing it WithCopyOnWrite
+		return s
+	}
+	s.data = maps.Clone(c.data)
+	for k, v := range c.old {
+		if _, ok := s.data[k]; !ok {
+			s.data[k] = v
+		}
+	}
+	return s
+}
+
+// item returns the item of the key live at the snapshot time
+func (s *snapshot[T]) item(key string) (CacheItem[T], error) {
+	item, ok := s.data[key]
+	if !ok || item.expiredAt(s.at) {
+		if s.keyErrors {
+			return CacheItem[T]{}, wrapKey(ErrKeyNotFound, key)
+		}
+		return CacheItem[T]{}, ErrKeyNotFound
+	}
+	if item.negative {
+		return item, ErrNegativeCached
+	}
+	return item, nil
+}
+
+// Get returns the value of the key at the snapshot time
+func (s *snapshot[T]) Get(key string) (T, error) {
+	item, err := s.item(key)
+	return item.value, err
+}
+
+// GetVersioned returns the value of the key at the snapshot time with its version
+func (s *snapshot[T]) GetVersioned(key string) (T, uint64, error) {
+	item, err := s.item(key)
+	if err != nil && err != ErrNegativeCached {
+		return item.value, 0, err
+	}
+	return item.value, item.version, err
+}
+
+// Has checks if the key existed at the snapshot time
+func (s *snapshot[T]) Has(key string) (bool, error) {
+	_, err := s.item(key)
+	return err == nil, err
+}
+
+// Keys returns keys existing at the snapshot time, in no particular order
+func (s *snapshot[T]) Keys() []string {
+	keys := make([]string, 0, len(s.data))
+	for k, v := range s.data {
+		if !v.expiredAt(s.at) && !v.negative {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// Len returns the number of entries existing at the snapshot time
+func (s *snapshot[T]) Len() int {
+	n := 0
+	for _, v := range s.data {
+		if !v.expiredAt(s.at) && !v.negative {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,55 @@
+package mcache
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache/clocktest"
+)
+
+func TestSnapshot(t *testing.T) {
+	for name, opt := range map[string]func(*Cache[int]){
+		"map":         func(*Cache[int]) {},
+		"copyOnWrite": WithCopyOnWrite[int](),
+		"generations": WithGenerations[int](),
+	} {
+		t.Run(name, func(t *testing.T) {
+			clock := clocktest.New(time.Now())
+			cache := NewCache(opt, WithClock[int](clock))
+			require.True(t, cache.Set("a", 1, 0))
+			require.True(t, cache.Set("b", 2, time.Minute))
+			require.True(t, cache.Set("expired", 3, time.Second))
+			require.True(t, cache.SetNegative("missing", time.Hour))
+			clock.Advance(2 * time.Second)
+
+			snap := cache.Snapshot()
+			require.NoError(t, cache.Del("a"))
+			require.True(t, cache.Set("c", 4, 0))
+			clock.Advance(2 * time.Minute)
+
+			v, err := snap.Get("a")
+			assert.NoError(t, err)
+			assert.Equal(t, 1, v)
+			v, err = snap.Get("b")
+			assert.NoError(t, err, "live at the snapshot time")
+			assert.Equal(t, 2, v)
+			_, err = snap.Get("c")
+			assert.ErrorIs(t, err, ErrKeyNotFound)
+			_, err = snap.Get("expired")
+			assert.ErrorIs(t, err, ErrKeyNotFound)
+			has, err := snap.Has("missing")
+			assert.ErrorIs(t, err, ErrNegativeCached)
+			assert.False(t, has)
+
+			keys := snap.Keys()
+			sort.Strings(keys)
+			assert.Equal(t, []string{"a", "b"}, keys)
+			assert.Equal(t, 2, snap.Len())
+			assert.Equal(t, uint64(0), cache.Stats().Hits, "snapshot reads are not counted")
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package mcache
+
+import (
+	"errors"
+	"time"
+)
+
+// GetStaleOrLoad returns the live value of the key, or loads it with loader on a miss and sets it with ttl.
+// If loader fails and the key has an expired value still kept in the cache, the stale value is returned
+// without an error, so reads degrade gracefully during upstream outages, and the failure is logged.
+// Expired values are kept until Cleanup, WithLazyDeletion(false) keeps Get from deleting them too.
+// Concurrent calls of the same missing key share a single loader call.
+func (c *Cache[T]) GetStaleOrLoad(key string, ttl time.Duration, loader func() (T, error)) (T, error) {
+	stale, hasStale := c.staleValue(key)
+	v, err := c.Get(key)
+	if err == nil || errors.Is(err, ErrClosed) || errors.Is(err, ErrNegativeCached) {
+		return v, err
+	}
+
+	return c.loads.do(key, func() (T, error) {
+		// the key may have been loaded by a call which has just finished
+		if item, err := c.lookup(key); err == nil {
+			if item.negative {
+				return item.value, ErrNegativeCached
+			}
+			return item.value, nil
+		}
+		v, err := loader()
+		if err != nil {
+			if hasStale {
+				c.logger.Warn("mcache: loader failed, serving stale value", "key", key, "err", err)
+				return stale, nil
+			}
+			return v, err
+		}
+		c.Set(key, v, ttl)
+		return v, nil
+	})
+}
+
+// staleValue returns the value of the key, even if it's expired, negative entries have no value
+func (c *Cache[T]) staleValue(key string) (value T, ok bool) {
+	c.RLock()
+	defer c.RUnlock()
+	item, ok := c.data[key]
+	if !ok {
+		item, ok = c.old[key]
+	}
+	if !ok || item.negative {
+		return value, false
+	}
+	return item.value, true
+}
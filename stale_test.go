@@ -0,0 +1,93 @@
+package mcache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache/clocktest"
+)
+
+func TestGetStaleOrLoad(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	cache := NewCache(WithClock[string](clock))
+	down := errors.New("upstream is down")
+	failing := func() (string, error) { return "", down }
+	loads := 0
+	loader := func() (string, error) {
+		loads++
+		return "loaded", nil
+	}
+
+	v, err := cache.GetStaleOrLoad("key", time.Minute, loader)
+	require.NoError(t, err)
+	assert.Equal(t, "loaded", v)
+	v, err = cache.GetStaleOrLoad("key", time.Minute, failing)
+	require.NoError(t, err, "live value is returned without loading")
+	assert.Equal(t, "loaded", v)
+	assert.Equal(t, 1, loads)
+
+	// expired value is served when the loader fails
+	clock.Advance(time.Minute + 1)
+	v, err = cache.GetStaleOrLoad("key", time.Minute, failing)
+	require.NoError(t, err)
+	assert.Equal(t, "loaded", v)
+
+	// but not after it's cleaned up
+	cache.Set("key", "old", time.Second)
+	clock.Advance(2 * time.Second)
+	cache.Cleanup()
+	_, err = cache.GetStaleOrLoad("key", time.Minute, failing)
+	assert.ErrorIs(t, err, down)
+	_, err = cache.GetStaleOrLoad("missing", time.Minute, failing)
+	assert.ErrorIs(t, err, down)
+
+	// expired value is replaced when the loader succeeds
+	cache.Set("key", "old", time.Second)
+	clock.Advance(2 * time.Second)
+	v, err = cache.GetStaleOrLoad("key", time.Minute, loader)
+	require.NoError(t, err)
+	assert.Equal(t, "loaded", v)
+	info, err := cache.EntryInfo("key")
+	require.NoError(t, err)
+	assert.True(t, clock.Now().Add(time.Minute).Equal(info.Expiration))
+
+	cache.SetNegative("negative", time.Minute)
+	_, err = cache.GetStaleOrLoad("negative", time.Minute, loader)
+	assert.ErrorIs(t, err, ErrNegativeCached)
+
+	require.NoError(t, cache.Close())
+	_, err = cache.GetStaleOrLoad("key", time.Minute, loader)
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestGetStaleOrLoadConcurrent(t *testing.T) {
+	cache := NewCache[int]()
+	var loads atomic.Int32
+	release := make(chan struct{})
+	loader := func() (int, error) {
+		loads.Add(1)
+		<-release
+		return 1, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := cache.GetStaleOrLoad("key", 0, loader)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, v)
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), loads.Load())
+}
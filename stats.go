@@ -0,0 +1,88 @@
+package mcache
+
+import "time"
+
+// Stats is a point-in-time snapshot of cache counters.
+type Stats struct {
+	Hits        uint64        // Get calls that returned a value
+	Misses      uint64        // Get calls for missing or expired keys
+	Evictions   uint64        // expired entries removed by Get, Has or Cleanup, and entries evicted WithMaxEntries
+	Entries     int           // entries currently stored, including expired but not yet removed
+	Cleanups    uint64        // Cleanup runs
+	CleanupTime time.Duration // total time spent in Cleanup
+	SetTTLs     TTLHistogram  // TTLs passed to Set and its variants, WithTTLHistogram
+	TTLs        TTLHistogram  // remaining TTLs of live entries, WithTTLHistogram
+	LockWait    time.Duration // total time spent waiting for the lock, WithLockStats
+}
+
+// HitRatio returns Hits/(Hits+Misses), or 0 if there were no Get calls.
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// add returns the sum of counters of both snapshots
+func (s Stats) add(o Stats) Stats {
+	s.Hits += o.Hits
+	s.Misses += o.Misses
+	s.Evictions += o.Evictions
+	s.Entries += o.Entries
+	s.Cleanups += o.Cleanups
+	s.CleanupTime += o.CleanupTime
+	s.SetTTLs = s.SetTTLs.add(o.SetTTLs)
+	s.TTLs = s.TTLs.add(o.TTLs)
+	s.LockWait += o.LockWait
+	return s
+}
+
+// Stats returns current cache counters.
+func (c *Cache[T]) Stats() Stats {
+	var ttls TTLHistogram
+	c.RLock()
+	entries := len(c.data) + len(c.old)
+	if c.setTTLs != nil {
+		ttls = c.remainingTTLs()
+	}
+	c.RUnlock()
+
+	s := Stats{
+		Hits:        c.hits.Load(),
+		Misses:      c.misses.Load(),
+		Evictions:   c.evictions.Load(),
+		Entries:     entries,
+		Cleanups:    c.cleanups.Load(),
+		CleanupTime: time.Duration(c.cleanupTime.Load()),
+		TTLs:        ttls,
+		LockWait:    time.Duration(c.lockWait.Load()),
+	}
+	if c.setTTLs != nil {
+		s.SetTTLs = c.setTTLs.load()
+	}
+	return s
+}
+
+func (c *Cache[T]) hit() {
+	c.hits.Add(1)
+	c.metrics.IncHit()
+}
+
+func (c *Cache[T]) miss() {
+	c.misses.Add(1)
+	c.metrics.IncMiss()
+}
+
+func (c *Cache[T]) evicted(n int) {
+	c.evictions.Add(uint64(n))
+	c.metrics.IncEviction(n)
+}
+
+// cleaned counts Cleanup run, including evictions
+func (c *Cache[T]) cleaned(removed int, took time.Duration) {
+	c.evicted(removed)
+	c.cleanups.Add(1)
+	c.cleanupTime.Add(int64(took))
+	c.metrics.ObserveCleanup(removed, took)
+}
@@ -0,0 +1,141 @@
+package mcache
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+	cache := NewCache[string]()
+	assert.Equal(t, float64(0), cache.Stats().HitRatio())
+
+	cache.Set("key", "value", 0)
+	cache.Set("expired", "value", time.Millisecond)
+	cache.Set("expired2", "value", time.Millisecond)
+	cache.Set("expired3", "value", time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+
+	cache.Get("key")
+	cache.Get("key")
+	cache.Get("key")
+	cache.Get("noSuchKey")
+	cache.Get("expired")
+	cache.Has("expired2")
+
+	s := cache.Stats()
+	assert.Equal(t, uint64(3), s.Hits)
+	assert.Equal(t, uint64(2), s.Misses)
+	assert.Equal(t, uint64(2), s.Evictions)
+	assert.Equal(t, 2, s.Entries)
+	assert.Equal(t, 0.6, s.HitRatio())
+
+	cache.Cleanup()
+	s = cache.Stats()
+	assert.Equal(t, uint64(3), s.Evictions)
+	assert.Equal(t, 1, s.Entries)
+	assert.Equal(t, uint64(1), s.Cleanups)
+	assert.Greater(t, s.CleanupTime, time.Duration(0))
+}
+
+func TestCleanupN(t *testing.T) {
+	cache := NewCache[string]()
+	cache.Set("key", "value", 0)
+	cache.Set("expired", "value", time.Millisecond)
+	cache.Set("expired2", "value", time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+
+	removed, took := cache.CleanupN()
+	assert.Equal(t, 2, removed)
+	assert.Greater(t, took, time.Duration(0))
+
+	removed, _ = cache.CleanupN()
+	assert.Equal(t, 0, removed)
+
+	s := cache.Stats()
+	assert.Equal(t, uint64(2), s.Cleanups)
+	assert.Equal(t, uint64(2), s.Evictions)
+	assert.GreaterOrEqual(t, s.CleanupTime, took)
+
+	cache.Close()
+	removed, took = cache.CleanupN()
+	assert.Equal(t, 0, removed)
+	assert.Equal(t, time.Duration(0), took)
+}
+
+func TestCleanupWithBudget(t *testing.T) {
+	cache := NewCache[int]()
+	for i := 0; i < 5000; i++ {
+		cache.Set("live_"+strconv.Itoa(i), i, 0)
+		cache.Set("expired_"+strconv.Itoa(i), i, time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	// zero budget scans a single chunk
+	removed, _ := cache.CleanupWithBudget(0)
+	assert.Greater(t, removed, 0)
+	assert.LessOrEqual(t, removed, cleanupChunk)
+
+	// runs with enough budget eventually remove everything
+	for i := 0; i < 100 && cache.Stats().Entries > 5000; i++ {
+		cache.CleanupWithBudget(time.Second)
+	}
+	s := cache.Stats()
+	assert.Equal(t, 5000, s.Entries)
+	assert.Equal(t, uint64(5000), s.Evictions)
+	for i := 0; i < 5000; i++ {
+		v, err := cache.Get("live_" + strconv.Itoa(i))
+		require.NoError(t, err)
+		require.Equal(t, i, v)
+	}
+
+	cache.Close()
+	removed, _ = cache.CleanupWithBudget(time.Second)
+	assert.Equal(t, 0, removed)
+}
+
+func TestWithInPlaceCleanup(t *testing.T) {
+	cache := NewCache(WithInPlaceCleanup[int](), WithSize[int](100))
+	for i := 0; i < 100; i++ {
+		cache.Set("live_"+strconv.Itoa(i), i, 0)
+		cache.Set("expired_"+strconv.Itoa(i), i, time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	data := cache.data
+	removed, _ := cache.CleanupN()
+	assert.Equal(t, 100, removed)
+	assert.Equal(t, 100, cache.Stats().Entries)
+	assert.Equal(t, uint64(100), cache.Stats().Evictions)
+	// same map is kept
+	assert.Equal(t, reflect.ValueOf(data).Pointer(), reflect.ValueOf(cache.data).Pointer())
+
+	v, err := cache.Get("live_99")
+	assert.NoError(t, err)
+	assert.Equal(t, 99, v)
+}
+
+func TestWithSampledCleanup(t *testing.T) {
+	cache := NewCache(WithSampledCleanup[int](20))
+	for i := 0; i < 1000; i++ {
+		cache.Set("expired_"+strconv.Itoa(i), i, time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	for i := 0; i < 1000; i++ {
+		cache.Set("live_"+strconv.Itoa(i), i, 0)
+	}
+	s := cache.Stats()
+	assert.Less(t, s.Entries, 1500, "most of expired entries should be removed by writes")
+	assert.Equal(t, uint64(2000-s.Entries), s.Evictions)
+	assert.Equal(t, uint64(0), s.Cleanups)
+
+	for i := 0; i < 1000; i++ {
+		_, err := cache.Get("live_" + strconv.Itoa(i))
+		require.NoError(t, err)
+	}
+}
@@ -0,0 +1,59 @@
+package mcache
+
+import (
+	"context"
+	"time"
+)
+
+// Store is a backing store the cache is in front of, like a database.
+// Load returns ErrKeyNotFound for missing keys.
+type Store[T any] interface {
+	Load(ctx context.Context, key string) (value T, ttl time.Duration, err error)
+	Save(ctx context.Context, key string, value T, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// WithReadThrough is a functional option for loading keys missing in the cache from the store:
+// Get of a missing or expired key loads it with store.Load, caches it with the returned ttl
+// and returns it. Concurrent Gets of the same key share a single Load.
+// Store errors are returned as is, and nothing is cached. Has doesn't consult the store.
+func WithReadThrough[T any](store Store[T]) func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.backing = store
+	}
+}
+
+// readThrough loads the missing key from the backing store and caches it
+func (c *Cache[T]) readThrough(key string) (T, error) {
+	return c.loads.do(key, func() (T, error) {
+		// the key may have been loaded by a call which has just finished
+		if item, err := c.lookup(key); err == nil {
+			if item.negative {
+				return item.value, ErrNegativeCached
+			}
+			return item.value, nil
+		}
+		if c.knownMissing(key) {
+			var none T
+			return none, c.keyError(ErrKeyNotFound, key)
+		}
+		v, ttl, err := c.backing.Load(context.Background(), key)
+		if err != nil {
+			c.loaded(key, err)
+			return v, c.keyError(err, key)
+		}
+		c.fill(key, v, ttl)
+		return v, nil
+	})
+}
+
+// fill caches the value loaded from the store, unless the key was set meanwhile.
+// Unlike Set, it's not written back to the store WithWriteBehind.
+func (c *Cache[T]) fill(key string, value T, ttl time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+	if item, err := c.lookupLocked(key); (err == nil && !item.negative) || err == ErrClosed {
+		return
+	}
+	c.setLocked(key, value, ttl)
+}
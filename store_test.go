@@ -0,0 +1,115 @@
+package mcache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapStore is a Store in a map, counting loads
+type mapStore[T any] struct {
+	mu    sync.Mutex
+	data  map[string]T
+	loads atomic.Int32
+	err   error // returned by all methods, if set
+}
+
+func newMapStore[T any]() *mapStore[T] {
+	return &mapStore[T]{data: make(map[string]T)}
+}
+
+func (s *mapStore[T]) Load(_ context.Context, key string) (value T, ttl time.Duration, err error) {
+	s.loads.Add(1)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return value, 0, s.err
+	}
+	v, ok := s.data[key]
+	if !ok {
+		return value, 0, ErrKeyNotFound
+	}
+	return v, time.Minute, nil
+}
+
+func (s *mapStore[T]) Save(_ context.Context, key string, value T, _ time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.data[key] = value
+	return nil
+}
+
+func (s *mapStore[T]) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	delete(s.data, key)
+	return nil
+}
+
+func (s *mapStore[T]) get(key string) (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.data[key]
+	return v, ok
+}
+
+func TestWithReadThrough(t *testing.T) {
+	store := newMapStore[string]()
+	store.data["stored"] = "value"
+	cache := NewCache(WithReadThrough[string](store))
+
+	v, err := cache.Get("stored")
+	require.NoError(t, err)
+	assert.Equal(t, "value", v)
+	info, err := cache.EntryInfo("stored")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), info.Expiration, time.Second)
+
+	// cached value is not loaded again
+	_, err = cache.Get("stored")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), store.loads.Load())
+
+	_, err = cache.Get("missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.Equal(t, Stats{Hits: 1, Misses: 2, Entries: 1}, cache.Stats())
+
+	store.err = errors.New("store failed")
+	_, err = cache.Get("other")
+	assert.ErrorIs(t, err, store.err)
+
+	require.NoError(t, cache.Close())
+	_, err = cache.Get("stored")
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestWithReadThroughConcurrent(t *testing.T) {
+	store := newMapStore[int]()
+	store.data["key"] = 42
+	cache := NewCache(WithReadThrough[int](store))
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := cache.Get("key")
+			assert.NoError(t, err)
+			assert.Equal(t, 42, v)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), store.loads.Load())
+}
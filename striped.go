@@ -0,0 +1,132 @@
+package mcache
+
+import (
+	"errors"
+	"hash/maphash"
+	"time"
+)
+
+// StripedCache is a cache split into stripes by key hash, every stripe is a Cache with its own lock,
+// so operations on different keys rarely contend. Clear takes all stripes at once,
+// Cleanup runs stripe by stripe, so it blocks only one stripe at a time.
+type StripedCache[T any] struct {
+	stripes []*Cache[T]
+	hash    func(key string) uint64
+}
+
+// NewStripedCache is a constructor for StripedCache of n stripes, options are applied to every stripe.
+// WithSize is a size of a single stripe, WithCleanup starts a goroutine per stripe,
+// WithHasher sets the hash selecting a stripe, maphash with a random seed by default.
+// Background tasks of stripes are staggered: the first run of a task of stripe i is delayed by i/n
+// of its interval, so WithCleanup sweeps stripes one after another, not all at once.
+// Options writing files (WithPersistence, WithWAL) can't be used, as stripes would share the file.
+func NewStripedCache[T any](n int, options ...func(*Cache[T])) *StripedCache[T] {
+	s := &StripedCache[T]{stripes: make([]*Cache[T], max(n, 1))}
+	for i := range s.stripes {
+		phase := float64(i) / float64(len(s.stripes))
+		s.stripes[i] = NewCache(append(options[:len(options):len(options)], withPhase[T](phase))...)
+	}
+	s.hash = s.stripes[0].hasher
+	if s.hash == nil {
+		seed := maphash.MakeSeed()
+		s.hash = func(key string) uint64 { return maphash.String(seed, key) }
+	}
+	return s
+}
+
+// stripe returns the stripe of the key
+func (s *StripedCache[T]) stripe(key string) *Cache[T] {
+	return s.stripes[s.hash(key)%uint64(len(s.stripes))]
+}
+
+// Set is Cache.Set on the stripe of the key
+func (s *StripedCache[T]) Set(key string, value T, ttl time.Duration) bool {
+	return s.stripe(key).Set(key, value, ttl)
+}
+
+// Get is Cache.Get on the stripe of the key
+func (s *StripedCache[T]) Get(key string) (T, error) {
+	return s.stripe(key).Get(key)
+}
+
+// Has is Cache.Has on the stripe of the key
+func (s *StripedCache[T]) Has(key string) (bool, error) {
+	return s.stripe(key).Has(key)
+}
+
+// Exists is Cache.Exists on the stripe of the key
+func (s *StripedCache[T]) Exists(key string) bool {
+	return s.stripe(key).Exists(key)
+}
+
+// Del is Cache.Del on the stripe of the key
+func (s *StripedCache[T]) Del(key string) error {
+	return s.stripe(key).Del(key)
+}
+
+// Cleanup deletes expired keys from every stripe, one stripe at a time
+func (s *StripedCache[T]) Cleanup() {
+	for _, c := range s.stripes {
+		c.Cleanup()
+	}
+}
+
+// Clear clears all stripes at once, with locks of all stripes taken
+func (s *StripedCache[T]) Clear() error {
+	for _, c := range s.stripes {
+		c.Lock()
+	}
+	errs := make([]error, 0, len(s.stripes))
+	for _, c := range s.stripes {
+		errs = append(errs, c.clearLocked())
+	}
+	for _, c := range s.stripes {
+		c.Unlock()
+	}
+	return joinErrors(errs)
+}
+
+// Stats returns counters summed over all stripes, Cleanups counts runs of every stripe
+func (s *StripedCache[T]) Stats() Stats {
+	var total Stats
+	for _, c := range s.stripes {
+		total = total.add(c.Stats())
+	}
+	return total
+}
+
+// ShardStats returns counters of every stripe, to diagnose skewed hashing by entries
+// and hot stripes by hit counts and LockWait, measured WithLockStats
+func (s *StripedCache[T]) ShardStats() []Stats {
+	stats := make([]Stats, len(s.stripes))
+	for i, c := range s.stripes {
+		stats[i] = c.Stats()
+	}
+	return stats
+}
+
+// Close closes all stripes, see Cache.Close
+func (s *StripedCache[T]) Close() error {
+	errs := make([]error, 0, len(s.stripes))
+	for _, c := range s.stripes {
+		errs = append(errs, c.Close())
+	}
+	return joinErrors(errs)
+}
+
+// joinErrors joins errors of all stripes, stripes are closed together, so ErrClosed is returned once
+func joinErrors(errs []error) error {
+	if errors.Is(errs[0], ErrClosed) {
+		return ErrClosed
+	}
+	return errors.Join(errs...)
+}
+
+// WithHasher is a functional option for setting the hash function selecting a stripe of a key
+// in StripedCache, like xxhash, or a hash of the tenant prefix only, to keep keys of a tenant
+// in a single stripe. A single Cache doesn't use it.
+func WithHasher[T any](hash func(key string) uint64) func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.hasher = hash
+	}
+}
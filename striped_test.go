@@ -0,0 +1,141 @@
+package mcache
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStripedCache(t *testing.T) {
+	var cache Cacher[int] = NewStripedCache[int](8)
+	for i := 0; i < 100; i++ {
+		assert.True(t, cache.Set(strconv.Itoa(i), i, time.Hour))
+	}
+	assert.True(t, cache.Set("0", 0, time.Hour))
+	cache.Set("expired", 0, time.Millisecond)
+
+	for i := 0; i < 100; i++ {
+		v, err := cache.Get(strconv.Itoa(i))
+		require.NoError(t, err)
+		assert.Equal(t, i, v)
+	}
+	has, err := cache.Has("99")
+	assert.NoError(t, err)
+	assert.True(t, has)
+	require.NoError(t, cache.Del("99"))
+	assert.ErrorIs(t, cache.Del("99"), ErrKeyNotFound)
+
+	time.Sleep(10 * time.Millisecond)
+	cache.Cleanup()
+
+	striped := cache.(*StripedCache[int])
+	stats := striped.Stats()
+	assert.Equal(t, 99, stats.Entries)
+	assert.Equal(t, uint64(100), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Evictions)
+	assert.Equal(t, uint64(8), stats.Cleanups)
+
+	// keys are spread over stripes
+	for _, c := range striped.stripes {
+		assert.Greater(t, c.Stats().Entries, 0)
+	}
+
+	require.NoError(t, cache.Clear())
+	assert.Equal(t, 0, striped.Stats().Entries)
+
+	require.NoError(t, striped.Close())
+	assert.ErrorIs(t, striped.Close(), ErrClosed)
+	assert.ErrorIs(t, cache.Clear(), ErrClosed)
+	_, err = cache.Get("0")
+	assert.ErrorIs(t, err, ErrClosed)
+
+	assert.Len(t, NewStripedCache[int](0).stripes, 1)
+}
+
+func TestStripedCacheConcurrent(t *testing.T) {
+	cache := NewStripedCache[int](4)
+	wg := sync.WaitGroup{}
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func(r int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				key := strconv.Itoa(r*1000 + i)
+				cache.Set(key, i, time.Hour)
+				_, _ = cache.Get(key)
+				if i%100 == 0 {
+					_ = cache.Clear()
+				}
+			}
+		}(r)
+	}
+	wg.Wait()
+}
+
+func TestWithHasher(t *testing.T) {
+	// hash of the tenant prefix keeps keys of a tenant in a single stripe
+	tenant := func(key string) uint64 {
+		prefix, _, _ := strings.Cut(key, "/")
+		n, _ := strconv.ParseUint(prefix, 10, 64)
+		return n
+	}
+	cache := NewStripedCache(4, WithHasher[int](tenant))
+	for i := 0; i < 10; i++ {
+		cache.Set("1/"+strconv.Itoa(i), i, 0)
+		cache.Set("2/"+strconv.Itoa(i), i, 0)
+	}
+	assert.Equal(t, 0, cache.stripes[0].Stats().Entries)
+	assert.Equal(t, 10, cache.stripes[1].Stats().Entries)
+	assert.Equal(t, 10, cache.stripes[2].Stats().Entries)
+
+	v, err := cache.Get("2/5")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, v)
+}
+
+func TestStripedCacheShardStats(t *testing.T) {
+	cache := NewStripedCache(4, WithLockStats[int](), WithHasher[int](func(key string) uint64 {
+		n, _ := strconv.ParseUint(key, 10, 64)
+		return n
+	}))
+	for i := 0; i < 10; i++ {
+		cache.Set(strconv.Itoa(i), i, 0)
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				cache.Get("1") // hot key
+			}
+		}()
+	}
+	wg.Wait()
+
+	shards := cache.ShardStats()
+	require.Len(t, shards, 4)
+	assert.Equal(t, []int{3, 3, 2, 2}, []int{shards[0].Entries, shards[1].Entries, shards[2].Entries, shards[3].Entries})
+	assert.Equal(t, uint64(4000), shards[1].Hits)
+	assert.Zero(t, shards[0].Hits)
+	assert.Greater(t, shards[1].LockWait, time.Duration(0))
+	assert.GreaterOrEqual(t, cache.Stats().LockWait, shards[0].LockWait+shards[1].LockWait+shards[2].LockWait+shards[3].LockWait)
+}
+
+func TestStripedCacheStaggeredCleanup(t *testing.T) {
+	cache := NewStripedCache(4, WithCleanup[int](200*time.Millisecond))
+	defer cache.Close()
+
+	// stripes are cleaned up at 200, 250, 300 and 350ms
+	cleanups := func(i int) uint64 { return cache.stripes[i].Stats().Cleanups }
+	require.Eventually(t, func() bool { return cleanups(0) == 1 }, time.Second, time.Millisecond)
+	assert.Zero(t, cleanups(2), "stripes are not cleaned up at once")
+	assert.Zero(t, cleanups(3))
+	require.Eventually(t, func() bool { return cleanups(3) == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, uint64(1), cleanups(0), "the next run of the first stripe is still ahead")
+}
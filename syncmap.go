@@ -0,0 +1,48 @@
+package mcache
+
+import "sync"
+
+// WithSyncMapBackend is a functional option for serving Get and Has from a sync.Map,
+// for workloads where goroutines mostly read disjoint sets of keys.
+// Reads don't take the cache lock, writes still go through it and update both the map
+// and the sync.Map, so entries take roughly twice the memory and every write allocates.
+// The API is the same, benchmark your access pattern against the default backend and WithCopyOnWrite,
+// which can't be combined with this option, the last one set wins.
+func WithSyncMapBackend[T any]() func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.syncMap = true
+		c.cow = false
+		c.smap.Store(newSyncMap(c.data))
+	}
+}
+
+// newSyncMap returns a sync.Map holding all entries of data
+func newSyncMap[T any](data map[string]CacheItem[T]) *sync.Map {
+	m := &sync.Map{}
+	for k, v := range data {
+		m.Store(k, v)
+	}
+	return m
+}
+
+// lookupSyncMap is lookup from the sync.Map WithSyncMapBackend, expired item is deleted under lock
+func (c *Cache[T]) lookupSyncMap(key string) (CacheItem[T], error) {
+	m := c.smap.Load()
+	if m == nil {
+		return CacheItem[T]{}, ErrClosed
+	}
+	v, ok := m.Load(key)
+	if !ok {
+		return CacheItem[T]{}, c.keyError(ErrKeyNotFound, key)
+	}
+	if item := v.(CacheItem[T]); !c.expired(item) {
+		return item, nil
+	}
+	if c.keepExpired {
+		return CacheItem[T]{}, c.keyError(ErrExpired, key)
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	return c.lookupLocked(key)
+}
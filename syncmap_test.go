@@ -0,0 +1,76 @@
+package mcache
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache/clocktest"
+)
+
+func TestWithSyncMapBackend(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	cache := NewCache(WithSyncMapBackend[string](), WithExpirationHeap[string](), WithClock[string](clock))
+	assert.True(t, cache.Set("key", "value", 0))
+	assert.ErrorIs(t, cache.Add("key", "other", 0), ErrKeyExists)
+	cache.Set("deleted", "value", 0)
+	require.NoError(t, cache.Del("deleted"))
+
+	// readers don't take the lock
+	cache.Lock()
+	v, err := cache.Get("key")
+	cache.Unlock()
+	assert.NoError(t, err)
+	assert.Equal(t, "value", v)
+	_, err = cache.Get("deleted")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	cache.Set("expired", "value", time.Millisecond)
+	clock.Advance(time.Millisecond + 1)
+	_, err = cache.Get("expired")
+	assert.ErrorIs(t, err, ErrExpired)
+	_, err = cache.Has("expired")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	cache.Set("expired", "value", time.Millisecond)
+	clock.Advance(time.Millisecond + 1)
+	cache.Cleanup()
+	_, err = cache.Get("expired")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	require.NoError(t, cache.Clear())
+	_, err = cache.Get("key")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	require.NoError(t, cache.Close())
+	_, err = cache.Get("key")
+	assert.ErrorIs(t, err, ErrClosed)
+
+	// the last backend option wins
+	assert.False(t, NewCache(WithCopyOnWrite[int](), WithSyncMapBackend[int]()).cow)
+	assert.False(t, NewCache(WithSyncMapBackend[int](), WithCopyOnWrite[int]()).syncMap)
+}
+
+func TestWithSyncMapBackendConcurrent(t *testing.T) {
+	cache := NewCache(WithSyncMapBackend[int]())
+	wg := sync.WaitGroup{}
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func(r int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				key := strconv.Itoa(r) + ":" + strconv.Itoa(i%10)
+				cache.Set(key, i, time.Microsecond)
+				_, _ = cache.Get(key)
+			}
+		}(r)
+	}
+	for i := 0; i < 100; i++ {
+		cache.Cleanup() // rebuilds the sync.Map
+	}
+	wg.Wait()
+}
@@ -0,0 +1,117 @@
+package mcache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// TTLBuckets are upper bounds of TTLHistogram buckets
+var TTLBuckets = [...]time.Duration{
+	time.Second, 10 * time.Second, time.Minute, 10 * time.Minute, time.Hour, 24 * time.Hour,
+}
+
+// TTLHistogram is a distribution of TTLs, tracked WithTTLHistogram
+type TTLHistogram struct {
+	NoExpiration uint64                  // entries without TTL
+	Buckets      [len(TTLBuckets)]uint64 // Buckets[i] counts TTLs above TTLBuckets[i-1] up to TTLBuckets[i]
+	Over         uint64                  // TTLs above the largest bound
+	Sum          time.Duration           // sum of TTLs, entries without TTL are not included
+}
+
+// Count returns the number of TTLs in the histogram, including entries without TTL
+func (h TTLHistogram) Count() uint64 {
+	n := h.NoExpiration + h.Over
+	for _, b := range h.Buckets {
+		n += b
+	}
+	return n
+}
+
+// observe adds the TTL to the histogram, 0 for no expiration
+func (h *TTLHistogram) observe(ttl time.Duration) {
+	if ttl <= 0 {
+		h.NoExpiration++
+		return
+	}
+	h.Sum += ttl
+	for i, bound := range TTLBuckets {
+		if ttl <= bound {
+			h.Buckets[i]++
+			return
+		}
+	}
+	h.Over++
+}
+
+// add returns the sum of both histograms
+func (h TTLHistogram) add(o TTLHistogram) TTLHistogram {
+	h.NoExpiration += o.NoExpiration
+	for i := range h.Buckets {
+		h.Buckets[i] += o.Buckets[i]
+	}
+	h.Over += o.Over
+	h.Sum += o.Sum
+	return h
+}
+
+// ttlCounters is TTLHistogram updated atomically
+type ttlCounters struct {
+	noExpiration atomic.Uint64
+	buckets      [len(TTLBuckets)]atomic.Uint64
+	over         atomic.Uint64
+	sum          atomic.Int64
+}
+
+// observe counts the TTL, 0 for no expiration
+func (t *ttlCounters) observe(ttl time.Duration) {
+	if ttl <= 0 {
+		t.noExpiration.Add(1)
+		return
+	}
+	t.sum.Add(int64(ttl))
+	for i, bound := range TTLBuckets {
+		if ttl <= bound {
+			t.buckets[i].Add(1)
+			return
+		}
+	}
+	t.over.Add(1)
+}
+
+// load returns the current counts
+func (t *ttlCounters) load() TTLHistogram {
+	h := TTLHistogram{NoExpiration: t.noExpiration.Load(), Over: t.over.Load(), Sum: time.Duration(t.sum.Load())}
+	for i := range t.buckets {
+		h.Buckets[i] = t.buckets[i].Load()
+	}
+	return h
+}
+
+// WithTTLHistogram is a functional option for tracking distributions of TTLs passed to Set
+// and its variants, and of remaining TTLs of live entries, reported in Stats. It helps to find
+// callers setting everything without TTL by accident. Remaining TTLs are counted by scanning
+// all entries on every Stats call.
+func WithTTLHistogram[T any]() func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.setTTLs = &ttlCounters{}
+	}
+}
+
+// remainingTTLs returns the distribution of remaining TTLs of live entries, must be called under lock
+func (c *Cache[T]) remainingTTLs() TTLHistogram {
+	var h TTLHistogram
+	now := c.now()
+	for _, data := range c.dataMaps() {
+		for _, v := range data {
+			if v.negative {
+				continue
+			}
+			if v.expiration == 0 {
+				h.observe(0)
+			} else if !v.expiredAt(now) {
+				h.observe(max(time.Duration(v.expiration-now), 1))
+			}
+		}
+	}
+	return h
+}
@@ -0,0 +1,41 @@
+package mcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache/clocktest"
+)
+
+func TestWithTTLHistogram(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	cache := NewCache(WithClock[int](clock), WithTTLHistogram[int]())
+	require.True(t, cache.Set("forever", 1, 0))
+	require.True(t, cache.Set("forever2", 1, 0))
+	require.True(t, cache.Set("second", 1, time.Second))
+	require.True(t, cache.Set("minute", 1, 50*time.Second))
+	require.True(t, cache.Set("week", 1, 7*24*time.Hour))
+	clock.Advance(30 * time.Second)
+
+	s := cache.Stats()
+	assert.Equal(t, TTLHistogram{
+		NoExpiration: 2,
+		Buckets:      [len(TTLBuckets)]uint64{1, 0, 1, 0, 0, 0},
+		Over:         1,
+		Sum:          time.Second + 50*time.Second + 7*24*time.Hour,
+	}, s.SetTTLs)
+	assert.Equal(t, uint64(5), s.SetTTLs.Count())
+	assert.Equal(t, TTLHistogram{
+		NoExpiration: 2,
+		Buckets:      [len(TTLBuckets)]uint64{0, 0, 1, 0, 0, 0},
+		Over:         1,
+		Sum:          20*time.Second + 7*24*time.Hour - 30*time.Second,
+	}, s.TTLs, "expired entries are not counted")
+
+	s = NewCache[int]().Stats()
+	assert.Zero(t, s.SetTTLs.Count())
+	assert.Zero(t, s.TTLs.Count())
+}
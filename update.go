@@ -0,0 +1,67 @@
+package mcache
+
+import "time"
+
+// Update replaces the value of a live key with fn applied to it, atomically, keeping the TTL
+// and entry metadata, for changes like bumping a field. fn is called under the write lock
+// and must not call the cache. Errors are the same as for Get: ErrKeyNotFound, ErrExpired,
+// ErrNegativeCached and ErrClosed.
+func (c *Cache[T]) Update(key string, fn func(T) T) error {
+	c.Lock()
+	defer c.Unlock()
+	item, err := c.lookupLocked(key)
+	if err != nil {
+		return err
+	}
+	if item.negative {
+		return ErrNegativeCached
+	}
+	item.value = fn(item.value)
+	c.setItem(key, item)
+	c.writeBack(key, pendingWrite[T]{value: item.value, ttl: c.remaining(item)})
+	return nil
+}
+
+// remaining returns the remaining TTL of a live item, 0 if it doesn't expire
+func (c *Cache[T]) remaining(item CacheItem[T]) time.Duration {
+	if item.expiration == 0 {
+		return 0
+	}
+	return max(time.Duration(item.expiration-c.now()), 1)
+}
+
+// Swap sets the value of the key, always overwriting, and returns the previous value, if it was live,
+// mirroring sync.Map.Swap. Negative entries are not returned as previous values.
+// Swap can't report errors: on a closed cache or for a key rejected WithKeyValidator nothing is set,
+// and WithFullPolicy(Reject) doesn't apply to it, as a swapped value can't be rejected.
+func (c *Cache[T]) Swap(key string, value T, ttl time.Duration) (previous T, loaded bool) {
+	c.Lock()
+	defer c.Unlock()
+	if c.closed || c.validateKey(key) != nil {
+		return previous, false
+	}
+	if item, err := c.lookupLocked(key); err == nil && !item.negative {
+		previous, loaded = item.value, true
+	}
+	c.setLocked(key, value, ttl)
+	c.writeBack(key, pendingWrite[T]{value: value, ttl: ttl})
+	return previous, loaded
+}
+
+// Replace sets the value of the key only if it's live, returns ErrKeyNotFound if the key is missing,
+// expired or cached as missing, so values are refreshed without creating new entries.
+// If cache is closed, return ErrClosed.
+func (c *Cache[T]) Replace(key string, value T, ttl time.Duration) error {
+	c.Lock()
+	defer c.Unlock()
+	item, err := c.lookupLocked(key)
+	if err == ErrClosed {
+		return err
+	}
+	if err != nil || item.negative {
+		return c.keyError(ErrKeyNotFound, key)
+	}
+	c.setLocked(key, value, ttl)
+	c.writeBack(key, pendingWrite[T]{value: value, ttl: ttl})
+	return nil
+}
@@ -0,0 +1,122 @@
+package mcache
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache/clocktest"
+)
+
+func TestUpdate(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	cache := NewCache(WithClock[int](clock), WithExpirationHeap[int]())
+	require.True(t, cache.Set("counter", 1, time.Minute))
+	clock.Advance(30 * time.Second)
+
+	require.NoError(t, cache.Update("counter", func(v int) int { return v + 1 }))
+	v, err := cache.Get("counter")
+	require.NoError(t, err)
+	assert.Equal(t, 2, v)
+	info, err := cache.EntryInfo("counter")
+	require.NoError(t, err)
+	assert.True(t, clock.Now().Add(30*time.Second).Equal(info.Expiration), "TTL is kept")
+
+	assert.ErrorIs(t, cache.Update("missing", func(v int) int { return v }), ErrKeyNotFound)
+	require.True(t, cache.SetNegative("negative", 0))
+	assert.ErrorIs(t, cache.Update("negative", func(v int) int { return v }), ErrNegativeCached)
+
+	clock.Advance(time.Minute)
+	assert.ErrorIs(t, cache.Update("counter", func(v int) int { return v + 1 }), ErrExpired)
+	assert.Equal(t, 1, cache.Stats().Entries, "expired entry is deleted")
+	cache.Cleanup() // expiration index is consistent
+
+	require.NoError(t, cache.Close())
+	assert.ErrorIs(t, cache.Update("counter", func(v int) int { return v }), ErrClosed)
+}
+
+func TestSwap(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	cache := NewCache(WithClock[string](clock))
+
+	prev, loaded := cache.Swap("key", "first", time.Minute)
+	assert.False(t, loaded)
+	assert.Empty(t, prev)
+	prev, loaded = cache.Swap("key", "second", time.Second)
+	assert.True(t, loaded)
+	assert.Equal(t, "first", prev)
+	v, err := cache.Get("key")
+	require.NoError(t, err)
+	assert.Equal(t, "second", v)
+
+	clock.Advance(2 * time.Second)
+	_, loaded = cache.Swap("key", "third", 0)
+	assert.False(t, loaded, "expired value isn't returned")
+	require.True(t, cache.SetNegative("negative", 0))
+	_, loaded = cache.Swap("negative", "value", 0)
+	assert.False(t, loaded, "negative entry isn't returned")
+
+	require.NoError(t, cache.Close())
+	_, loaded = cache.Swap("key", "fourth", 0)
+	assert.False(t, loaded)
+}
+
+func TestReplace(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	cache := NewCache(WithClock[string](clock))
+
+	assert.ErrorIs(t, cache.Replace("key", "value", 0), ErrKeyNotFound)
+	_, err := cache.Get("key")
+	assert.ErrorIs(t, err, ErrKeyNotFound, "new entries are not created")
+
+	require.True(t, cache.Set("key", "first", time.Second))
+	require.NoError(t, cache.Replace("key", "second", time.Minute))
+	v, err := cache.Get("key")
+	require.NoError(t, err)
+	assert.Equal(t, "second", v)
+
+	clock.Advance(2 * time.Minute)
+	assert.ErrorIs(t, cache.Replace("key", "third", 0), ErrKeyNotFound, "expired")
+	require.True(t, cache.SetNegative("negative", 0))
+	assert.ErrorIs(t, cache.Replace("negative", "value", 0), ErrKeyNotFound)
+
+	require.NoError(t, cache.Close())
+	assert.ErrorIs(t, cache.Replace("key", "value", 0), ErrClosed)
+}
+
+func TestAdd(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	cache := NewCache(WithClock[string](clock))
+
+	var wg sync.WaitGroup
+	var acquired atomic.Int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := cache.Add("lock", strconv.Itoa(i), time.Minute)
+			if err == nil {
+				acquired.Add(1)
+				return
+			}
+			assert.ErrorIs(t, err, ErrKeyExists)
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), acquired.Load(), "a single lease holder")
+
+	clock.Advance(2 * time.Minute)
+	require.NoError(t, cache.Add("lock", "next", time.Minute), "lease expired")
+	require.NoError(t, cache.Del("lock"))
+	require.NoError(t, cache.Add("lock", "released", time.Minute), "lease released")
+	require.True(t, cache.SetNegative("negative", 0))
+	require.NoError(t, cache.Add("negative", "value", 0))
+
+	require.NoError(t, cache.Close())
+	assert.ErrorIs(t, cache.Add("key", "value", 0), ErrClosed)
+}
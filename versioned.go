@@ -0,0 +1,46 @@
+package mcache
+
+import "errors"
+
+// ErrVersionMismatch is returned by SetIfVersion when the entry changed after its version was read
+var ErrVersionMismatch = errors.New("version mismatch")
+
+// GetVersioned is Get returning the version of the entry as well. Versions increase with every write
+// to the cache, so any change of the entry changes its version, see SetIfVersion.
+func (c *Cache[T]) GetVersioned(key string) (T, uint64, error) {
+	item, err := c.lookup(key)
+	v, err := c.get(item, err)
+	if err != nil && err != ErrNegativeCached {
+		return v, 0, err
+	}
+	return v, item.version, err
+}
+
+// SetIfVersion sets the value of the key only if the entry still has the version returned by GetVersioned,
+// keeping its TTL, and returns the new version. It returns ErrVersionMismatch if the entry changed meanwhile,
+// errors of Get if it's missing or expired, so read-modify-write is done without holding a lock:
+//
+//	for {
+//		v, version, err := cache.GetVersioned(key)
+//		...
+//		if _, err = cache.SetIfVersion(key, modify(v), version); !errors.Is(err, mcache.ErrVersionMismatch) {
+//			return err
+//		}
+//	}
+//
+// A negative entry is replaced with the value, as it has a version too.
+func (c *Cache[T]) SetIfVersion(key string, value T, version uint64) (uint64, error) {
+	c.Lock()
+	defer c.Unlock()
+	item, err := c.lookupLocked(key)
+	if err != nil {
+		return 0, err
+	}
+	if item.version != version {
+		return 0, ErrVersionMismatch
+	}
+	item.value, item.negative = value, false
+	c.setItem(key, item)
+	c.writeBack(key, pendingWrite[T]{value: value, ttl: c.remaining(item)})
+	return c.version, nil
+}
@@ -0,0 +1,105 @@
+package mcache
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/parMaster/mcache/clocktest"
+)
+
+func TestVersioned(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	cache := NewCache(WithClock[string](clock))
+	require.True(t, cache.Set("key", "first", time.Minute))
+	require.True(t, cache.Set("other", "value", 0))
+
+	v, version, err := cache.GetVersioned("key")
+	require.NoError(t, err)
+	assert.Equal(t, "first", v)
+	assert.NotZero(t, version)
+
+	clock.Advance(30 * time.Second)
+	next, err := cache.SetIfVersion("key", "second", version)
+	require.NoError(t, err)
+	assert.Greater(t, next, version)
+	v, version, err = cache.GetVersioned("key")
+	require.NoError(t, err)
+	assert.Equal(t, "second", v)
+	assert.Equal(t, next, version)
+	info, err := cache.EntryInfo("key")
+	require.NoError(t, err)
+	assert.True(t, clock.Now().Add(30*time.Second).Equal(info.Expiration), "TTL is kept")
+
+	require.True(t, cache.Set("key", "second", time.Minute))
+	_, err = cache.SetIfVersion("key", "third", version)
+	assert.ErrorIs(t, err, ErrVersionMismatch, "rewritten with the same value")
+	require.NoError(t, cache.Del("key"))
+	require.True(t, cache.Set("key", "second", time.Minute))
+	_, err = cache.SetIfVersion("key", "third", version)
+	assert.ErrorIs(t, err, ErrVersionMismatch, "deleted and set again")
+
+	_, _, err = cache.GetVersioned("missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	_, err = cache.SetIfVersion("missing", "value", 1)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	require.True(t, cache.SetNegative("negative", 0))
+	_, version, err = cache.GetVersioned("negative")
+	assert.ErrorIs(t, err, ErrNegativeCached)
+	_, err = cache.SetIfVersion("negative", "loaded", version)
+	require.NoError(t, err, "negative entry is replaced")
+	v, err = cache.Get("negative")
+	require.NoError(t, err)
+	assert.Equal(t, "loaded", v)
+
+	_, version, err = cache.GetVersioned("key")
+	require.NoError(t, err)
+	clock.Advance(2 * time.Minute)
+	_, err = cache.SetIfVersion("key", "third", version)
+	assert.ErrorIs(t, err, ErrExpired)
+
+	require.NoError(t, cache.Close())
+	_, _, err = cache.GetVersioned("other")
+	assert.ErrorIs(t, err, ErrClosed)
+	_, err = cache.SetIfVersion("other", "value", 1)
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestVersionedConcurrent(t *testing.T) {
+	for name, opt := range map[string]func(*Cache[int]){
+		"map":         func(*Cache[int]) {},
+		"copyOnWrite": WithCopyOnWrite[int](),
+		"syncMap":     WithSyncMapBackend[int](),
+	} {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache(opt)
+			require.True(t, cache.Set("counter", 0, 0))
+			var wg sync.WaitGroup
+			for i := 0; i < 50; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for {
+						v, version, err := cache.GetVersioned("counter")
+						if !assert.NoError(t, err) {
+							return
+						}
+						if _, err = cache.SetIfVersion("counter", v+1, version); !errors.Is(err, ErrVersionMismatch) {
+							assert.NoError(t, err)
+							return
+						}
+					}
+				}()
+			}
+			wg.Wait()
+			v, err := cache.Get("counter")
+			require.NoError(t, err)
+			assert.Equal(t, 50, v, "no increment is lost")
+		})
+	}
+}
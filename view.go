@@ -0,0 +1,29 @@
+package mcache
+
+// ReadView is a consistent read-only view of a cache, passed to the function of View
+type ReadView[T any] interface {
+	ReadOnlyCache[T]
+	GetVersioned(key string) (T, uint64, error)
+}
+
+// View calls fn with a read-only view of the cache at a single point in time, so reads of several keys
+// are consistent with each other, no write is seen halfway. The view is valid only until fn returns,
+// and View returns the error of fn. Like Snapshot reads, view reads don't count hits or touch entries,
+// and entries spilled WithOverflow are not visible.
+// WithCopyOnWrite the published map is read without locking, otherwise fn runs under the read lock,
+// so it must be short and must not write to the cache. WithGenerations the map is cloned.
+// Versions of entries read in fn can be passed to SetIfVersion, to write the result of the view.
+func (c *Cache[T]) View(fn func(v ReadView[T]) error) error {
+	c.RLock()
+	if c.closed {
+		c.RUnlock()
+		return ErrClosed
+	}
+	if c.cow || len(c.old) > 0 {
+		s := c.snapshotLocked()
+		c.RUnlock()
+		return fn(s)
+	}
+	defer c.RUnlock()
+	return fn(&snapshot[T]{data: c.data, at: c.now(), keyErrors: c.keyErrors})
+}
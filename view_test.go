@@ -0,0 +1,89 @@
+package mcache
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestView(t *testing.T) {
+	cache := NewCache[int]()
+	require.True(t, cache.Set("a", 1, 0))
+	require.True(t, cache.Set("b", 2, time.Hour))
+	require.True(t, cache.SetNegative("negative", 0))
+
+	errStop := errors.New("stop")
+	err := cache.View(func(v ReadView[int]) error {
+		a, err := v.Get("a")
+		require.NoError(t, err)
+		b, version, err := v.GetVersioned("b")
+		require.NoError(t, err)
+		assert.Equal(t, 3, a+b)
+		assert.NotZero(t, version)
+		_, err = v.Get("missing")
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+		_, _, err = v.GetVersioned("negative")
+		assert.ErrorIs(t, err, ErrNegativeCached)
+		assert.ElementsMatch(t, []string{"a", "b"}, v.Keys())
+		assert.Equal(t, 2, v.Len())
+		return errStop
+	})
+	assert.ErrorIs(t, err, errStop, "error of fn is returned")
+	assert.Equal(t, uint64(0), cache.Stats().Hits, "view reads don't count hits")
+
+	require.NoError(t, cache.Close())
+	assert.ErrorIs(t, cache.View(func(ReadView[int]) error { return nil }), ErrClosed)
+}
+
+func TestViewConsistent(t *testing.T) {
+	for name, opt := range map[string]func(*Cache[int]){
+		"map":         func(*Cache[int]) {},
+		"copyOnWrite": WithCopyOnWrite[int](),
+		"syncMap":     WithSyncMapBackend[int](),
+		"generations": WithGenerations[int](),
+	} {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache(opt)
+			require.True(t, cache.Set("a", 50, 0))
+			require.True(t, cache.Set("b", 50, 0))
+			cache.Cleanup() // entries move to the old generation WithGenerations
+			t.Cleanup(func() { _ = cache.Close() })
+
+			done := make(chan struct{})
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				// moves amounts between the keys, keeping the sum
+				for i := 0; ; i++ {
+					select {
+					case <-done:
+						return
+					default:
+					}
+					cache.Lock()
+					cache.setLocked("a", i%100, 0)
+					cache.setLocked("b", 100-i%100, 0)
+					cache.Unlock()
+				}
+			}()
+
+			for i := 0; i < 1000; i++ {
+				require.NoError(t, cache.View(func(v ReadView[int]) error {
+					a, err := v.Get("a")
+					require.NoError(t, err)
+					b, err := v.Get("b")
+					require.NoError(t, err)
+					assert.Equal(t, 100, a+b)
+					return nil
+				}))
+			}
+			close(done)
+			wg.Wait()
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package mcache
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// tombstone is an expiration of WAL records for deleted keys, replay recognizes deletions by it,
+// as WithNoExpiration expirations of replayed entries are dropped
+var tombstone = time.Unix(0, 1)
+
+// wal is an append-only log of Set and Del operations, written with the cache codec.
+// All methods are called under the cache write lock.
+type wal[T any] struct {
+	path  string
+	codec Codec[T]
+	file  *os.File
+	buf   *bufio.Writer
+	enc   Encoder[T]
+}
+
+// append writes a record, errors are returned to be logged, the cache keeps working without the log
+func (w *wal[T]) append(e *Entry[T]) error {
+	err := w.enc.Encode(e)
+	if err == nil {
+		err = w.buf.Flush()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to append %q to WAL: %w", e.Key, err)
+	}
+	return nil
+}
+
+// rewrite replaces the log with a fresh one containing only live entries of the cache
+func (w *wal[T]) rewrite(c *Cache[T]) error {
+	f, err := os.CreateTemp(filepath.Dir(w.path), filepath.Base(w.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create WAL file: %w", err)
+	}
+
+	bw := bufio.NewWriter(f)
+	enc := w.codec.NewEncoder(bw)
+	now := c.now()
+	// entries of the old generation WithGenerations are live until rotated out, so they are kept too
+write:
+	for _, data := range c.dataMaps() {
+		for k, v := range data {
+			if v.expiredAt(now) || v.negative {
+				continue
+			}
+			if err = enc.Encode(&Entry[T]{Key: k, Value: v.value, Expiration: c.expirationTime(v)}); err != nil {
+				break write
+			}
+		}
+	}
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), w.path)
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return fmt.Errorf("failed to compact WAL: %w", err)
+	}
+
+	if w.file != nil {
+		w.file.Close()
+	}
+	// encoder keeps writing to the same stream, so the log is decodable as a single stream
+	w.file, w.buf, w.enc = f, bw, enc
+	return nil
+}
+
+// walSet logs the item set for the key, if WAL is enabled
+func (c *Cache[T]) walSet(key string, item CacheItem[T]) {
+	if c.wal == nil {
+		return
+	}
+	if err := c.wal.append(&Entry[T]{Key: key, Value: item.value, Expiration: c.expirationTime(item)}); err != nil {
+		c.logger.Warn("mcache wal: write failed", "path", c.wal.path, "err", err)
+	}
+}
+
+// walDel logs deletion of the key, if WAL is enabled
+func (c *Cache[T]) walDel(key string) {
+	if c.wal == nil {
+		return
+	}
+	if err := c.wal.append(&Entry[T]{Key: key, Expiration: tombstone}); err != nil {
+		c.logger.Warn("mcache wal: write failed", "path", c.wal.path, "err", err)
+	}
+}
+
+// compactWAL rewrites the log with live entries only, holding the write lock while it runs
+func (c *Cache[T]) compactWAL() {
+	c.Lock()
+	defer c.Unlock()
+	if err := c.wal.rewrite(c); err != nil {
+		c.logger.Warn("mcache wal: compaction failed", "path", c.wal.path, "err", err)
+	}
+}
+
+// RecoverFromWAL replays the log written WithWAL: sets logged entries and deletes logged deletions.
+// Entries expired since they were logged are deleted as well.
+// Replayed entries are logged to the cache own WAL, if it's enabled.
+func (c *Cache[T]) RecoverFromWAL(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open WAL: %w", err)
+	}
+	defer f.Close()
+
+	dec := c.codec.NewDecoder(bufio.NewReader(f))
+	for {
+		var e Entry[T]
+		if err = dec.Decode(&e); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to decode WAL: %w", err)
+		}
+		if err = c.replay(e); err != nil {
+			return err
+		}
+	}
+}
+
+// replay applies the logged entry: sets it, or deletes the key if it's a deletion or expired.
+// Deletions are checked before the expiration is dropped WithNoExpiration.
+func (c *Cache[T]) replay(e Entry[T]) error {
+	c.Lock()
+	defer c.Unlock()
+	if c.closed {
+		return ErrClosed
+	}
+	item := c.newItem(e.Value, e.Expiration)
+	if e.Expiration.Equal(tombstone) || c.expired(item) {
+		c.dropOld(e.Key)
+		if old, ok := c.data[e.Key]; ok {
+			c.remove(e.Key, old)
+			c.logDel(e.Key)
+		}
+		return nil
+	}
+	c.store(e.Key, item)
+	c.logSet(e.Key, item)
+	return nil
+}
+
+// WithWAL is a functional option for logging every Set and Del to the append-only file at path.
+// On construction the log is replayed with RecoverFromWAL, if the file exists,
+// then it's compacted every compactInterval - rewritten with live entries only, compactInterval <= 0
+// disables compaction.
+// Log is written without fsync, so it survives process crashes, but not necessarily OS crashes.
+// WAL errors don't stop the cache, they are logged, see WithLogger.
+func WithWAL[T any](path string, compactInterval time.Duration) func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.walPath = path
+		c.addTask(compactInterval, c.compactWAL)
+	}
+}
+
+// openWAL replays the log and starts a new compacted one, called by NewCache WithWAL.
+// If the new log can't be created, cache works without it
+func (c *Cache[T]) openWAL() {
+	if err := c.RecoverFromWAL(c.walPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		c.logger.Warn("mcache wal: failed to recover", "path", c.walPath, "err", err)
+	}
+	w := &wal[T]{path: c.walPath, codec: c.codec}
+	if err := w.rewrite(c); err != nil {
+		c.logger.Warn("mcache wal: failed to start", "path", c.walPath, "err", err)
+		return
+	}
+	c.wal = w
+}
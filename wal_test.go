@@ -0,0 +1,129 @@
+package mcache
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithWAL(t *testing.T) {
+	for name, codec := range map[string]Codec[string]{"gob": GobCodec[string]{}, "json": JSONCodec[string]{}} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "cache.wal")
+
+			cache := NewCache(WithWAL[string](path, time.Hour), WithCodec(codec))
+			cache.Set("key", "value", 0)
+			cache.Set("deleted", "value", 0)
+			cache.Set("hour", "value", time.Hour)
+			cache.Set("expired", "value", time.Millisecond)
+			require.NoError(t, cache.Del("deleted"))
+			time.Sleep(10 * time.Millisecond)
+			cache.Set("expired", "new value", 0)
+
+			// replay into a new cache, as after a crash
+			recovered := NewCache(WithWAL[string](path, time.Hour), WithCodec(codec))
+			assert.Equal(t, 3, recovered.Stats().Entries)
+			v, err := recovered.Get("expired")
+			assert.NoError(t, err)
+			assert.Equal(t, "new value", v)
+			_, err = recovered.Get("deleted")
+			assert.ErrorIs(t, err, ErrKeyNotFound)
+
+			// recovered cache keeps logging, log was compacted on start and is a single stream
+			recovered.Set("more", "value", 0)
+			recovered.Del("key")
+			again := NewCache(WithCodec(codec))
+			require.NoError(t, again.RecoverFromWAL(path))
+			assert.Equal(t, 3, again.Stats().Entries)
+			_, err = again.Get("more")
+			assert.NoError(t, err)
+
+			// Clear truncates the log
+			require.NoError(t, recovered.Clear())
+			again = NewCache(WithCodec(codec))
+			require.NoError(t, again.RecoverFromWAL(path))
+			assert.Equal(t, 0, again.Stats().Entries)
+		})
+	}
+}
+
+func TestWALCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.wal")
+
+	cache := NewCache(WithWAL[int](path, 50*time.Millisecond))
+	for i := 0; i < 100; i++ {
+		cache.Set("key", i, time.Nanosecond)
+	}
+	cache.Set("key", 100, 0)
+	before, err := os.Stat(path)
+	require.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+	after, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Less(t, after.Size(), before.Size())
+
+	recovered := NewCache[int]()
+	require.NoError(t, recovered.RecoverFromWAL(path))
+	v, err := recovered.Get("key")
+	assert.NoError(t, err)
+	assert.Equal(t, 100, v)
+}
+
+func TestWALCompactionGenerations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.wal")
+
+	cache := NewCache(WithWAL[int](path, time.Hour), WithGenerations[int]())
+	cache.Set("old", 1, 0)
+	cache.Cleanup() // "old" is rotated into the old generation
+	cache.Set("new", 2, 0)
+	cache.compactWAL()
+	require.NoError(t, cache.Close())
+
+	reopened := NewCache(WithWAL[int](path, time.Hour), WithGenerations[int]())
+	defer reopened.Close()
+	for key, want := range map[string]int{"old": 1, "new": 2} {
+		v, err := reopened.Get(key)
+		require.NoError(t, err, key)
+		assert.Equal(t, want, v)
+	}
+}
+
+func TestWALNoExpiration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.wal")
+
+	cache := NewCache(WithWAL[int](path, time.Hour), WithNoExpiration[int]())
+	cache.Set("deleted", 1, 0)
+	cache.Set("kept", 2, 0)
+	require.NoError(t, cache.Del("deleted"))
+	require.NoError(t, cache.Close())
+
+	reopened := NewCache(WithWAL[int](path, time.Hour), WithNoExpiration[int]())
+	defer reopened.Close()
+	_, err := reopened.Get("deleted")
+	assert.ErrorIs(t, err, ErrKeyNotFound, "logged deletion is replayed")
+	v, err := reopened.Get("kept")
+	require.NoError(t, err)
+	assert.Equal(t, 2, v)
+}
+
+func TestWALErrors(t *testing.T) {
+	dir := t.TempDir()
+	assert.ErrorIs(t, NewCache[string]().RecoverFromWAL(filepath.Join(dir, "noSuchFile")), os.ErrNotExist)
+
+	garbage := filepath.Join(dir, "garbage")
+	require.NoError(t, os.WriteFile(garbage, []byte("garbage"), 0o600))
+	assert.Error(t, NewCache[string]().RecoverFromWAL(garbage))
+
+	// WAL in a missing directory is disabled, cache keeps working
+	sbuf := &syncBuffer{}
+	cache := NewCache(WithWAL[string](filepath.Join(dir, "noSuchDir", "cache.wal"), time.Hour),
+		WithLogger[string](slog.New(slog.NewTextHandler(sbuf, nil))))
+	assert.True(t, cache.Set("key", "value", 0))
+	assert.Contains(t, sbuf.String(), "failed to start")
+}
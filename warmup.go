@@ -0,0 +1,123 @@
+package mcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// warmup is the state of preloading WithWarmup
+type warmup[T any] struct {
+	keys        []string
+	loader      func(key string) (T, time.Duration, error)
+	concurrency int
+	loaded      atomic.Int64 // keys processed, loaded or failed
+	failed      atomic.Int64
+	done        chan struct{}
+	err         error // joined errors of failed keys, set before done is closed
+}
+
+// WarmupProgress is the progress of preloading WithWarmup
+type WarmupProgress struct {
+	Total  int // keys to load
+	Done   int // keys loaded or failed
+	Failed int // keys failed to load, WaitWarmup returns their errors
+}
+
+// WithWarmup is a functional option for preloading keys with the loader, returning the value and its TTL,
+// right after NewCache returns, so a cold cache doesn't send all the first requests to the backend.
+// Keys are loaded in the background by concurrency goroutines, WaitWarmup waits for them and
+// WarmupProgress reports how many are done. Keys set meanwhile are not overwritten by preloaded values.
+// Load errors are logged, see WithLogger. Close stops preloading, waiting for loads in flight.
+func WithWarmup[T any](keys []string, loader func(key string) (T, time.Duration, error), concurrency int) func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.warmup = &warmup[T]{keys: keys, loader: loader, concurrency: max(concurrency, 1), done: make(chan struct{})}
+	}
+}
+
+// startWarmup starts goroutines preloading keys WithWarmup, called by NewCache
+func (c *Cache[T]) startWarmup() {
+	w := c.warmup
+	keys := make(chan string)
+	var errs []error
+	var mu sync.Mutex
+	var workers sync.WaitGroup
+	for i := 0; i < min(w.concurrency, max(len(w.keys), 1)); i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for key := range keys {
+				if err := c.warm(key); err != nil {
+					c.logger.Warn("mcache warmup: failed to load", "key", key, "err", err)
+					mu.Lock()
+					errs = append(errs, fmt.Errorf("%q: %w", key, err))
+					mu.Unlock()
+					w.failed.Add(1)
+				}
+				w.loaded.Add(1)
+			}
+		}()
+	}
+
+	c.bg.wg.Add(1)
+	go func() {
+		defer c.bg.wg.Done()
+	feed:
+		for _, key := range w.keys {
+			select {
+			case keys <- key:
+			case <-c.bg.done:
+				break feed
+			}
+		}
+		close(keys)
+		workers.Wait()
+		w.err = errors.Join(errs...)
+		close(w.done)
+	}()
+}
+
+// warm loads the key WithWarmup, unless it's set already
+func (c *Cache[T]) warm(key string) error {
+	if c.Exists(key) {
+		return nil
+	}
+	v, ttl, err := c.warmup.loader(key)
+	if err != nil {
+		return err
+	}
+	if err = c.Add(key, v, ttl); err != nil && !errors.Is(err, ErrKeyExists) && !errors.Is(err, ErrClosed) {
+		return err
+	}
+	return nil
+}
+
+// WaitWarmup waits until keys are preloaded WithWarmup, and returns errors of keys failed to load
+// and the error of restoring the snapshot WithRestore, joined. It returns immediately without WithWarmup,
+// and when the cache is closed, with keys loaded so far.
+func (c *Cache[T]) WaitWarmup(ctx context.Context) error {
+	if c.warmup == nil {
+		return c.restoreErr
+	}
+	select {
+	case <-c.warmup.done:
+		return errors.Join(c.restoreErr, c.warmup.err)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WarmupProgress reports the progress of preloading WithWarmup, zero without it
+func (c *Cache[T]) WarmupProgress() WarmupProgress {
+	if c.warmup == nil {
+		return WarmupProgress{}
+	}
+	return WarmupProgress{
+		Total:  len(c.warmup.keys),
+		Done:   int(c.warmup.loaded.Load()),
+		Failed: int(c.warmup.failed.Load()),
+	}
+}
@@ -0,0 +1,81 @@
+package mcache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithWarmup(t *testing.T) {
+	keys := make([]string, 100)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+	errLoad := errors.New("load failed")
+	var running, peak atomic.Int32
+	loader := func(key string) (int, time.Duration, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		time.Sleep(time.Millisecond)
+		if key == "13" {
+			return 0, 0, errLoad
+		}
+		v, _ := strconv.Atoi(key)
+		return v, time.Hour, nil
+	}
+
+	cache := NewCache(WithWarmup(keys, loader, 4))
+	cache.Set("42", -1, 0)
+	err := cache.WaitWarmup(context.Background())
+	require.ErrorIs(t, err, errLoad)
+	assert.Contains(t, err.Error(), `"13"`)
+	assert.Equal(t, WarmupProgress{Total: 100, Done: 100, Failed: 1}, cache.WarmupProgress())
+	assert.LessOrEqual(t, peak.Load(), int32(4), "concurrency is limited")
+
+	assert.Equal(t, 99, cache.Stats().Entries)
+	v, err := cache.Get("7")
+	require.NoError(t, err)
+	assert.Equal(t, 7, v)
+	info, err := cache.EntryInfo("7")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), info.Expiration, time.Minute)
+	v, err = cache.Get("42")
+	require.NoError(t, err)
+	assert.Equal(t, -1, v, "key set meanwhile is not overwritten")
+}
+
+func TestWithWarmupClose(t *testing.T) {
+	release := make(chan struct{})
+	var calls atomic.Int32
+	cache := NewCache(WithWarmup([]string{"a", "b", "c", "d"}, func(key string) (string, time.Duration, error) {
+		calls.Add(1)
+		<-release
+		return key, 0, nil
+	}, 1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, cache.WaitWarmup(ctx), context.DeadlineExceeded)
+
+	closed := make(chan error)
+	go func() { closed <- cache.Close() }()
+	require.Eventually(t, func() bool {
+		_, err := cache.Get("a")
+		return errors.Is(err, ErrClosed)
+	}, time.Second, time.Millisecond)
+	close(release)
+	require.NoError(t, <-closed)
+	require.NoError(t, cache.WaitWarmup(context.Background()))
+	assert.Equal(t, int32(1), calls.Load(), "preloading stops on Close")
+
+	assert.NoError(t, NewCache[int]().WaitWarmup(context.Background()), "no-op without WithWarmup")
+	assert.Equal(t, WarmupProgress{}, NewCache[int]().WarmupProgress())
+}
@@ -0,0 +1,150 @@
+package mcache
+
+import "time"
+
+// timing wheel dimensions: wheelLevels levels of wheelSlots slots each,
+// every slot of a level covers wheelSlots slots of the level below
+const (
+	wheelBits   = 6
+	wheelSlots  = 1 << wheelBits
+	wheelMask   = wheelSlots - 1
+	wheelLevels = 4
+)
+
+// minWheelTick is the tick used for non-positive ticks of WithTimingWheel
+const minWheelTick = time.Millisecond
+
+// timingWheel is a hierarchical timing wheel, implements expiryIndex.
+// Insertion and removal are O(1), nodes are kept in doubly-linked slot lists.
+// Level 0 slots hold nodes expiring within wheelSlots ticks, one tick per slot,
+// higher level slots hold nodes expiring later, and are cascaded to the lower levels
+// when the wheel reaches them. Nodes beyond the wheel range wait in the last level
+// and are re-inserted on cascade.
+type timingWheel struct {
+	tick  time.Duration
+	base  int64 // next tick to process
+	count int
+	slots [wheelLevels][wheelSlots]*expiryNode
+}
+
+func newTimingWheel(tick time.Duration, now int64) *timingWheel {
+	if tick <= 0 {
+		tick = minWheelTick
+	}
+	return &timingWheel{tick: tick, base: now / int64(tick)}
+}
+
+// expTick is the first tick at which the node is expired
+func (w *timingWheel) expTick(node *expiryNode) int64 {
+	return node.expiration/int64(w.tick) + 1
+}
+
+func (w *timingWheel) add(node *expiryNode) {
+	w.count++
+	w.place(node)
+}
+
+// place links the node into the slot corresponding to its expiration tick
+func (w *timingWheel) place(node *expiryNode) {
+	exp := max(w.expTick(node), w.base)
+	delta := exp - w.base
+
+	level := 0
+	for level < wheelLevels-1 && delta >= 1<<(wheelBits*(level+1)) {
+		level++
+	}
+	if delta >= 1<<(wheelBits*wheelLevels) {
+		exp = w.base + 1<<(wheelBits*wheelLevels) - 1
+	}
+	head := &w.slots[level][(exp>>(wheelBits*level))&wheelMask]
+
+	node.slot, node.prev, node.next = head, nil, *head
+	if *head != nil {
+		(*head).prev = node
+	}
+	*head = node
+}
+
+// unlink removes the node from its slot list
+func (w *timingWheel) unlink(node *expiryNode) {
+	if node.prev != nil {
+		node.prev.next = node.next
+	} else {
+		*node.slot = node.next
+	}
+	if node.next != nil {
+		node.next.prev = node.prev
+	}
+	node.slot, node.prev, node.next = nil, nil, nil
+}
+
+func (w *timingWheel) remove(node *expiryNode) {
+	w.unlink(node)
+	w.count--
+}
+
+func (w *timingWheel) len() int { return w.count }
+
+func (w *timingWheel) clear() {
+	w.slots = [wheelLevels][wheelSlots]*expiryNode{}
+	w.count = 0
+}
+
+// cascade re-places all nodes of the slot, moving them to lower levels
+func (w *timingWheel) cascade(level int, idx int64) {
+	head := &w.slots[level][idx]
+	node := *head
+	*head = nil
+	for node != nil {
+		next := node.next
+		w.place(node)
+		node = next
+	}
+}
+
+// popExpired turns the wheel up to now, calling fn for nodes of every passed level 0 slot
+func (w *timingWheel) popExpired(now int64, fn func(node *expiryNode)) {
+	until := now / int64(w.tick)
+	if w.count == 0 {
+		w.base = max(w.base, until+1)
+		return
+	}
+	for w.base <= until {
+		idx := w.base & wheelMask
+		for level := 1; idx == 0 && level < wheelLevels; level++ {
+			idx = (w.base >> (wheelBits * level)) & wheelMask
+			w.cascade(level, idx)
+		}
+
+		head := &w.slots[0][w.base&wheelMask]
+		for *head != nil {
+			node := *head
+			w.remove(node)
+			fn(node)
+		}
+		w.base++
+	}
+}
+
+// WithTimingWheel is a functional option for tracking expirations with a hierarchical timing wheel
+// instead of periodic full scans. Insertion is O(1), and a background goroutine turns the wheel
+// every tick, deleting entries within a tick after they expire. Cleanup pops expired entries
+// from the wheel as well. The goroutine is stopped by Close.
+// Suits caches holding millions of short-lived entries, where heap updates are too costly.
+// Tick <= 0 is replaced with 1ms.
+func WithTimingWheel[T any](tick time.Duration) func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		wheel := newTimingWheel(tick, 0) // the cache epoch
+		c.expiry = wheel
+		c.addTask(wheel.tick, func() {
+			c.Lock()
+			defer c.Unlock()
+			if c.closed {
+				return
+			}
+			if removed := c.popExpired(); removed > 0 {
+				c.evicted(removed)
+			}
+		})
+	}
+}
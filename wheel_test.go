@@ -0,0 +1,103 @@
+package mcache
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimingWheel(t *testing.T) {
+	start := int64(1_700_000_000 * time.Second)
+	w := newTimingWheel(time.Second, start)
+	rnd := rand.New(rand.NewSource(1))
+
+	nodes := map[string]*expiryNode{}
+	add := func(d time.Duration) {
+		node := &expiryNode{key: strconv.Itoa(len(nodes)), expiration: start + int64(d)}
+		nodes[node.key] = node
+		w.add(node)
+	}
+	// all levels
+	for i := 0; i < 10_000; i++ {
+		add(time.Duration(rnd.Int63n(int64(time.Second) << (wheelBits * (rnd.Intn(wheelLevels) + 1)))))
+	}
+	// beyond the wheel range
+	add(time.Second<<(wheelBits*wheelLevels) + time.Hour)
+	add(-time.Hour) // already expired
+	assert.Equal(t, len(nodes), w.len())
+
+	// removed nodes are never popped
+	for i := 0; i < 100; i++ {
+		node := nodes[strconv.Itoa(i)]
+		w.remove(node)
+		delete(nodes, node.key)
+	}
+	assert.Equal(t, len(nodes), w.len())
+
+	popped := 0
+	step := func() time.Duration {
+		return time.Duration(rnd.Int63n(int64(time.Second) << (wheelBits * rnd.Intn(wheelLevels))))
+	}
+	for now := start; w.len() > 0; now += int64(step()) {
+		w.popExpired(now, func(node *expiryNode) {
+			_, ok := nodes[node.key]
+			require.True(t, ok, "popped twice or after removal")
+			require.Less(t, node.expiration, now, "popped before expiration")
+			delete(nodes, node.key)
+			popped++
+		})
+		// nothing expired is left behind
+		for _, node := range nodes {
+			require.GreaterOrEqual(t, node.expiration, now-int64(time.Second), "expired node is not popped")
+		}
+	}
+	assert.Empty(t, nodes)
+	assert.Equal(t, 9_902, popped)
+
+	add(time.Hour)
+	w.clear()
+	assert.Equal(t, 0, w.len())
+	w.popExpired(start+int64(2*time.Hour), func(*expiryNode) { t.Fatal("cleared wheel popped a node") })
+}
+
+func TestWithTimingWheel(t *testing.T) {
+	cache := NewCache(WithTimingWheel[int](5 * time.Millisecond))
+	defer cache.Close()
+
+	for i := 0; i < 100; i++ {
+		cache.Set("short_"+strconv.Itoa(i), i, 10*time.Millisecond)
+		cache.Set("long_"+strconv.Itoa(i), i, time.Hour)
+		cache.Set("forever_"+strconv.Itoa(i), i, 0)
+	}
+	require.NoError(t, cache.Del("long_0"))
+	assert.Equal(t, 299, cache.Stats().Entries)
+
+	// short-lived entries are deleted by the wheel goroutine without Cleanup
+	assert.Eventually(t, func() bool { return cache.Stats().Entries == 199 }, time.Second, 5*time.Millisecond)
+	s := cache.Stats()
+	assert.Equal(t, uint64(100), s.Evictions)
+	assert.Equal(t, uint64(0), s.Cleanups)
+
+	cache.Set("short", 0, time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	_, err := cache.Get("short")
+	assert.ErrorIs(t, err, ErrExpired)
+
+	require.NoError(t, cache.Clear())
+	assert.Equal(t, 0, cache.expiry.len())
+}
+
+func TestWithTimingWheelNonPositiveTick(t *testing.T) {
+	for _, tick := range []time.Duration{0, -time.Second} {
+		cache := NewCache(WithTimingWheel[int](tick))
+		assert.Equal(t, minWheelTick, cache.expiry.(*timingWheel).tick)
+		cache.Set("key", 1, 5*time.Millisecond)
+		assert.Eventually(t, func() bool { return cache.Stats().Entries == 0 }, time.Second, time.Millisecond,
+			"the wheel turns with the minimal tick")
+		require.NoError(t, cache.Close())
+	}
+}
@@ -0,0 +1,134 @@
+package mcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// pendingWrite is a change waiting to be written to the store WithWriteBehind
+type pendingWrite[T any] struct {
+	value T
+	ttl   time.Duration
+	del   bool
+}
+
+// writeBehind is a queue of changes written to the store asynchronously.
+// Changes of the same key are coalesced, only the last one is written.
+type writeBehind[T any] struct {
+	store     Store[T]
+	interval  time.Duration
+	batchSize int
+	onError   func(key string, err error)
+	kick      chan struct{} // wakes the flusher when batchSize changes are pending
+	mu        sync.Mutex
+	pending   map[string]pendingWrite[T]
+	flushing  sync.Mutex // serializes flushes, so changes of a key are written in order
+}
+
+// WithWriteBehind is a functional option for absorbing write bursts in front of a slow store:
+// Set, SetBytes and Del are acknowledged immediately, and changes are written to the store
+// asynchronously, every flushInterval or as soon as batchSize keys are pending.
+// FlushInterval <= 0 disables periodic flushes, changes are written on batchSize only.
+// Changes of the same key made between flushes are coalesced, only the last one is written.
+// Flush writes pending changes right away, Close flushes them before returning.
+// Failed writes are not retried, they are logged and passed to the handler set WithWriteBehindErrors.
+func WithWriteBehind[T any](store Store[T], flushInterval time.Duration, batchSize int) func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		wb := c.writeBehindQueue()
+		wb.store, wb.interval, wb.batchSize = store, flushInterval, max(batchSize, 1)
+	}
+}
+
+// WithWriteBehindErrors is a functional option for handling writes failed WithWriteBehind,
+// like re-queueing them with Set
+func WithWriteBehindErrors[T any](fn func(key string, err error)) func(*Cache[T]) {
+	return func(c *Cache[T]) {
+		c.writeBehindQueue().onError = fn
+	}
+}
+
+// writeBehindQueue returns the queue set by options, creating it on first call
+func (c *Cache[T]) writeBehindQueue() *writeBehind[T] {
+	if c.wb == nil {
+		c.wb = &writeBehind[T]{kick: make(chan struct{}, 1), pending: make(map[string]pendingWrite[T])}
+	}
+	return c.wb
+}
+
+// startWriteBehind starts the flusher goroutine, called by NewCache WithWriteBehind
+func (c *Cache[T]) startWriteBehind() {
+	c.bg.wg.Add(1)
+	go func() {
+		defer c.bg.wg.Done()
+		var tick <-chan time.Time // nil without periodic flushes, never ready
+		if c.wb.interval > 0 {
+			ticker := time.NewTicker(c.wb.interval)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+		for {
+			select {
+			case <-c.bg.done:
+				return
+			case <-tick:
+			case <-c.wb.kick:
+			}
+			c.Flush() //nolint:errcheck // errors are logged and passed to the handler
+		}
+	}()
+}
+
+// writeBack queues the change of the key, if WithWriteBehind is set
+func (c *Cache[T]) writeBack(key string, w pendingWrite[T]) {
+	if c.wb == nil {
+		return
+	}
+	c.wb.mu.Lock()
+	c.wb.pending[key] = w
+	full := len(c.wb.pending) >= c.wb.batchSize
+	c.wb.mu.Unlock()
+	if full {
+		select {
+		case c.wb.kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Flush writes changes pending WithWriteBehind to the store, returns errors of failed writes joined.
+// It's a no-op without WithWriteBehind.
+func (c *Cache[T]) Flush() error {
+	if c.wb == nil {
+		return nil
+	}
+	c.wb.flushing.Lock()
+	defer c.wb.flushing.Unlock()
+
+	c.wb.mu.Lock()
+	pending := c.wb.pending
+	c.wb.pending = make(map[string]pendingWrite[T], len(pending))
+	c.wb.mu.Unlock()
+
+	var errs []error
+	for key, w := range pending {
+		var err error
+		if w.del {
+			err = c.wb.store.Delete(context.Background(), key)
+		} else {
+			err = c.wb.store.Save(context.Background(), key, w.value, w.ttl)
+		}
+		if err == nil {
+			continue
+		}
+		err = fmt.Errorf("failed to write %q behind: %w", key, err)
+		c.logger.Warn("mcache write-behind: write failed", "key", key, "err", err)
+		if c.wb.onError != nil {
+			c.wb.onError(key, err)
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
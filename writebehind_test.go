@@ -0,0 +1,118 @@
+package mcache
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithWriteBehind(t *testing.T) {
+	store := newMapStore[string]()
+	store.data["deleted"] = "value"
+	cache := NewCache(WithWriteBehind[string](store, time.Hour, 100))
+
+	cache.Set("key", "first", 0)
+	require.NoError(t, cache.Del("key"))
+	cache.Set("key", "second", time.Minute)
+	cache.SetBytes([]byte("bytes"), "value", 0)
+	cache.Set("deleted", "value", 0)
+	require.NoError(t, cache.Del("deleted"))
+
+	// nothing is written before the flush
+	_, ok := store.get("key")
+	assert.False(t, ok)
+
+	require.NoError(t, cache.Flush())
+	v, ok := store.get("key")
+	assert.True(t, ok)
+	assert.Equal(t, "second", v)
+	_, ok = store.get("bytes")
+	assert.True(t, ok)
+	_, ok = store.get("deleted")
+	assert.False(t, ok)
+
+	cache.Set("closing", "value", 0)
+	require.NoError(t, cache.Close())
+	_, ok = store.get("closing")
+	assert.True(t, ok, "Close flushes pending writes")
+}
+
+func TestWithWriteBehindBatch(t *testing.T) {
+	store := newMapStore[int]()
+	cache := NewCache(WithWriteBehind[int](store, time.Hour, 10), WithReadThrough[int](store))
+	defer cache.Close()
+
+	for i := 0; i < 10; i++ {
+		cache.Set(strconv.Itoa(i), i, 0)
+	}
+	assert.Eventually(t, func() bool {
+		_, ok := store.get("9")
+		return ok
+	}, time.Second, time.Millisecond, "full batch is flushed right away")
+
+	// loaded key is cached, but not written back
+	store.mu.Lock()
+	store.data["loaded"] = 42
+	store.mu.Unlock()
+	_, err := cache.Get("loaded")
+	require.NoError(t, err)
+	cache.wb.mu.Lock()
+	assert.Empty(t, cache.wb.pending)
+	cache.wb.mu.Unlock()
+}
+
+func TestWithWriteBehindNoInterval(t *testing.T) {
+	store := newMapStore[int]()
+	cache := NewCache(WithWriteBehind[int](store, 0, 2))
+
+	cache.Set("a", 1, 0)
+	time.Sleep(10 * time.Millisecond)
+	_, ok := store.get("a")
+	assert.False(t, ok, "no periodic flushes")
+
+	cache.Set("b", 2, 0)
+	assert.Eventually(t, func() bool {
+		_, ok := store.get("b")
+		return ok
+	}, time.Second, time.Millisecond, "full batch is flushed")
+
+	cache.Set("c", 3, 0)
+	require.NoError(t, cache.Close())
+	_, ok = store.get("c")
+	assert.True(t, ok, "Close flushes pending writes")
+}
+
+func TestWithWriteBehindErrors(t *testing.T) {
+	store := newMapStore[int]()
+	store.err = errors.New("store is down")
+	var mu sync.Mutex
+	var failed []string
+	cache := NewCache(
+		WithWriteBehindErrors[int](func(key string, err error) {
+			assert.ErrorIs(t, err, store.err)
+			mu.Lock()
+			failed = append(failed, key)
+			mu.Unlock()
+		}),
+		WithWriteBehind[int](store, 10*time.Millisecond, 100),
+	)
+	cache.Set("key", 1, 0)
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(failed) == 1
+	}, time.Second, time.Millisecond, "periodic flush")
+
+	require.NoError(t, cache.Del("key"))
+	assert.ErrorIs(t, cache.Close(), store.err)
+
+	// handler alone doesn't enable write-behind
+	cache = NewCache(WithWriteBehindErrors[int](func(string, error) {}))
+	cache.Set("key", 1, 0)
+	assert.NoError(t, cache.Flush())
+}